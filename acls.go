@@ -0,0 +1,243 @@
+package carrot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+var ErrOwnerNotEditable = errors.New("owner can only be changed by superusers")
+
+// ObjectPermission grants a user or a group a permission on a single row.
+//
+//   - ObjectType: table name of the model, such as "products"
+//   - ObjectID: primary key value of the row, composite keys are joined with ","
+//   - Permission: PermissionRead, PermissionUpdate, PermissionDelete or PermissionAll
+type ObjectPermission struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	CreatedAt  time.Time `json:"createdAt" gorm:"autoCreateTime"`
+	ObjectType string    `json:"objectType" gorm:"size:128;uniqueIndex:idx_object_permission_grant"`
+	ObjectID   string    `json:"objectId" gorm:"size:200;uniqueIndex:idx_object_permission_grant"`
+	UserID     uint      `json:"userId,omitempty" gorm:"uniqueIndex:idx_object_permission_grant"`
+	GroupID    uint      `json:"groupId,omitempty" gorm:"uniqueIndex:idx_object_permission_grant"`
+	Permission string    `json:"permission" gorm:"size:64;uniqueIndex:idx_object_permission_grant"`
+}
+
+// GetObjectIdentity return the table name and the primary key value of obj,
+// such as ("products", "42").
+func GetObjectIdentity(db *gorm.DB, obj any) (string, string, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(obj); err != nil {
+		return "", "", err
+	}
+	if len(stmt.Schema.PrimaryFields) <= 0 {
+		return "", "", fmt.Errorf("%s not has primaryKey", stmt.Schema.Name)
+	}
+
+	rv := reflect.Indirect(reflect.ValueOf(obj))
+	var keys []string
+	for _, f := range stmt.Schema.PrimaryFields {
+		v, _ := f.ValueOf(context.Background(), rv)
+		keys = append(keys, fmt.Sprintf("%v", v))
+	}
+	return stmt.Schema.Table, strings.Join(keys, ","), nil
+}
+
+// GrantUserPermission grants user the permissions on obj.
+func GrantUserPermission(db *gorm.DB, obj any, user *User, permissions ...string) error {
+	return grantObjectPermission(db, obj, user.ID, 0, permissions)
+}
+
+// GrantGroupPermission grants all members of group the permissions on obj.
+func GrantGroupPermission(db *gorm.DB, obj any, group *Group, permissions ...string) error {
+	return grantObjectPermission(db, obj, 0, group.ID, permissions)
+}
+
+// RevokeUserPermission revokes the permissions of user on obj,
+// all permissions are revoked if permissions is empty.
+func RevokeUserPermission(db *gorm.DB, obj any, user *User, permissions ...string) error {
+	return revokeObjectPermission(db, obj, user.ID, 0, permissions)
+}
+
+// RevokeGroupPermission revokes the permissions of group on obj,
+// all permissions are revoked if permissions is empty.
+func RevokeGroupPermission(db *gorm.DB, obj any, group *Group, permissions ...string) error {
+	return revokeObjectPermission(db, obj, 0, group.ID, permissions)
+}
+
+func grantObjectPermission(db *gorm.DB, obj any, userID, groupID uint, permissions []string) error {
+	objectType, objectID, err := GetObjectIdentity(db, obj)
+	if err != nil {
+		return err
+	}
+	db = db.Session(&gorm.Session{NewDB: true})
+	for _, perm := range permissions {
+		val := ObjectPermission{
+			ObjectType: objectType,
+			ObjectID:   objectID,
+			UserID:     userID,
+			GroupID:    groupID,
+			Permission: perm,
+		}
+		result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&val)
+		if result.Error != nil {
+			return result.Error
+		}
+	}
+	return nil
+}
+
+func revokeObjectPermission(db *gorm.DB, obj any, userID, groupID uint, permissions []string) error {
+	objectType, objectID, err := GetObjectIdentity(db, obj)
+	if err != nil {
+		return err
+	}
+	tx := db.Session(&gorm.Session{NewDB: true}).Where("object_type", objectType).Where("object_id", objectID).Where("user_id", userID).Where("group_id", groupID)
+	if len(permissions) > 0 {
+		tx = tx.Where("permission IN ?", permissions)
+	}
+	return tx.Delete(&ObjectPermission{}).Error
+}
+
+// grantedPermissions return the permission names which satisfy permission.
+// Any grant implies read access.
+func grantedPermissions(permission string) []string {
+	if permission == PermissionRead {
+		return []string{PermissionRead, PermissionUpdate, PermissionDelete, PermissionAll}
+	}
+	return []string{permission, PermissionAll}
+}
+
+// userGrantsQuery return the grants of user, include grants of user's groups.
+func userGrantsQuery(db *gorm.DB, user *User, objectType, permission string) *gorm.DB {
+	db = db.Session(&gorm.Session{NewDB: true})
+	groupIDs := db.Model(&GroupMember{}).Select("group_id").Where("user_id", user.ID)
+	principal := db.Where("user_id", user.ID).Or("group_id <> 0 AND group_id IN (?)", groupIDs)
+	return db.Model(&ObjectPermission{}).
+		Where("object_type", objectType).
+		Where("permission IN ?", grantedPermissions(permission)).
+		Where(principal)
+}
+
+// HasObjectPermission check user has been granted permission on obj,
+// the owner of obj is not considered.
+func HasObjectPermission(db *gorm.DB, obj any, user *User, permission string) bool {
+	if user == nil {
+		return false
+	}
+	objectType, objectID, err := GetObjectIdentity(db, obj)
+	if err != nil {
+		return false
+	}
+	var c int64
+	result := userGrantsQuery(db, user, objectType, permission).Where("object_id", objectID).Limit(1).Count(&c)
+	return result.Error == nil && c > 0
+}
+
+// deleteObjectPermissions revokes all grants on obj, the grants must not apply to a later row reusing the id.
+func deleteObjectPermissions(db *gorm.DB, obj any) error {
+	objectType, objectID, err := GetObjectIdentity(db, obj)
+	if err != nil {
+		return err
+	}
+	return db.Session(&gorm.Session{NewDB: true}).Where("object_type", objectType).Where("object_id", objectID).Delete(&ObjectPermission{}).Error
+}
+
+// GetPermittedObjectIDs return the ids of objectType which user has been granted permission.
+func GetPermittedObjectIDs(db *gorm.DB, objectType string, user *User, permission string) ([]string, error) {
+	var ids []string
+	result := userGrantsQuery(db, user, objectType, permission).Distinct("object_id").Pluck("object_id", &ids)
+	return ids, result.Error
+}
+
+// isObjectOwner check the ownerField of val is user's id.
+func isObjectOwner(val any, ownerField string, user *User) bool {
+	fv := reflect.Indirect(reflect.ValueOf(val)).FieldByName(ownerField)
+	if !fv.IsValid() {
+		return false
+	}
+	if fv.Kind() == reflect.Ptr {
+		if fv.IsNil() {
+			return false
+		}
+		fv = fv.Elem()
+	}
+	return formatAsInt64(fv.Interface()) == int64(user.ID)
+}
+
+// setObjectOwner fill the ownerField of val with user's id.
+func setObjectOwner(val any, ownerField string, user *User) {
+	fv := reflect.Indirect(reflect.ValueOf(val)).FieldByName(ownerField)
+	if !fv.IsValid() || !fv.CanSet() {
+		return
+	}
+	if fv.Kind() == reflect.Ptr {
+		v := reflect.New(fv.Type().Elem())
+		v.Elem().Set(reflect.ValueOf(user.ID).Convert(fv.Type().Elem()))
+		fv.Set(v)
+		return
+	}
+	fv.Set(reflect.ValueOf(user.ID).Convert(fv.Type()))
+}
+
+// checkObjectAccess return ErrForbidden unless user owns val or has been granted permission on val.
+func checkObjectAccess(db *gorm.DB, val any, ownerField string, user *User, permission string) error {
+	if user == nil {
+		return ErrUnauthorized
+	}
+	if isObjectOwner(val, ownerField, user) {
+		return nil
+	}
+	if HasObjectPermission(db, val, user, permission) {
+		return nil
+	}
+	return ErrForbidden
+}
+
+func abortWithAccessError(c *gin.Context, err error) {
+	if errors.Is(err, ErrUnauthorized) {
+		AbortWithJSONError(c, http.StatusUnauthorized, err)
+	} else if errors.Is(err, ErrForbidden) {
+		AbortWithJSONError(c, http.StatusForbidden, err)
+	} else {
+		AbortWithJSONError(c, http.StatusInternalServerError, err)
+	}
+}
+
+// scopeObjectAccess limits the query to the rows owned by user or shared with user.
+// Shared rows are only resolved for models with a single primary key.
+func scopeObjectAccess(db *gorm.DB, model any, ownerField string, user *User) (*gorm.DB, error) {
+	if user == nil {
+		return nil, ErrUnauthorized
+	}
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return nil, err
+	}
+	ownerCol := db.NamingStrategy.ColumnName(stmt.Schema.Table, ownerField)
+	if f := stmt.Schema.LookUpField(ownerField); f != nil {
+		ownerCol = f.DBName
+	}
+	cond := db.Session(&gorm.Session{NewDB: true}).Where(clause.Eq{Column: clause.Column{Table: stmt.Schema.Table, Name: ownerCol}, Value: user.ID})
+
+	if len(stmt.Schema.PrimaryFields) == 1 {
+		pk := stmt.Schema.PrimaryFields[0]
+		ids := userGrantsQuery(db, user, stmt.Schema.Table, PermissionRead).Select("object_id")
+		sql := "? IN (?)"
+		if pk.DataType != schema.String && db.Dialector.Name() == "postgres" {
+			// object_id is a string, postgres doesn't compare it with the numbers
+			sql = "CAST(? AS TEXT) IN (?)"
+		}
+		cond = cond.Or(clause.Expr{SQL: sql, Vars: []any{clause.Column{Table: stmt.Schema.Table, Name: pk.DBName}, ids}})
+	}
+	return db.Where(cond), nil
+}
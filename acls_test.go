@@ -0,0 +1,199 @@
+package carrot
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestObjectACL(t *testing.T) {
+	type Note struct {
+		ID      uint   `json:"id" gorm:"primarykey"`
+		OwnerID uint   `json:"ownerId"`
+		Title   string `json:"title" gorm:"size:100"`
+	}
+
+	db, _ := InitDatabase(nil, "", "")
+	InitMigrate(db)
+	db.AutoMigrate(Note{})
+
+	alice, _ := CreateUser(db, "alice@restsend.com", "--")
+	bob, _ := CreateUser(db, "bob@restsend.com", "--")
+	carol, _ := CreateUser(db, "carol@restsend.com", "--")
+	group, _ := CreateGroupByUser(db, carol, "editors")
+
+	var current *User
+	r := gin.New()
+	r.Use(WithGormDB(db), func(ctx *gin.Context) {
+		ctx.Set(UserField, current)
+		ctx.Next()
+	})
+	webobject := WebObject{
+		Model:      Note{},
+		Editables:  []string{"Title"},
+		OwnerField: "OwnerID",
+	}
+	err := webobject.RegisterObject(&r.RouterGroup)
+	assert.Nil(t, err)
+	client := NewTestClient(r)
+
+	var note Note
+	current = alice
+	err = client.CallPut("/note", Note{Title: "alice note"}, &note)
+	assert.Nil(t, err)
+	assert.Equal(t, alice.ID, note.OwnerID)
+
+	current = bob
+	w := client.Get("/note/1")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	var res QueryResult
+	err = client.CallPost("/note", nil, &res)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, res.TotalCount)
+
+	// share with bob as read only
+	err = GrantUserPermission(db, &note, bob, PermissionRead)
+	assert.Nil(t, err)
+	assert.True(t, HasObjectPermission(db, &note, bob, PermissionRead))
+	assert.False(t, HasObjectPermission(db, &note, bob, PermissionUpdate))
+
+	w = client.Get("/note/1")
+	assert.Equal(t, http.StatusOK, w.Code)
+	err = client.CallPost("/note", nil, &res)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, res.TotalCount)
+
+	w = client.Post(http.MethodPatch, "/note/1", []byte(`{"title":"bob"}`))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// share with group members
+	current = carol
+	w = client.Post(http.MethodPatch, "/note/1", []byte(`{"title":"carol"}`))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	err = GrantGroupPermission(db, &note, group, PermissionUpdate)
+	assert.Nil(t, err)
+	w = client.Post(http.MethodPatch, "/note/1", []byte(`{"title":"carol"}`))
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = client.Post(http.MethodDelete, "/note/1", nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// the owner can't be taken by the update grant
+	w = client.Post(http.MethodPatch, "/note/1", []byte(`{"title":"carol","ownerId":3}`))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), ErrOwnerNotEditable.Error())
+
+	// revoke
+	current = bob
+	err = RevokeUserPermission(db, &note, bob)
+	assert.Nil(t, err)
+	w = client.Get("/note/1")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	current = nil
+	w = client.Get("/note/1")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	current = alice
+	w = client.Post(http.MethodDelete, "/note/1", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// the grants are deleted with the object, not applied to a new row with the same id
+	var c int64
+	db.Model(&ObjectPermission{}).Where("object_type", "notes").Count(&c)
+	assert.Equal(t, int64(0), c)
+	current = bob
+	db.Create(&Note{ID: 1, OwnerID: bob.ID, Title: "bob note"})
+	current = carol
+	w = client.Post(http.MethodPatch, "/note/1", []byte(`{"title":"carol"}`))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestAdminObjectACL(t *testing.T) {
+	type Memo struct {
+		ID      uint   `json:"id" gorm:"primarykey"`
+		OwnerID uint   `json:"ownerId"`
+		Title   string `json:"title" gorm:"size:100"`
+	}
+
+	r := gin.Default()
+	db, _ := InitDatabase(nil, "", "")
+	InitCarrot(db, r)
+	db.AutoMigrate(Memo{})
+
+	var keys []map[string]any
+	objs := append(GetCarrotAdminObjects(), AdminObject{
+		Model:      &Memo{},
+		Name:       "Memo",
+		Editables:  []string{"OwnerID", "Title"},
+		OwnerField: "OwnerID",
+		Actions: []AdminAction{{
+			Path:  "archive",
+			Name:  "Archive",
+			Batch: true,
+			Handler: func(db *gorm.DB, c *gin.Context, obj any) (bool, any, error) {
+				keys = obj.([]map[string]any)
+				return false, true, nil
+			},
+		}},
+	})
+	RegisterAdmins(r.Group("/admin"), db, objs)
+
+	alice, _ := CreateUser(db, "alice@restsend.com", "--")
+	bob, _ := CreateUser(db, "bob@restsend.com", "--")
+	bob.IsStaff = true
+	bob.Activated = true
+	db.Save(bob)
+	createAdminGroup(db, bob, GroupTypeAdmin, "memo.*")
+	db.Create(&Memo{OwnerID: alice.ID, Title: "alice memo"})
+
+	client := NewTestClient(r)
+	authClient(db, client, "bob@restsend.com", "--", false)
+
+	// the owner is always current user for non-superusers
+	var memo Memo
+	err := client.CallPut("/admin/memo/", gin.H{"title": "bob memo", "ownerId": alice.ID}, &memo)
+	assert.Nil(t, err)
+	assert.Equal(t, bob.ID, memo.OwnerID)
+
+	var ok bool
+	err = client.CallPost(`/admin/memo/archive?keys=[{"id":2}]`, nil, &ok)
+	assert.Nil(t, err)
+	assert.Len(t, keys, 1)
+
+	// the whole batch is rejected if any object is not accessible
+	keys = nil
+	w := client.Post(http.MethodPost, `/admin/memo/archive?keys=[{"id":2},{"id":1}]`, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Nil(t, keys)
+
+	// the owner can't be changed by non-superusers, even with the update grant
+	err = GrantUserPermission(db, &Memo{ID: 1}, bob, PermissionUpdate)
+	assert.Nil(t, err)
+	var result QueryResult
+	err = client.CallPost("/admin/memo/", &QueryForm{}, &result)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, result.TotalCount)
+
+	w = client.Post(http.MethodPatch, "/admin/memo/?id=1", []byte(`{"title":"taken","owner_id":2}`))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), ErrOwnerNotEditable.Error())
+	err = client.CallPatch("/admin/memo/?id=1", gin.H{"title": "shared"}, nil)
+	assert.Nil(t, err)
+	var shared Memo
+	db.First(&shared, 1)
+	assert.Equal(t, alice.ID, shared.OwnerID)
+	assert.Equal(t, "shared", shared.Title)
+
+	// the grants are deleted with the object
+	GrantUserPermission(db, &Memo{ID: 2}, alice, PermissionRead)
+	err = client.CallDelete("/admin/memo/?id=2", nil, nil)
+	assert.Nil(t, err)
+	var c int64
+	db.Model(&ObjectPermission{}).Where("object_type", "memos").Where("object_id", "2").Count(&c)
+	assert.Equal(t, int64(0), c)
+}
@@ -0,0 +1,171 @@
+package carrot
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+)
+
+const KeyAdminActionForm = "_carrot_admin_action_form"
+
+var ErrInvalidActionForm = errors.New("invalid action form")
+var errInvalidFormInteger = errors.New("must be an integer")
+var errInvalidFormUint = errors.New("must be a non-negative integer")
+
+// actionFormType return the go type of the form field type, such as "string", "int", "datetime".
+func actionFormType(name string) (reflect.Type, bool) {
+	name = strings.ToLower(name)
+	switch {
+	case name == "" || name == "string":
+		return reflect.TypeOf(""), true
+	case name == "bool":
+		return reflect.TypeOf(false), true
+	case name == "datetime" || name == "time":
+		return reflect.TypeOf(time.Time{}), true
+	case name == "decimal":
+		return decimalType, true
+	case strings.HasPrefix(name, "uint"):
+		return reflect.TypeOf(uint64(0)), true
+	case strings.HasPrefix(name, "int"):
+		return reflect.TypeOf(int64(0)), true
+	case strings.HasPrefix(name, "float"):
+		return reflect.TypeOf(float64(0)), true
+	}
+	return nil, false
+}
+
+// buildForm check the fields of the action form, the type is "string" if empty.
+func (action *AdminAction) buildForm() error {
+	for idx := range action.Form {
+		f := &action.Form[idx]
+		if f.Name == "" {
+			return fmt.Errorf("%w: %s has field without name", ErrInvalidActionForm, action.Path)
+		}
+		if f.Type == "" {
+			f.Type = "string"
+		}
+		et, ok := actionFormType(f.Type)
+		if !ok {
+			return fmt.Errorf("%w: %s.%s has invalid type %s", ErrInvalidActionForm, action.Path, f.Name, f.Type)
+		}
+		f.elemType = et
+		if f.Label == "" {
+			f.Label = cases.Title(language.Und).String(strings.ReplaceAll(f.Name, "_", " "))
+		}
+	}
+	return nil
+}
+
+// parseForm validate the values of the action form, the values are converted to the types of the fields.
+func (action *AdminAction) parseForm(vals map[string]any) (map[string]any, error) {
+	values := map[string]any{}
+	for _, f := range action.Form {
+		v, ok := vals[f.Name]
+		if !ok || v == nil || v == "" {
+			if f.Required {
+				return nil, fmt.Errorf("%w: %s is required", ErrInvalidActionForm, f.Label)
+			}
+			continue
+		}
+		v, err := parseFormInteger(f.elemType.Kind(), v)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s %v", ErrInvalidActionForm, f.Label, err)
+		}
+		cv, err := convertValue(f.elemType, v, false)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s %v", ErrInvalidActionForm, f.Label, err)
+		}
+		if rv := reflect.ValueOf(cv); rv.Kind() == reflect.Ptr {
+			cv = rv.Elem().Interface()
+		}
+		if f.Attribute != nil && len(f.Attribute.Choices) > 0 {
+			matched := false
+			for _, opt := range f.Attribute.Choices {
+				if fmt.Sprintf("%v", opt.Value) == fmt.Sprintf("%v", cv) {
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("%w: %s has invalid choice %v", ErrInvalidActionForm, f.Label, v)
+			}
+		}
+		values[f.Name] = cv
+	}
+	return values, nil
+}
+
+// parseFormInteger check the value is an integer for int fields, and not negative for uint fields,
+// the strings are parsed. The json numbers are float64, such as 1.5 and -1.
+func parseFormInteger(kind reflect.Kind, v any) (any, error) {
+	if kind != reflect.Int64 && kind != reflect.Uint64 {
+		return v, nil
+	}
+	var err error
+	switch val := v.(type) {
+	case string:
+		if kind == reflect.Uint64 {
+			v, err = strconv.ParseUint(val, 10, 64)
+		} else {
+			v, err = strconv.ParseInt(val, 10, 64)
+		}
+	case float64:
+		if val != math.Trunc(val) || (kind == reflect.Uint64 && val < 0) {
+			err = errInvalidFormInteger
+		}
+	default:
+		rv := reflect.ValueOf(v)
+		if rv.CanInt() && kind == reflect.Uint64 && rv.Int() < 0 {
+			err = errInvalidFormInteger
+		}
+	}
+	if err != nil {
+		if kind == reflect.Uint64 {
+			return nil, errInvalidFormUint
+		}
+		return nil, errInvalidFormInteger
+	}
+	return v, nil
+}
+
+// bindActionForm bind the action form from the json body, the validated values are set to the context.
+func bindActionForm(c *gin.Context, action *AdminAction) bool {
+	if len(action.Form) == 0 {
+		return true
+	}
+	vals := map[string]any{}
+	if c.Request.ContentLength > 0 {
+		if err := c.BindJSON(&vals); err != nil {
+			AbortWithJSONError(c, http.StatusBadRequest, err)
+			return false
+		}
+	}
+	values, err := action.parseForm(vals)
+	if err != nil {
+		AbortWithJSONError(c, http.StatusBadRequest, err)
+		return false
+	}
+	c.Set(KeyAdminActionForm, values)
+	return true
+}
+
+// GetActionForm return the validated values of the action form, such as:
+//
+//	reason := carrot.GetActionForm(c)["reason"].(string)
+func GetActionForm(c *gin.Context) map[string]any {
+	if v, ok := c.Get(KeyAdminActionForm); ok {
+		if values, ok := v.(map[string]any); ok {
+			return values
+		}
+	}
+	return map[string]any{}
+}
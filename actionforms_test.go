@@ -0,0 +1,111 @@
+package carrot
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestActionFormBuild(t *testing.T) {
+	action := AdminAction{Path: "extend", Form: []AdminField{
+		{Name: "reason", Required: true},
+		{Name: "expired_at", Type: "datetime"},
+		{Name: "days", Type: "int"},
+	}}
+	assert.Nil(t, action.buildForm())
+	assert.Equal(t, "string", action.Form[0].Type)
+	assert.Equal(t, "Expired At", action.Form[1].Label)
+
+	_, err := action.parseForm(map[string]any{"days": 3})
+	assert.ErrorIs(t, err, ErrInvalidActionForm)
+
+	values, err := action.parseForm(map[string]any{"reason": "renew", "expired_at": "2024-01-02", "days": "3", "mock": 1})
+	assert.Nil(t, err)
+	assert.Equal(t, "renew", values["reason"])
+	assert.Equal(t, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), values["expired_at"])
+	assert.Equal(t, int64(3), values["days"])
+	assert.NotContains(t, values, "mock")
+
+	_, err = action.parseForm(map[string]any{"reason": "renew", "days": "x"})
+	assert.NotNil(t, err)
+	_, err = action.parseForm(map[string]any{"reason": "renew", "days": 1.5})
+	assert.ErrorIs(t, err, ErrInvalidActionForm)
+	values, err = action.parseForm(map[string]any{"reason": "renew", "days": float64(-2)})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(-2), values["days"])
+
+	counter := AdminAction{Path: "count", Form: []AdminField{{Name: "count", Type: "uint"}}}
+	assert.Nil(t, counter.buildForm())
+	for _, v := range []any{"-1", float64(-1), -1, 1.5, "1.5", "x"} {
+		_, err = counter.parseForm(map[string]any{"count": v})
+		assert.ErrorIs(t, err, ErrInvalidActionForm, v)
+	}
+	values, err = counter.parseForm(map[string]any{"count": "18446744073709551615"})
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(18446744073709551615), values["count"])
+	values, err = counter.parseForm(map[string]any{"count": float64(3)})
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(3), values["count"])
+
+	invalid := AdminAction{Path: "mock", Form: []AdminField{{Name: "mock", Type: "chan"}}}
+	assert.ErrorIs(t, invalid.buildForm(), ErrInvalidActionForm)
+	invalid = AdminAction{Path: "mock", Form: []AdminField{{Type: "string"}}}
+	assert.ErrorIs(t, invalid.buildForm(), ErrInvalidActionForm)
+}
+
+func TestAdminActionForm(t *testing.T) {
+	r := gin.Default()
+	db, _ := InitDatabase(nil, "", "")
+	InitCarrot(db, r)
+
+	var form map[string]any
+	objs := GetCarrotAdminObjects()
+	for idx := range objs {
+		if objs[idx].Name != "User" {
+			continue
+		}
+		objs[idx].Actions = append(objs[idx].Actions, AdminAction{
+			Path: "disable",
+			Name: "Disable",
+			Form: []AdminField{
+				{Name: "reason", Required: true},
+				{Name: "level", Type: "string", Attribute: &AdminAttribute{
+					Choices: []AdminSelectOption{{Label: "Low", Value: "low"}, {Label: "High", Value: "high"}},
+				}},
+			},
+			Handler: func(db *gorm.DB, c *gin.Context, obj any) (bool, any, error) {
+				form = GetActionForm(c)
+				return false, form["reason"], nil
+			},
+		})
+	}
+	RegisterAdmins(r.Group("/admin"), db, objs)
+	client := NewTestClient(r)
+	authClient(db, client, "bob@restsend.com", "--", true)
+
+	var meta AdminObject
+	for _, obj := range objs {
+		if obj.Name == "User" {
+			meta = obj
+		}
+	}
+	action := meta.Actions[len(meta.Actions)-1]
+	assert.Equal(t, "Reason", action.Form[0].Label)
+
+	var reason string
+	err := client.CallPost("/admin/user/disable?email=bob@restsend.com", gin.H{"reason": "spam", "level": "high"}, &reason)
+	assert.Nil(t, err)
+	assert.Equal(t, "spam", reason)
+	assert.Equal(t, map[string]any{"reason": "spam", "level": "high"}, form)
+
+	w := client.Post(http.MethodPost, "/admin/user/disable?email=bob@restsend.com", []byte(`{"level":"low"}`))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "Reason is required")
+
+	w = client.Post(http.MethodPost, "/admin/user/disable?email=bob@restsend.com", []byte(`{"reason":"spam","level":"mock"}`))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
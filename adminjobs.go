@@ -0,0 +1,326 @@
+package carrot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	AdminJobPending  = "pending"
+	AdminJobRunning  = "running"
+	AdminJobDone     = "done"
+	AdminJobFailed   = "failed"
+	AdminJobCanceled = "canceled"
+)
+
+var ErrAdminJobNotFinished = errors.New("admin job is not finished")
+var ErrAdminJobNoOutput = errors.New("admin job has no output")
+
+// AdminJob records an action running in the background, the status is polled by the admin UI.
+//
+//   - Status: AdminJobPending, AdminJobRunning, AdminJobDone, AdminJobFailed or AdminJobCanceled
+//   - Processed/Total: the progress reported by the handler
+//   - Result: the json result returned by the handler
+//   - Output: the file set by the handler, can be downloaded when the job is done
+type AdminJob struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	CreatedAt   time.Time  `json:"createdAt" gorm:"autoCreateTime;index"`
+	UpdatedAt   time.Time  `json:"updatedAt" gorm:"autoUpdateTime"`
+	UserID      uint       `json:"-" gorm:"index"`
+	ObjectType  string     `json:"objectType" gorm:"size:128;index"`
+	Action      string     `json:"action" gorm:"size:64"`
+	Status      string     `json:"status" gorm:"size:20;index"`
+	Total       int        `json:"total"`
+	Processed   int        `json:"processed"`
+	Message     string     `json:"message,omitempty" gorm:"size:200"`
+	Error       string     `json:"error,omitempty"`
+	Result      string     `json:"result,omitempty"`
+	FileName    string     `json:"fileName,omitempty" gorm:"size:200"`
+	ContentType string     `json:"-" gorm:"size:100"`
+	Output      []byte     `json:"-"`
+	FinishedAt  *time.Time `json:"finishedAt,omitempty"`
+}
+
+// AdminJobHandler runs the action in the background, the result is saved as json.
+type AdminJobHandler func(jc *AdminJobContext) (any, error)
+
+// AdminJobContext is passed to AdminJobHandler, it is canceled when the job is canceled.
+type AdminJobContext struct {
+	context.Context
+	DB      *gorm.DB         // Database session with the context of the job
+	Job     *AdminJob        // The job record
+	User    *User            // The user who started the job
+	Keys    []map[string]any // Primary keys of the objects, empty if the action is WithoutObject
+	Form    map[string]any   // Values of the action form
+	db      *gorm.DB
+	cancel  context.CancelFunc
+	handler AdminJobHandler
+}
+
+// AdminJobWorker runs the background actions, change Num and QueueSize before the first job.
+// The jobs run in the request if QueueSize is 0.
+var AdminJobWorker = &Worker[*AdminJobContext]{
+	Name:      "admin-jobs",
+	Num:       2,
+	QueueSize: 128,
+}
+
+var adminJobWorkerOnce sync.Once
+var adminJobCancels sync.Map // job id -> context.CancelFunc
+
+// SetProgress save the progress of the job, return the error of the context if the job is canceled.
+func (jc *AdminJobContext) SetProgress(processed, total int, message string) error {
+	jc.Job.Processed = processed
+	jc.Job.Total = total
+	jc.Job.Message = message
+	err := jc.db.Model(jc.Job).Select("Processed", "Total", "Message").Updates(jc.Job).Error
+	if err != nil {
+		return err
+	}
+	return jc.Err()
+}
+
+// SetOutput set the file of the job, it is saved when the job is done.
+func (jc *AdminJobContext) SetOutput(fileName, contentType string, data []byte) {
+	jc.Job.FileName = fileName
+	jc.Job.ContentType = contentType
+	jc.Job.Output = data
+}
+
+// startAdminJob create the job record and push it to AdminJobWorker.
+func startAdminJob(c *gin.Context, db *gorm.DB, objectType string, action *AdminAction, keys []map[string]any) (*AdminJob, error) {
+	user := CurrentUser(c)
+	job := &AdminJob{
+		ObjectType: objectType,
+		Action:     action.Path,
+		Status:     AdminJobPending,
+	}
+	if user != nil {
+		job.UserID = user.ID
+	}
+	if err := db.Create(job).Error; err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	base := db.Session(&gorm.Session{NewDB: true}).WithContext(context.Background())
+	jc := &AdminJobContext{
+		Context: ctx,
+		DB:      base.WithContext(ctx),
+		Job:     job,
+		User:    user,
+		Keys:    keys,
+		Form:    GetActionForm(c),
+		db:      base,
+		cancel:  cancel,
+		handler: action.JobHandler,
+	}
+	adminJobCancels.Store(job.ID, cancel)
+	created := *job // the job is changed by the worker
+
+	adminJobWorkerOnce.Do(func() {
+		AdminJobWorker.Handler = runAdminJob
+		AdminJobWorker.Start(context.Background())
+	})
+	if err := AdminJobWorker.Push(jc); err != nil {
+		jc.finish(nil, err)
+		return nil, err
+	}
+	return &created, nil
+}
+
+// runAdminJob is the handler of AdminJobWorker, the canceled jobs are skipped.
+func runAdminJob(jc *AdminJobContext) (err error) {
+	if jc.Err() != nil {
+		jc.finish(nil, jc.Err())
+		return jc.Err()
+	}
+	jc.Job.Status = AdminJobRunning
+	if err := jc.db.Model(jc.Job).Select("Status").Updates(jc.Job).Error; err != nil {
+		jc.finish(nil, err)
+		return err
+	}
+
+	var result any
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+			logrus.WithFields(logrus.Fields{
+				"id":     jc.Job.ID,
+				"action": jc.Job.Action,
+			}).WithError(err).Warn("admin: job panic")
+		}
+		jc.finish(result, err)
+	}()
+	result, err = jc.handler(jc)
+	return err
+}
+
+// finish save the status and result of the job.
+func (jc *AdminJobContext) finish(result any, err error) {
+	defer adminJobCancels.Delete(jc.Job.ID)
+	defer jc.cancel()
+
+	now := time.Now()
+	jc.Job.FinishedAt = &now
+	switch {
+	case err == nil:
+		jc.Job.Status = AdminJobDone
+		if result != nil {
+			if s, ok := result.(string); ok {
+				jc.Job.Result = s
+			} else if data, err := json.Marshal(result); err == nil {
+				jc.Job.Result = string(data)
+			}
+		}
+	case errors.Is(err, context.Canceled):
+		jc.Job.Status = AdminJobCanceled
+	default:
+		jc.Job.Status = AdminJobFailed
+		jc.Job.Error = err.Error()
+	}
+	err = jc.db.Model(jc.Job).Select("Status", "Error", "Result", "FileName", "ContentType", "Output", "FinishedAt").Updates(jc.Job).Error
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"id":     jc.Job.ID,
+			"action": jc.Job.Action,
+		}).WithError(err).Warn("admin: save job fail")
+	}
+}
+
+// handleAsyncAction start the action in the background and return the job.
+func (obj *AdminObject) handleAsyncAction(c *gin.Context, db *gorm.DB, action *AdminAction) {
+	var keys []map[string]any
+	var logObjs []any
+	if action.Batch {
+		var ok bool
+		if keys, logObjs, ok = obj.getBatchObjects(c, db); !ok {
+			return
+		}
+	} else if !action.WithoutObject {
+		key := obj.getPrimaryValues(c)
+		if len(key) <= 0 {
+			AbortWithJSONError(c, http.StatusBadRequest, ErrInvalidPrimaryKey)
+			return
+		}
+		val := reflect.New(obj.modelElem).Interface()
+		if err := db.Where(key).First(val).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				AbortWithJSONError(c, http.StatusNotFound, ErrNotFound)
+			} else {
+				AbortWithJSONError(c, http.StatusInternalServerError, err)
+			}
+			return
+		}
+		if err := obj.checkAccess(c, db, val, PermissionUpdate); err != nil {
+			abortWithAccessError(c, err)
+			return
+		}
+		keys = []map[string]any{key}
+		logObjs = []any{val}
+	}
+
+	job, err := startAdminJob(c, db, obj.tableName, action, keys)
+	if err != nil {
+		AbortWithJSONError(c, http.StatusServiceUnavailable, err)
+		return
+	}
+	if action.WithoutObject {
+		obj.writeLog(c, nil, action.Path)
+	}
+	for _, val := range logObjs {
+		obj.writeLog(c, val, action.Path)
+	}
+	RenderJSON(c, http.StatusOK, job)
+}
+
+// getJob return the job of the object by id, the users only can access their own jobs except superusers.
+func (obj *AdminObject) getJob(c *gin.Context, db *gorm.DB) (*AdminJob, bool) {
+	var job AdminJob
+	tx := db.Where("id", c.Query("id")).Where("object_type", obj.tableName)
+	if user := CurrentUser(c); user == nil || !user.IsSuperUser {
+		if user == nil {
+			AbortWithJSONError(c, http.StatusForbidden, ErrForbidden)
+			return nil, false
+		}
+		tx = tx.Where("user_id", user.ID)
+	}
+	if err := tx.Omit("Output").Take(&job).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			AbortWithJSONError(c, http.StatusNotFound, ErrNotFound)
+		} else {
+			AbortWithJSONError(c, http.StatusInternalServerError, err)
+		}
+		return nil, false
+	}
+	return &job, true
+}
+
+// handleJob return the status of the job.
+func (obj *AdminObject) handleJob(c *gin.Context) {
+	job, ok := obj.getJob(c, getDbConnection(c, obj.GetDB, false))
+	if !ok {
+		return
+	}
+	RenderJSON(c, http.StatusOK, job)
+}
+
+// handleCancelJob cancel the pending or running job, the running job is canceled by its context.
+func (obj *AdminObject) handleCancelJob(c *gin.Context) {
+	db := getDbConnection(c, obj.GetDB, false)
+	job, ok := obj.getJob(c, db)
+	if !ok {
+		return
+	}
+	if job.Status != AdminJobPending && job.Status != AdminJobRunning {
+		RenderJSON(c, http.StatusOK, false)
+		return
+	}
+	if cancel, ok := adminJobCancels.Load(job.ID); ok {
+		cancel.(context.CancelFunc)()
+	} else {
+		// the job is lost, such as the server is restarted
+		now := time.Now()
+		db.Model(job).Where("status IN ?", []string{AdminJobPending, AdminJobRunning}).
+			Updates(map[string]any{"status": AdminJobCanceled, "finished_at": &now})
+	}
+	RenderJSON(c, http.StatusOK, true)
+}
+
+// handleDownloadJob send the output file of the done job.
+func (obj *AdminObject) handleDownloadJob(c *gin.Context) {
+	db := getDbConnection(c, obj.GetDB, false)
+	job, ok := obj.getJob(c, db)
+	if !ok {
+		return
+	}
+	if job.Status != AdminJobDone {
+		AbortWithJSONError(c, http.StatusBadRequest, ErrAdminJobNotFinished)
+		return
+	}
+	if job.FileName == "" {
+		AbortWithJSONError(c, http.StatusNotFound, ErrAdminJobNoOutput)
+		return
+	}
+	if err := db.Model(job).Select("Output").Take(job).Error; err != nil {
+		AbortWithJSONError(c, http.StatusInternalServerError, err)
+		return
+	}
+	contentType := job.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": job.FileName}))
+	c.Data(http.StatusOK, contentType, job.Output)
+}
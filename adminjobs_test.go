@@ -0,0 +1,135 @@
+package carrot
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminJob(t *testing.T) {
+	r := gin.Default()
+	db, _ := InitDatabase(nil, "", "")
+	// the jobs run in other goroutines, keep them on the same memory database
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	InitCarrot(db, r)
+
+	release := make(chan struct{})
+	objs := GetCarrotAdminObjects()
+	for idx := range objs {
+		if objs[idx].Name != "User" {
+			continue
+		}
+		objs[idx].Actions = append(objs[idx].Actions,
+			AdminAction{
+				Path:  "report",
+				Name:  "Report",
+				Batch: true,
+				Form:  []AdminField{{Name: "title", Required: true}},
+				JobHandler: func(jc *AdminJobContext) (any, error) {
+					for i := range jc.Keys {
+						if err := jc.SetProgress(i+1, len(jc.Keys), "exporting"); err != nil {
+							return nil, err
+						}
+					}
+					jc.SetOutput("report 1.txt", "text/plain", []byte(jc.Form["title"].(string)))
+					return gin.H{"count": len(jc.Keys)}, nil
+				},
+			},
+			AdminAction{
+				Path:          "block",
+				Name:          "Block",
+				WithoutObject: true,
+				JobHandler: func(jc *AdminJobContext) (any, error) {
+					<-release
+					<-jc.Done()
+					return nil, jc.Err()
+				},
+			},
+			AdminAction{
+				Path:          "fail",
+				Name:          "Fail",
+				WithoutObject: true,
+				JobHandler: func(jc *AdminJobContext) (any, error) {
+					panic("mock panic")
+				},
+			},
+		)
+	}
+	RegisterAdmins(r.Group("/admin"), db, objs)
+	client := NewTestClient(r)
+	authClient(db, client, "bob@restsend.com", "--", true)
+	CreateUser(db, "alice@restsend.com", "--")
+
+	waitJob := func(id uint, status string) AdminJob {
+		var job AdminJob
+		for i := 0; i < 100; i++ {
+			err := client.CallPost(fmt.Sprintf("/admin/user/_job?id=%d", id), nil, &job)
+			assert.Nil(t, err)
+			if job.Status == status {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		return job
+	}
+
+	var job AdminJob
+	err := client.CallPost(`/admin/user/report?keys=[{"id":1},{"id":2}]`, gin.H{"title": "hello"}, &job)
+	assert.Nil(t, err)
+	assert.NotZero(t, job.ID)
+	assert.Equal(t, "report", job.Action)
+
+	job = waitJob(job.ID, AdminJobDone)
+	assert.Equal(t, AdminJobDone, job.Status)
+	assert.Equal(t, 2, job.Processed)
+	assert.Equal(t, 2, job.Total)
+	assert.Equal(t, `{"count":2}`, job.Result)
+	assert.Equal(t, "report 1.txt", job.FileName)
+	assert.NotNil(t, job.FinishedAt)
+
+	w := client.Post(http.MethodPost, fmt.Sprintf("/admin/user/_job_download?id=%d", job.ID), nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "hello", w.Body.String())
+	assert.Equal(t, `attachment; filename="report 1.txt"`, w.Header().Get("Content-Disposition"))
+
+	// the form is validated before the job is created
+	w = client.Post(http.MethodPost, `/admin/user/report?keys=[{"id":1}]`, []byte(`{}`))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// cancel the running job
+	err = client.CallPost("/admin/user/block", nil, &job)
+	assert.Nil(t, err)
+	w = client.Post(http.MethodPost, fmt.Sprintf("/admin/user/_job_download?id=%d", job.ID), nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var ok bool
+	err = client.CallPost(fmt.Sprintf("/admin/user/_job_cancel?id=%d", job.ID), nil, &ok)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	close(release)
+	job = waitJob(job.ID, AdminJobCanceled)
+	assert.Equal(t, AdminJobCanceled, job.Status)
+
+	err = client.CallPost(fmt.Sprintf("/admin/user/_job_cancel?id=%d", job.ID), nil, &ok)
+	assert.Nil(t, err)
+	assert.False(t, ok)
+
+	err = client.CallPost("/admin/user/fail", nil, &job)
+	assert.Nil(t, err)
+	job = waitJob(job.ID, AdminJobFailed)
+	assert.Equal(t, AdminJobFailed, job.Status)
+	assert.Equal(t, "mock panic", job.Error)
+
+	// the job of other objects is not found
+	err = client.CallPost(fmt.Sprintf("/admin/group/_job?id=%d", job.ID), nil, &job)
+	assert.NotNil(t, err)
+
+	var count int64
+	db.Model(&AdminLog{}).Where("action", "report").Count(&count)
+	assert.Equal(t, int64(2), count)
+}
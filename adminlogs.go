@@ -0,0 +1,187 @@
+package carrot
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	AdminLogCreate = "create"
+	AdminLogUpdate = "update"
+	AdminLogDelete = "delete"
+)
+
+const DefaultAdminLogLimit = 50
+
+var ErrAdminLogReadOnly = errors.New("admin log is read only")
+
+// AdminLog records a change made in the admin by user.
+//
+//   - ObjectType: table name of the model, such as "users"
+//   - ObjectID: primary key value of the row, composite keys are joined with ","
+//   - Action: AdminLogCreate, AdminLogUpdate, AdminLogDelete or the path of the action
+//   - Fields: the changed fields, joined with ","
+type AdminLog struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	CreatedAt  time.Time `json:"createdAt" gorm:"autoCreateTime;index"`
+	UserID     uint      `json:"-" gorm:"index"`
+	User       User      `json:"user"`
+	ObjectType string    `json:"objectType" gorm:"size:128;index:idx_admin_log_object"`
+	ObjectID   string    `json:"objectId" gorm:"size:200;index:idx_admin_log_object"`
+	Action     string    `json:"action" gorm:"size:64"`
+	Fields     string    `json:"fields,omitempty"`
+}
+
+// WriteAdminLog records the change of obj made by user.
+func WriteAdminLog(db *gorm.DB, user *User, obj any, action string, fields ...string) error {
+	objectType, objectID, err := GetObjectIdentity(db, obj)
+	if err != nil {
+		return err
+	}
+	return createAdminLog(db, user, objectType, objectID, action, fields)
+}
+
+func createAdminLog(db *gorm.DB, user *User, objectType, objectID, action string, fields []string) error {
+	val := AdminLog{
+		ObjectType: objectType,
+		ObjectID:   objectID,
+		Action:     action,
+		Fields:     strings.Join(fields, ","),
+	}
+	if user != nil {
+		val.UserID = user.ID
+	}
+	return db.Omit("User").Create(&val).Error
+}
+
+// GetAdminLogs return the latest logs of obj, the newest first.
+func GetAdminLogs(db *gorm.DB, obj any, limit int) ([]AdminLog, error) {
+	objectType, objectID, err := GetObjectIdentity(db, obj)
+	if err != nil {
+		return nil, err
+	}
+	tx := db.Where("object_type", objectType).Where("object_id", objectID).Preload("User").Order("id DESC")
+	if limit > 0 {
+		tx = tx.Limit(limit)
+	}
+	var vals []AdminLog
+	result := tx.Find(&vals)
+	return vals, result.Error
+}
+
+// logDB return the default db for the logs, or the db of obj if the default db is not set.
+func (obj *AdminObject) logDB(c *gin.Context) *gorm.DB {
+	if _, ok := c.Get(DbField); ok {
+		return getDbConnection(c, nil, false)
+	}
+	return getDbConnection(c, obj.GetDB, false)
+}
+
+// writeLog records the change of val made by current user, val is nil for actions without object.
+// The errors are logged and ignored.
+func (obj *AdminObject) writeLog(c *gin.Context, val any, action string, fields ...string) {
+	db := obj.logDB(c)
+	var err error
+	if val == nil {
+		err = createAdminLog(db, CurrentUser(c), obj.tableName, "", action, fields)
+	} else {
+		err = WriteAdminLog(db, CurrentUser(c), val, action, fields...)
+	}
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"name":   obj.Name,
+			"action": action,
+		}).WithError(err).Warn("admin: write log fail")
+	}
+}
+
+// changedFields return the names of fields in vals, which values are different between old and val.
+// All fields are compared if vals is nil, such as the fields changed by BeforeUpdate.
+func (obj *AdminObject) changedFields(old, val any, vals map[string]any) []string {
+	ov := reflect.Indirect(reflect.ValueOf(old))
+	nv := reflect.Indirect(reflect.ValueOf(val))
+	var fields []string
+	for _, field := range obj.Fields {
+		if _, ok := vals[field.Name]; !ok && vals != nil {
+			continue
+		}
+		name := field.fieldName
+		if field.Foreign != nil {
+			name = field.Foreign.foreignKey
+		}
+		of, nf := ov.FieldByName(name), nv.FieldByName(name)
+		if !of.IsValid() || !nf.IsValid() {
+			continue
+		}
+		if !sameAdminValue(of.Interface(), nf.Interface()) {
+			fields = append(fields, field.Name)
+		}
+	}
+	return fields
+}
+
+// sameAdminValue compare the values by json too, such as the times in different locations.
+func sameAdminValue(a, b any) bool {
+	if reflect.DeepEqual(a, b) {
+		return true
+	}
+	av, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	bv, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(av, bv)
+}
+
+// handleHistory return the logs of the object, the newest first.
+func (obj *AdminObject) handleHistory(c *gin.Context) {
+	if !obj.checkPermission(c, PermissionRead) {
+		return
+	}
+	keys := obj.getPrimaryValues(c)
+	if len(keys) <= 0 {
+		AbortWithJSONError(c, http.StatusBadRequest, ErrInvalidPrimaryKey)
+		return
+	}
+
+	db := getDbConnection(c, obj.GetDB, false)
+	val := reflect.New(obj.modelElem).Interface()
+	result := db.Where(keys).Take(val)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			AbortWithJSONError(c, http.StatusNotFound, ErrNotFound)
+		} else {
+			AbortWithJSONError(c, http.StatusInternalServerError, result.Error)
+		}
+		return
+	}
+
+	if err := obj.checkAccess(c, db, val, PermissionRead); err != nil {
+		abortWithAccessError(c, err)
+		return
+	}
+
+	limit := DefaultAdminLogLimit
+	if v, err := strconv.Atoi(c.Query("limit")); err == nil && v > 0 {
+		limit = v
+	}
+	logs, err := GetAdminLogs(obj.logDB(c), val, limit)
+	if err != nil {
+		AbortWithJSONError(c, http.StatusInternalServerError, err)
+		return
+	}
+	RenderJSON(c, http.StatusOK, logs)
+}
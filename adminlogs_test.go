@@ -0,0 +1,93 @@
+package carrot
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteAdminLog(t *testing.T) {
+	db, _ := InitDatabase(nil, "", "")
+	InitMigrate(db)
+	bob, _ := CreateUser(db, "bob@restsend.com", "--")
+	alice, _ := CreateUser(db, "alice@restsend.com", "--")
+
+	err := WriteAdminLog(db, bob, alice, AdminLogUpdate, "email", "display_name")
+	assert.Nil(t, err)
+	err = WriteAdminLog(db, bob, alice, "toggle_staff")
+	assert.Nil(t, err)
+	err = WriteAdminLog(db, bob, bob, AdminLogUpdate, "email")
+	assert.Nil(t, err)
+
+	logs, err := GetAdminLogs(db, alice, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(logs))
+	assert.Equal(t, "toggle_staff", logs[0].Action)
+	assert.Equal(t, AdminLogUpdate, logs[1].Action)
+	assert.Equal(t, "users", logs[1].ObjectType)
+	assert.Equal(t, "email,display_name", logs[1].Fields)
+	assert.Equal(t, "bob@restsend.com", logs[1].User.Email)
+
+	logs, err = GetAdminLogs(db, alice, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(logs))
+}
+
+func TestAdminHistory(t *testing.T) {
+	_, db, client := createAdminTest()
+	CreateUser(db, "alice@restsend.com", "1")
+
+	var r Config
+	err := client.CallPut("/admin/config/", gin.H{"key": "test", "value": "mock"}, &r)
+	assert.Nil(t, err)
+
+	keys := fmt.Sprintf("?id=%d", r.ID)
+	var item map[string]any
+	err = client.CallPatch("/admin/config/"+keys, gin.H{"key": "test", "value": "mock2"}, &item)
+	assert.Nil(t, err)
+	assert.Equal(t, "mock2", item["value"])
+
+	var ok bool
+
+	var logs []AdminLog
+	err = client.CallPost("/admin/config/_history"+keys, nil, &logs)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(logs))
+	assert.Equal(t, AdminLogUpdate, logs[0].Action)
+	assert.Equal(t, "value", logs[0].Fields)
+	assert.Equal(t, "bob@restsend.com", logs[0].User.Email)
+	assert.Equal(t, AdminLogCreate, logs[1].Action)
+	assert.Equal(t, "key,value", logs[1].Fields)
+
+	err = client.CallPost("/admin/user/toggle_staff?email=alice@restsend.com", nil, &ok)
+	assert.Nil(t, err)
+	logs = nil
+	err = client.CallPost("/admin/user/_history?email=alice@restsend.com", nil, &logs)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(logs))
+	assert.Equal(t, "toggle_staff", logs[0].Action)
+
+	err = client.CallDelete("/admin/config/"+keys, nil, &ok)
+	assert.Nil(t, err)
+	var count int64
+	db.Model(&AdminLog{}).Where("object_type", "configs").Where("action", AdminLogDelete).Count(&count)
+	assert.Equal(t, int64(1), count)
+
+	err = client.CallPost("/admin/config/_history"+keys, nil, &logs)
+	assert.NotNil(t, err)
+
+	// the builtin admin log object is read only
+	err = client.CallPatch("/admin/adminlog/?id=1", gin.H{"action": "mock"}, nil)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), ErrAdminLogReadOnly.Error())
+	err = client.CallDelete("/admin/adminlog/?id=1", nil, nil)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), ErrAdminLogReadOnly.Error())
+
+	var result AdminQueryResult
+	err = client.CallPost("/admin/adminlog/", &QueryForm{}, &result)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(result.Items))
+}
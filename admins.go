@@ -28,6 +28,9 @@ const KEY_ADMIN_DASHBOARD = "ADMIN_DASHBOARD"
 const KEY_ADMIN_WITHOUT_BUILTIN_SCRIPTS = "ADMIN_WITHOUT_BUILTIN_SCRIPTS"
 const KeyAdminQueryForm = "_carrot_admin_query_form"
 
+// DefaultForeignLimit is the page size of the foreign mode query if the limit is not set
+const DefaultForeignLimit = 20
+
 type AdminBuildContext func(*gin.Context, map[string]any) map[string]any
 
 type AdminQueryResult struct {
@@ -35,6 +38,7 @@ type AdminQueryResult struct {
 	Pos        int              `json:"pos,omitempty"`
 	Limit      int              `json:"limit,omitempty"`
 	Keyword    string           `json:"keyword,omitempty"`
+	TotalKind  string           `json:"totalKind,omitempty"` // exact, estimated or none
 	Items      []map[string]any `json:"items"`
 	objects    []any            `json:"-"`
 }
@@ -60,9 +64,13 @@ type AdminAttribute struct {
 type AdminForeign struct {
 	Path       string `json:"path"`
 	Field      string `json:"field"`
+	Many2Many  bool   `json:"many2many,omitempty"` // The values are the primary keys of the associated objects
 	fieldName  string `json:"-"`
 	foreignKey string `json:"-"`
+	primaryKey string `json:"-"` // Primary key field of the associated object, only for many2many
 	hasMany    bool   `json:"-"`
+
+	searchables []string // Searchable columns of the associated admin object, used to resolve the labels
 }
 type AdminValue struct {
 	Value any    `json:"value"`
@@ -103,7 +111,10 @@ type AdminAction struct {
 	Class         string             `json:"class,omitempty"`
 	WithoutObject bool               `json:"withoutObject"`
 	Batch         bool               `json:"batch,omitempty"`
+	Form          []AdminField       `json:"form,omitempty"`  // Input fields asked before the action, the values are in GetActionForm
+	Async         bool               `json:"async,omitempty"` // Run JobHandler in the background, set by JobHandler
 	Handler       AdminActionHandler `json:"-"`
+	JobHandler    AdminJobHandler    `json:"-"` // Run the action on AdminJobWorker, the status is polled by the job id
 }
 
 type AdminObject struct {
@@ -129,12 +140,16 @@ type AdminObject struct {
 	Styles      []string        `json:"styles,omitempty"`
 	Permissions map[string]bool `json:"permissions,omitempty"`
 	Actions     []AdminAction   `json:"actions,omitempty"`
+	Inlines     []AdminInline   `json:"inlines,omitempty"`   // Has many children edited in the parent edit page
+	LockField   string          `json:"lockField,omitempty"` // Optimistic lock field, such as "UpdatedAt" or an integer "Version"
 	Icon        *AdminIcon      `json:"icon,omitempty"`
 	Invisible   bool            `json:"invisible,omitempty"`
 	ViewOnSite  AdminViewOnSite `json:"-"`
 
 	Attributes       map[string]AdminAttribute `json:"-"` // Field's extra attributes
 	AccessCheck      AdminAccessCheck          `json:"-"` // Access control function
+	OwnerField       string                    `json:"-"` // Owner user id field, enables row-level access control for non-superusers
+	StateMachine     *StateMachine             `json:"-"` // State field can only be changed by transitions
 	GetDB            GetDB                     `json:"-"`
 	BeforeCreate     BeforeCreateFunc          `json:"-"`
 	BeforeRender     BeforeRenderFunc          `json:"-"`
@@ -144,13 +159,14 @@ type AdminObject struct {
 	modelElem        reflect.Type              `json:"-"`
 	ignores          map[string]bool           `json:"-"`
 	primaryKeyMaping map[string]string         `json:"-"`
+	lockFieldName    string                    `json:"-"`
 }
 
 // Returns all admin objects
 func GetCarrotAdminObjects() []AdminObject {
 
 	superAccessCheck := func(c *gin.Context, obj *AdminObject) error {
-		if !CurrentUser(c).IsSuperUser {
+		if user := CurrentUser(c); user == nil || !user.IsSuperUser {
 			return ErrOnlySuperUser
 		}
 		return nil
@@ -160,6 +176,7 @@ func GetCarrotAdminObjects() []AdminObject {
 	iconGroup, _ := EmbedStaticAssets.ReadFile("static/img/icon_group.svg")
 	iconMembers, _ := EmbedStaticAssets.ReadFile("static/img/icon_members.svg")
 	iconConfig, _ := EmbedStaticAssets.ReadFile("static/img/icon_config.svg")
+	iconHistory, _ := EmbedStaticAssets.ReadFile("static/img/icon_history.svg")
 
 	return []AdminObject{
 		{
@@ -169,10 +186,11 @@ func GetCarrotAdminObjects() []AdminObject {
 			Desc:        "Builtin user management system",
 			Shows:       []string{"ID", "Email", "DisplayName", "IsStaff", "IsSuperUser", "Enabled", "Activated", "UpdatedAt", "LastLogin", "LastLoginIP", "Source", "Locale", "Timezone"},
 			Editables:   []string{"Email", "Password", "DisplayName", "FirstName", "LastName", "IsStaff", "IsSuperUser", "Enabled", "Activated", "Profile", "Source", "Locale", "Timezone"},
-			Filterables: []string{"CreatedAt", "UpdatedAt", "IsStaff", "IsSuperUser", "Enabled", "Activated "},
+			Filterables: []string{"CreatedAt", "UpdatedAt", "IsStaff", "IsSuperUser", "Enabled", "Activated"},
 			Orderables:  []string{"CreatedAt", "UpdatedAt", "Enabled", "Activated"},
 			Searchables: []string{"Email", "DisplayName"},
 			Orders:      []Order{{"UpdatedAt", OrderOpDesc}},
+			LockField:   "UpdatedAt",
 			Icon:        &AdminIcon{SVG: string(iconUser)},
 			AccessCheck: superAccessCheck,
 			BeforeCreate: func(db *gorm.DB, c *gin.Context, obj any) error {
@@ -234,20 +252,24 @@ func GetCarrotAdminObjects() []AdminObject {
 			Model:       &Group{},
 			Group:       "Settings",
 			Name:        "Group",
-			Desc:        "A group describes a group of users. One user can be part of many groups and one group can have many users", //
+			Desc:        "A group describes a group of users. One user can be part of many groups and one group can have many users",
 			Shows:       []string{"ID", "Name", "Extra", "UpdatedAt", "CreatedAt"},
-			Editables:   []string{"ID", "Name", "Extra", "UpdatedAt"},
+			Editables:   []string{"ID", "Name", "UpdatedAt"},
 			Orderables:  []string{"UpdatedAt"},
 			Searchables: []string{"Name"},
+			LockField:   "UpdatedAt",
 			Requireds:   []string{"Name"},
 			Icon:        &AdminIcon{SVG: string(iconGroup)},
 			AccessCheck: superAccessCheck,
+			Inlines: []AdminInline{
+				{Field: "Extra", Editables: []string{"Key", "Value"}, Requireds: []string{"Key"}},
+			},
 		},
 		{
 			Model:       &GroupMember{},
 			Group:       "Settings",
 			Name:        "GroupMember",
-			Desc:        "Group members", //
+			Desc:        "Group members",
 			Shows:       []string{"ID", "User", "Group", "Role", "CreatedAt"},
 			Filterables: []string{"Group", "Role", "CreatedAt"},
 			Editables:   []string{"ID", "User", "Group", "Role"},
@@ -259,7 +281,6 @@ func GetCarrotAdminObjects() []AdminObject {
 			Attributes: map[string]AdminAttribute{
 				"Role": {
 					Default: GroupRoleMember,
-					Choices: []AdminSelectOption{{"Admin", GroupRoleAdmin}, {"Member", GroupRoleMember}},
 				},
 			},
 		},
@@ -267,7 +288,7 @@ func GetCarrotAdminObjects() []AdminObject {
 			Model:       &Config{},
 			Group:       "Settings",
 			Name:        "Config",
-			Desc:        "System config with database backend, You can change it in admin page, and it will take effect immediately without restarting the server", //
+			Desc:        "System config with database backend, You can change it in admin page, and it will take effect immediately without restarting the server",
 			Shows:       []string{"Key", "Value", "Autoload", "Public", "Format", "Desc"},
 			Editables:   []string{"Key", "Value", "Autoload", "Public", "Format", "Desc"},
 			Filterables: []string{"Autoload", "Public"},
@@ -277,6 +298,28 @@ func GetCarrotAdminObjects() []AdminObject {
 			Icon:        &AdminIcon{SVG: string(iconConfig)},
 			AccessCheck: superAccessCheck,
 		},
+		{
+			Model:       &AdminLog{},
+			Group:       "Settings",
+			Name:        "AdminLog",
+			Desc:        "The changes made in the admin, such as create, update, delete and actions",
+			Shows:       []string{"ID", "User", "ObjectType", "ObjectID", "Action", "Fields", "CreatedAt"},
+			Filterables: []string{"User", "ObjectType", "Action", "CreatedAt"},
+			Orderables:  []string{"CreatedAt"},
+			Searchables: []string{"ObjectType", "ObjectID", "Fields"},
+			Orders:      []Order{{"CreatedAt", OrderOpDesc}},
+			Icon:        &AdminIcon{SVG: string(iconHistory)},
+			AccessCheck: superAccessCheck,
+			BeforeCreate: func(db *gorm.DB, c *gin.Context, obj any) error {
+				return ErrAdminLogReadOnly
+			},
+			BeforeUpdate: func(db *gorm.DB, c *gin.Context, obj any, vals map[string]any) error {
+				return ErrAdminLogReadOnly
+			},
+			BeforeDelete: func(db *gorm.DB, c *gin.Context, obj any) error {
+				return ErrAdminLogReadOnly
+			},
+		},
 	}
 }
 
@@ -338,6 +381,22 @@ func BuildAdminObjects(r *gin.RouterGroup, db *gorm.DB, objs []AdminObject) []*A
 		obj.RegisterAdmin(objr)
 		handledObjects = append(handledObjects, obj)
 	}
+
+	objsByPath := make(map[string]*AdminObject)
+	for _, obj := range handledObjects {
+		objsByPath[obj.Path] = obj
+	}
+	for _, obj := range handledObjects {
+		for idx := range obj.Fields {
+			f := &obj.Fields[idx]
+			if f.Foreign == nil {
+				continue
+			}
+			if target, ok := objsByPath[f.Foreign.Path]; ok {
+				f.Foreign.searchables = target.Searchables
+			}
+		}
+	}
 	return handledObjects
 }
 
@@ -397,9 +456,17 @@ func HandleAdminJson(c *gin.Context, objects []*AdminObject, buildContext AdminB
 				continue
 			}
 		}
-		db := getDbConnection(c, obj.GetDB, false)
+		user := CurrentUser(c)
+		var perms []string
+		if !user.IsSuperUser {
+			perms = currentAdminPermissions(c, getDbConnection(c, nil, false), user)
+			// the objects can't be read are hidden
+			if !obj.HasPermission(perms, PermissionRead) {
+				continue
+			}
+		}
 		val := *obj
-		val.BuildPermissions(db, CurrentUser(c))
+		val.buildPermissions(user, perms)
 		viewObjects = append(viewObjects, val)
 	}
 
@@ -415,7 +482,16 @@ func HandleAdminJson(c *gin.Context, objects []*AdminObject, buildContext AdminB
 	})
 }
 
+// BuildPermissions fill obj.Permissions for the UI, resolved from the user's groups with GroupTypeAdmin.
 func (obj *AdminObject) BuildPermissions(db *gorm.DB, user *User) {
+	var perms []string
+	if !user.IsSuperUser {
+		perms, _ = GetAdminPermissions(db, user)
+	}
+	obj.buildPermissions(user, perms)
+}
+
+func (obj *AdminObject) buildPermissions(user *User, perms []string) {
 	obj.Permissions = map[string]bool{}
 	if user.IsSuperUser {
 		obj.Permissions["can_create"] = true
@@ -425,11 +501,16 @@ func (obj *AdminObject) BuildPermissions(db *gorm.DB, user *User) {
 		return
 	}
 
-	//TODO: build permissions with group settings
-	obj.Permissions["can_create"] = true
-	obj.Permissions["can_update"] = true
-	obj.Permissions["can_delete"] = true
-	obj.Permissions["can_action"] = true
+	obj.Permissions["can_create"] = obj.HasPermission(perms, PermissionCreate)
+	obj.Permissions["can_update"] = obj.HasPermission(perms, PermissionUpdate)
+	obj.Permissions["can_delete"] = obj.HasPermission(perms, PermissionDelete)
+	// can_action allows all actions, can_<action> allows the single action
+	obj.Permissions["can_action"] = obj.HasPermission(perms, PermissionAction)
+	for _, action := range obj.Actions {
+		if obj.HasPermission(perms, actionPermission(action.Path)) {
+			obj.Permissions["can_"+action.Path] = true
+		}
+	}
 }
 
 // RegisterAdmin registers admin routes
@@ -438,6 +519,9 @@ func (obj *AdminObject) BuildPermissions(db *gorm.DB, user *User) {
 //   - PUT /admin/{objectslug} -> Create One
 //   - PATCH /admin/{objectslug}} -> Update One
 //   - DELETE /admin/{objectslug} -> Delete One
+//   - POST /admin/{objectslug}/_history -> History of One
+//   - POST /admin/{objectslug}/_export -> Export as csv or xlsx
+//   - POST /admin/{objectslug}/_import -> Import from csv
 //   - POST /admin/{objectslug}/:name -> Action
 func (obj *AdminObject) RegisterAdmin(r gin.IRoutes) {
 	r = r.Use(func(ctx *gin.Context) {
@@ -455,16 +539,43 @@ func (obj *AdminObject) RegisterAdmin(r gin.IRoutes) {
 	r.PUT("/", obj.handleCreate)
 	r.PATCH("/", obj.handleUpdate)
 	r.DELETE("/", obj.handleDelete)
+	r.POST("/_history", obj.handleHistory)
+	r.POST("/_export", obj.handleExport)
+	r.POST("/_import", obj.handleImport)
+	r.POST("/_job", obj.handleJob)
+	r.POST("/_job_cancel", obj.handleCancelJob)
+	r.POST("/_job_download", obj.handleDownloadJob)
 	r.POST("/:name", obj.handleAction)
 }
 
+// asColNames convert the field names to column names, json paths are kept, such as "Profile.country" => "profile.country".
 func (obj *AdminObject) asColNames(db *gorm.DB, fields []string) []string {
 	for i := 0; i < len(fields); i++ {
-		fields[i] = db.NamingStrategy.ColumnName(obj.tableName, fields[i])
+		name, path, _ := strings.Cut(fields[i], ".")
+		fields[i] = db.NamingStrategy.ColumnName(obj.tableName, name)
+		if path != "" {
+			fields[i] += "." + path
+		}
 	}
 	return fields
 }
 
+// jsonPathColumn return the json path expression if name is a whitelisted json path of allows, such as "profile.country".
+func (obj *AdminObject) jsonPathColumn(db *gorm.DB, name string, allows []string) (string, bool) {
+	field, path, err := splitJSONPath(name)
+	if err != nil || len(path) == 0 {
+		return "", false
+	}
+	allowed := map[string]struct{}{}
+	for _, v := range allows {
+		allowed[v] = struct{}{}
+	}
+	if !jsonPathAllowed(allowed, field, path) {
+		return "", false
+	}
+	return jsonPathColumn(db, obj.tableName, field, path), true
+}
+
 // Build fill the properties of obj.
 func (obj *AdminObject) Build(db *gorm.DB) error {
 	if obj.Path == "" {
@@ -483,6 +594,38 @@ func (obj *AdminObject) Build(db *gorm.DB) error {
 	obj.modelElem = rt
 	obj.tableName = db.NamingStrategy.TableName(rt.Name())
 	obj.PluralName = inflection.Plural(obj.Name)
+
+	obj.Shows = mergeTaggedFields(rt, obj.Shows, TagShow)
+	obj.Editables = mergeTaggedFields(rt, obj.Editables, TagEdit)
+	obj.Orderables = mergeTaggedFields(rt, obj.Orderables, TagOrder)
+	obj.Searchables = mergeTaggedFields(rt, obj.Searchables, TagSearch)
+	obj.Filterables = mergeTaggedFields(rt, obj.Filterables, TagFilter)
+	obj.Requireds = mergeTaggedFields(rt, obj.Requireds, TagRequired)
+
+	orderNames := make([]string, 0, len(obj.Orders))
+	for _, o := range obj.Orders {
+		orderNames = append(orderNames, o.Name)
+	}
+	for kind, names := range map[string][]string{
+		TagShow:     obj.Shows,
+		TagEdit:     obj.Editables,
+		TagSearch:   obj.Searchables,
+		TagRequired: obj.Requireds,
+	} {
+		if err := checkFieldNames(rt, kind, names); err != nil {
+			return err
+		}
+	}
+	for kind, names := range map[string][]string{
+		TagOrder:  obj.Orderables,
+		TagFilter: obj.Filterables,
+		"orders":  orderNames,
+	} {
+		if err := checkFieldPaths(rt, kind, names); err != nil {
+			return err
+		}
+	}
+
 	obj.Shows = obj.asColNames(db, obj.Shows)
 	obj.Editables = obj.asColNames(db, obj.Editables)
 	obj.Orderables = obj.asColNames(db, obj.Orderables)
@@ -493,7 +636,7 @@ func (obj *AdminObject) Build(db *gorm.DB) error {
 
 	for idx := range obj.Orders {
 		o := &obj.Orders[idx]
-		o.Name = db.NamingStrategy.ColumnName(obj.tableName, o.Name)
+		o.Name = obj.asColNames(db, []string{o.Name})[0]
 	}
 
 	obj.ignores = map[string]bool{}
@@ -504,9 +647,30 @@ func (obj *AdminObject) Build(db *gorm.DB) error {
 	if len(obj.PrimaryKeys) <= 0 && len(obj.UniqueKeys) <= 0 {
 		return fmt.Errorf("%s not has primaryKey or uniqueKeys", obj.Name)
 	}
+	if err := obj.buildInlines(db); err != nil {
+		return err
+	}
+	if err := obj.buildLockField(db); err != nil {
+		return err
+	}
+
+	if obj.StateMachine != nil {
+		stateCol := obj.StateMachine.ColumnName(db, obj.tableName)
+		for i := 0; i < len(obj.Editables); i++ {
+			if obj.Editables[i] == stateCol {
+				obj.Editables = append(obj.Editables[:i], obj.Editables[i+1:]...)
+				break
+			}
+		}
+		obj.buildTransitionActions()
+	}
 
 	for idx := range obj.Actions {
 		action := &obj.Actions[idx]
+		if err := action.buildForm(); err != nil {
+			return err
+		}
+		action.Async = action.JobHandler != nil
 		if action.Name == "" {
 			continue
 		}
@@ -522,7 +686,9 @@ func (obj *AdminObject) parseFields(db *gorm.DB, rt reflect.Type) error {
 		f := rt.Field(i)
 
 		if f.Anonymous && f.Type.Kind() == reflect.Struct {
-			obj.parseFields(db, f.Type)
+			if err := obj.parseFields(db, f.Type); err != nil {
+				return err
+			}
 			continue
 		}
 
@@ -540,6 +706,9 @@ func (obj *AdminObject) parseFields(db *gorm.DB, rt reflect.Type) error {
 			Label:     f.Tag.Get("label"),
 			NotColumn: gormTag == "-",
 		}
+		if field.Label == "" {
+			field.Label = parseCarrotTag(f.Tag.Get("carrot")).Get(TagLabel)
+		}
 		if field.elemType.Kind() == reflect.Ptr {
 			field.elemType = field.elemType.Elem()
 		}
@@ -630,10 +799,35 @@ func (obj *AdminObject) parseFields(db *gorm.DB, rt reflect.Type) error {
 			}
 		}
 
+		if isMany2ManyField(f) {
+			et := f.Type.Elem()
+			if et.Kind() == reflect.Ptr {
+				et = et.Elem()
+			}
+			rel, err := many2manyRelation(db, obj.Model, f.Name)
+			if err != nil {
+				return err
+			}
+			field.NotColumn = true
+			field.Type = et.Name()
+			field.Foreign = &AdminForeign{
+				Path:       strings.ToLower(et.Name()),
+				Field:      field.Name,
+				Many2Many:  true,
+				fieldName:  f.Name,
+				primaryKey: rel.FieldSchema.PrioritizedPrimaryField.Name,
+				hasMany:    true,
+			}
+		}
+
 		if field.Type == "NullTime" || field.Type == "Time" || field.Type == "DeletedAt" {
 			field.Type = "datetime"
 		}
 
+		if field.elemType == decimalType {
+			field.Type = "decimal"
+		}
+
 		if field.Type == "DeletedAt" || strings.HasPrefix("Null", field.Type) {
 			field.CanNull = true
 		}
@@ -641,6 +835,18 @@ func (obj *AdminObject) parseFields(db *gorm.DB, rt reflect.Type) error {
 		if attr, ok := obj.Attributes[f.Name]; ok {
 			field.Attribute = &attr
 		}
+		enum, err := lookupFieldEnum(f)
+		if err != nil {
+			return err
+		}
+		if enum != nil {
+			if field.Attribute == nil {
+				field.Attribute = &AdminAttribute{}
+			}
+			if len(field.Attribute.Choices) == 0 {
+				field.Attribute.Choices = enum.Choices()
+			}
+		}
 		obj.Fields = append(obj.Fields, field)
 	}
 	return nil
@@ -676,6 +882,17 @@ func convertValue(elemType reflect.Type, source any, targetIsPtr bool) (any, err
 	// 	return source, nil
 	// }
 
+	if elemType == decimalType {
+		if source == "" && targetIsPtr {
+			return nil, nil
+		}
+		d, err := AsDecimal(source)
+		if err != nil {
+			return nil, err
+		}
+		return &d, nil
+	}
+
 	var targetType reflect.Type = elemType
 	var err error
 	switch elemType.Name() {
@@ -778,7 +995,7 @@ func (obj *AdminObject) UnmarshalFrom(elemObj reflect.Value, keys, vals map[stri
 			continue
 		}
 
-		if val == nil {
+		if val == nil || (field.Foreign != nil && field.Foreign.Many2Many) {
 			continue
 		}
 		var target reflect.Value
@@ -840,6 +1057,14 @@ func (obj *AdminObject) MarshalOne(c *gin.Context, val interface{}) (map[string]
 				v.Label = fmt.Sprintf("%v", v.Value)
 			}
 			fieldVal = v
+		} else if field.Foreign != nil && field.Foreign.Many2Many {
+			fieldVal = field.Foreign.marshalMany2Many(rv.FieldByName(field.fieldName))
+		} else if inline := obj.getInline(field.Name); inline != nil {
+			items, err := inline.marshal(c, rv.FieldByName(field.fieldName))
+			if err != nil {
+				return nil, err
+			}
+			fieldVal = items
 		} else {
 			v := rv.FieldByName(field.fieldName)
 			if v.IsValid() {
@@ -858,6 +1083,35 @@ func (obj *AdminObject) MarshalOne(c *gin.Context, val interface{}) (map[string]
 	return result, nil
 }
 
+// checkAccess check current user can access val with permission when OwnerField is set,
+// superusers can access all objects.
+func (obj *AdminObject) checkAccess(c *gin.Context, db *gorm.DB, val any, permission string) error {
+	if obj.OwnerField == "" {
+		return nil
+	}
+	user := CurrentUser(c)
+	if user != nil && user.IsSuperUser {
+		return nil
+	}
+	return checkObjectAccess(db, val, obj.OwnerField, user, permission)
+}
+
+// setOwner fill the owner of the new object with current user when OwnerField is set,
+// only superusers can assign the owner by the form.
+func (obj *AdminObject) setOwner(c *gin.Context, val any) error {
+	if obj.OwnerField == "" {
+		return nil
+	}
+	user := CurrentUser(c)
+	if user == nil {
+		return ErrUnauthorized
+	}
+	if !user.IsSuperUser || reflect.Indirect(reflect.ValueOf(val)).FieldByName(obj.OwnerField).IsZero() {
+		setObjectOwner(val, obj.OwnerField, user)
+	}
+	return nil
+}
+
 func (obj *AdminObject) getPrimaryValues(c *gin.Context) map[string]any {
 	var result = make(map[string]any)
 	hasPrimaryQuery := false
@@ -899,6 +1153,11 @@ func (obj *AdminObject) handleGetOne(c *gin.Context) {
 		return
 	}
 
+	if err := obj.checkAccess(c, db, modelObj, PermissionRead); err != nil {
+		abortWithAccessError(c, err)
+		return
+	}
+
 	if obj.BeforeRender != nil {
 		rr, err := obj.BeforeRender(db, c, modelObj)
 		if err != nil {
@@ -922,6 +1181,27 @@ func (obj *AdminObject) handleGetOne(c *gin.Context) {
 
 func (obj *AdminObject) QueryObjects(session *gorm.DB, form *QueryForm, ctx *gin.Context) (r AdminQueryResult, err error) {
 	for _, v := range form.Filters {
+		if strings.Contains(v.Name, ".") {
+			column, ok := obj.jsonPathColumn(session, v.Name, obj.Filterables)
+			if !ok {
+				return r, fmt.Errorf("invalid filter %s", v.Name)
+			}
+			column = jsonPathFilterColumn(session, column, v.Value)
+			if q := v.queryWith(column); q != "" {
+				if v.Op == FilterOpBetween {
+					vt := reflect.ValueOf(v.Value)
+					if vt.Kind() != reflect.Slice || vt.Len() != 2 {
+						return r, fmt.Errorf("invalid between value, must be slice with 2 elements")
+					}
+					session = session.Where(q, vt.Index(0).Interface(), vt.Index(1).Interface())
+				} else if v.Op == FilterOpLike {
+					session = session.Where(q, fmt.Sprintf("%%%s%%", v.Value))
+				} else {
+					session = session.Where(q, v.Value)
+				}
+			}
+			continue
+		}
 		if q := v.GetQuery(); q != "" {
 			if v.Op == FilterOpLike {
 				if kws, ok := v.Value.([]any); ok {
@@ -955,11 +1235,32 @@ func (obj *AdminObject) QueryObjects(session *gorm.DB, form *QueryForm, ctx *gin
 	}
 
 	for _, v := range orders {
+		if strings.Contains(v.Name, ".") {
+			allows := obj.Orderables
+			if len(form.Orders) == 0 {
+				allows = []string{v.Name} // default orders of obj
+			}
+			column, ok := obj.jsonPathColumn(session, v.Name, allows)
+			if !ok {
+				return r, fmt.Errorf("invalid order %s", v.Name)
+			}
+			session = session.Order(v.queryWith(column))
+			continue
+		}
 		if q := v.GetQuery(); q != "" && v.Op != "" {
 			session = session.Order(fmt.Sprintf("`%s`.%s", obj.tableName, q))
 		}
 	}
 
+	// order by the primary keys last, so the pages (and the batches of the export) are deterministic
+	for _, key := range obj.PrimaryKeys {
+		column := key
+		if v, ok := obj.primaryKeyMaping[key]; ok {
+			column = v
+		}
+		session = session.Order(fmt.Sprintf("`%s`.`%s`", obj.tableName, column))
+	}
+
 	if form.Keyword != "" && len(obj.Searchables) > 0 {
 		var query []string
 		for _, v := range obj.Searchables {
@@ -975,11 +1276,12 @@ func (obj *AdminObject) QueryObjects(session *gorm.DB, form *QueryForm, ctx *gin
 
 	session = session.Model(obj.Model)
 
-	var c int64
-	if err := session.Count(&c).Error; err != nil {
+	c, kind, err := countObjects(session, obj.Model, form.CountMode)
+	if err != nil {
 		return r, err
 	}
-	if c <= 0 {
+	r.TotalKind = kind
+	if c <= 0 && kind == CountModeExact {
 		return r, nil
 	}
 	r.TotalCount = int(c)
@@ -1031,6 +1333,9 @@ func (obj *AdminObject) QueryObjects(session *gorm.DB, form *QueryForm, ctx *gin
 
 // Query many objects with filter/limit/offset/order/search
 func (obj *AdminObject) handleQueryOrGetOne(c *gin.Context) {
+	if !obj.checkPermission(c, PermissionRead) {
+		return
+	}
 	if c.Request.ContentLength <= 0 {
 		obj.handleGetOne(c)
 		return
@@ -1042,8 +1347,16 @@ func (obj *AdminObject) handleQueryOrGetOne(c *gin.Context) {
 		return
 	}
 
-	if form.ForeignMode {
-		form.Limit = 0 // TODO: support foreign mode limit
+	if form.ForeignMode && form.Limit >= DefaultQueryLimit {
+		form.Limit = DefaultForeignLimit
+	}
+
+	if user := CurrentUser(c); obj.OwnerField != "" && (user == nil || !user.IsSuperUser) {
+		db, err = scopeObjectAccess(db, obj.Model, obj.OwnerField, user)
+		if err != nil {
+			abortWithAccessError(c, err)
+			return
+		}
 	}
 	c.Set(KeyAdminQueryForm, form)
 	r, err := obj.QueryObjects(db, form, c)
@@ -1080,12 +1393,25 @@ func (obj *AdminObject) handleQueryOrGetOne(c *gin.Context) {
 }
 
 func (obj *AdminObject) handleCreate(c *gin.Context) {
+	if !obj.checkPermission(c, PermissionCreate) {
+		return
+	}
 	keys := obj.getPrimaryValues(c)
 	var vals map[string]any
 	if err := c.BindJSON(&vals); err != nil {
 		AbortWithJSONError(c, http.StatusBadRequest, err)
 		return
 	}
+	children, err := obj.popInlines(vals)
+	if err != nil {
+		AbortWithJSONError(c, http.StatusBadRequest, err)
+		return
+	}
+	associations, err := obj.popMany2Many(vals)
+	if err != nil {
+		AbortWithJSONError(c, http.StatusBadRequest, err)
+		return
+	}
 	elmObj := reflect.New(obj.modelElem)
 	elm, err := obj.UnmarshalFrom(elmObj, keys, vals)
 	if err != nil {
@@ -1093,6 +1419,11 @@ func (obj *AdminObject) handleCreate(c *gin.Context) {
 		return
 	}
 	db := getDbConnection(c, obj.GetDB, true)
+	if err := obj.setOwner(c, elm); err != nil {
+		abortWithAccessError(c, err)
+		return
+	}
+
 	if obj.BeforeCreate != nil {
 		if err := obj.BeforeCreate(db, c, elm); err != nil {
 			AbortWithJSONError(c, http.StatusBadRequest, err)
@@ -1100,11 +1431,24 @@ func (obj *AdminObject) handleCreate(c *gin.Context) {
 		}
 	}
 
-	result := db.Create(elm)
-	if result.Error != nil {
-		AbortWithJSONError(c, http.StatusInternalServerError, result.Error)
+	var changedInlines []string
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := obj.omitInlines(tx).Create(elm).Error; err != nil {
+			return err
+		}
+		if changedInlines, err = obj.saveInlines(tx, elm, children); err != nil {
+			return err
+		}
+		changed, err := obj.saveMany2Many(tx, elm, associations)
+		changedInlines = append(changedInlines, changed...)
+		return err
+	})
+	if err != nil {
+		abortWithRelationError(c, err)
 		return
 	}
+	fields := obj.changedFields(reflect.New(obj.modelElem).Interface(), elm, vals)
+	obj.writeLog(c, elm, AdminLogCreate, append(fields, changedInlines...)...)
 
 	if obj.BeforeRender != nil {
 		rr, err := obj.BeforeRender(db, c, elm)
@@ -1121,6 +1465,9 @@ func (obj *AdminObject) handleCreate(c *gin.Context) {
 }
 
 func (obj *AdminObject) handleUpdate(c *gin.Context) {
+	if !obj.checkPermission(c, PermissionUpdate) {
+		return
+	}
 	keys := obj.getPrimaryValues(c)
 	if len(keys) <= 0 {
 		AbortWithJSONError(c, http.StatusBadRequest, ErrInvalidPrimaryKey)
@@ -1134,6 +1481,20 @@ func (obj *AdminObject) handleUpdate(c *gin.Context) {
 	}
 
 	db := getDbConnection(c, obj.GetDB, false)
+	if obj.StateMachine != nil {
+		if _, ok := inputVals[obj.StateMachine.ColumnName(db, obj.tableName)]; ok {
+			AbortWithJSONError(c, http.StatusBadRequest, ErrStateNotEditable)
+			return
+		}
+	}
+	if user := CurrentUser(c); obj.OwnerField != "" && (user == nil || !user.IsSuperUser) {
+		// only superusers can assign the owner by the form
+		if _, ok := inputVals[db.NamingStrategy.ColumnName(obj.tableName, obj.OwnerField)]; ok {
+			AbortWithJSONError(c, http.StatusBadRequest, ErrOwnerNotEditable)
+			return
+		}
+	}
+
 	elmObj := reflect.New(obj.modelElem)
 	err := db.Where(keys).First(elmObj.Interface()).Error
 	if err != nil {
@@ -1141,6 +1502,28 @@ func (obj *AdminObject) handleUpdate(c *gin.Context) {
 		return
 	}
 
+	if err := obj.checkAccess(c, db, elmObj.Interface(), PermissionUpdate); err != nil {
+		abortWithAccessError(c, err)
+		return
+	}
+	if err := obj.checkLock(c, elmObj.Interface()); err != nil {
+		AbortWithJSONError(c, http.StatusConflict, err)
+		return
+	}
+
+	oldObj := reflect.New(obj.modelElem)
+	oldObj.Elem().Set(elmObj.Elem())
+
+	children, err := obj.popInlines(inputVals)
+	if err != nil {
+		AbortWithJSONError(c, http.StatusBadRequest, err)
+		return
+	}
+	associations, err := obj.popMany2Many(inputVals)
+	if err != nil {
+		AbortWithJSONError(c, http.StatusBadRequest, err)
+		return
+	}
 	val, err := obj.UnmarshalFrom(elmObj, keys, inputVals)
 	if err != nil {
 		AbortWithJSONError(c, http.StatusBadRequest, err)
@@ -1154,37 +1537,53 @@ func (obj *AdminObject) handleUpdate(c *gin.Context) {
 		}
 	}
 
-	conflictKeys := []clause.Column{}
-	if len(obj.PrimaryKeys) > 0 {
-		for _, k := range obj.PrimaryKeys {
-			conflictKeys = append(conflictKeys, clause.Column{Name: k})
+	fields := obj.changedFields(oldObj.Interface(), val, nil)
+	var changedInlines []string
+	err = db.Transaction(func(tx *gorm.DB) error {
+		err := obj.updateFields(tx, keys, oldObj.Interface(), val, fields)
+		if err != nil {
+			return err
 		}
-	} else {
-		for _, k := range obj.UniqueKeys {
-			conflictKeys = append(conflictKeys, clause.Column{Name: k})
+		if changedInlines, err = obj.saveInlines(tx, val, children); err != nil {
+			return err
 		}
+		changed, err := obj.saveMany2Many(tx, val, associations)
+		changedInlines = append(changedInlines, changed...)
+		return err
+	})
+	if err != nil {
+		abortWithUpdateError(c, err)
+		return
 	}
+	obj.writeLog(c, val, AdminLogUpdate, append(fields, changedInlines...)...)
 
-	for idx := range conflictKeys {
-		k := &conflictKeys[idx]
-		if v, ok := obj.primaryKeyMaping[k.Name]; ok {
-			k.Name = v
+	// render the refreshed object, the values may be changed by the database
+	if err := db.Preload(clause.Associations).Take(val).Error; err != nil {
+		AbortWithJSONError(c, http.StatusInternalServerError, err)
+		return
+	}
+	if obj.BeforeRender != nil {
+		rr, err := obj.BeforeRender(db, c, val)
+		if err != nil {
+			AbortWithJSONError(c, http.StatusInternalServerError, err)
+			return
+		}
+		if rr != nil {
+			val = rr
 		}
 	}
-
-	result := db.Clauses(clause.OnConflict{
-		Columns:   conflictKeys,
-		UpdateAll: true,
-	}).Where(keys).Create(val)
-
-	if result.Error != nil {
-		AbortWithJSONError(c, http.StatusInternalServerError, result.Error)
+	data, err := obj.MarshalOne(c, val)
+	if err != nil {
+		AbortWithJSONError(c, http.StatusInternalServerError, err)
 		return
 	}
-	RenderJSON(c, http.StatusOK, true)
+	RenderJSON(c, http.StatusOK, data)
 }
 
 func (obj *AdminObject) handleDelete(c *gin.Context) {
+	if !obj.checkPermission(c, PermissionDelete) {
+		return
+	}
 	keys := obj.getPrimaryValues(c)
 	if len(keys) <= 0 {
 		AbortWithJSONError(c, http.StatusBadRequest, ErrInvalidPrimaryKey)
@@ -1204,6 +1603,11 @@ func (obj *AdminObject) handleDelete(c *gin.Context) {
 		return
 	}
 
+	if err := obj.checkAccess(c, db, val, PermissionDelete); err != nil {
+		abortWithAccessError(c, err)
+		return
+	}
+
 	if obj.BeforeDelete != nil {
 		if err := obj.BeforeDelete(db, c, val); err != nil {
 			AbortWithJSONError(c, http.StatusBadRequest, err)
@@ -1211,27 +1615,85 @@ func (obj *AdminObject) handleDelete(c *gin.Context) {
 		}
 	}
 
-	r = db.Where(keys).Delete(val)
-	if r.Error != nil {
-		AbortWithJSONError(c, http.StatusInternalServerError, r.Error)
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := obj.deleteInlines(tx, val); err != nil {
+			return err
+		}
+		if obj.OwnerField != "" {
+			if err := deleteObjectPermissions(tx, val); err != nil {
+				return err
+			}
+		}
+		return tx.Where(keys).Delete(val).Error
+	})
+	if err != nil {
+		AbortWithJSONError(c, http.StatusInternalServerError, err)
 		return
 	}
+	obj.writeLog(c, val, AdminLogDelete)
 	RenderJSON(c, http.StatusOK, true)
 }
 
+// abortWithActionError abort with 400 for the invalid transitions, 403 for the access errors, otherwise 500.
+func abortWithActionError(c *gin.Context, err error) {
+	if errors.Is(err, ErrInvalidTransition) {
+		AbortWithJSONError(c, http.StatusBadRequest, err)
+		return
+	}
+	abortWithAccessError(c, err)
+}
+
+// getBatchObjects parse the keys of the batch action and load the objects,
+// the whole batch is rejected if any object can't be accessed by current user.
+func (obj *AdminObject) getBatchObjects(c *gin.Context, db *gorm.DB) ([]map[string]any, []any, bool) {
+	var keys []map[string]any
+	if err := json.Unmarshal([]byte(c.Query("keys")), &keys); err != nil {
+		AbortWithJSONError(c, http.StatusBadRequest, err)
+		return nil, nil, false
+	}
+	var vals []any
+	for _, key := range keys {
+		val := reflect.New(obj.modelElem).Interface()
+		if err := db.Where(key).Take(val).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			AbortWithJSONError(c, http.StatusInternalServerError, err)
+			return nil, nil, false
+		}
+		if err := obj.checkAccess(c, db, val, PermissionUpdate); err != nil {
+			abortWithAccessError(c, err)
+			return nil, nil, false
+		}
+		vals = append(vals, val)
+	}
+	return keys, vals, true
+}
+
 func (obj *AdminObject) handleAction(c *gin.Context) {
 	for _, action := range obj.Actions {
 		if action.Path != c.Param("name") {
 			continue
 		}
+		if !obj.checkPermission(c, PermissionAction, actionPermission(action.Path)) {
+			return
+		}
+		if !bindActionForm(c, &action) {
+			return
+		}
 
 		db := getDbConnection(c, obj.GetDB, false)
+		if action.Async {
+			obj.handleAsyncAction(c, db, &action)
+			return
+		}
 		if action.WithoutObject {
 			handled, r, err := action.Handler(db, c, nil)
 			if err != nil {
-				AbortWithJSONError(c, http.StatusInternalServerError, err)
+				abortWithActionError(c, err)
 				return
 			}
+			obj.writeLog(c, nil, action.Path)
 			if !handled {
 				RenderJSON(c, http.StatusOK, r)
 			}
@@ -1239,16 +1701,19 @@ func (obj *AdminObject) handleAction(c *gin.Context) {
 		}
 
 		if action.Batch {
-			var keys []map[string]any
-			if err := json.Unmarshal([]byte(c.Query("keys")), &keys); err != nil {
-				AbortWithJSONError(c, http.StatusBadRequest, err)
+			// load the objects before the action for the logs, the action may delete them
+			keys, logObjs, ok := obj.getBatchObjects(c, db)
+			if !ok {
 				return
 			}
 			handled, r, err := action.Handler(db, c, keys)
 			if err != nil {
-				AbortWithJSONError(c, http.StatusInternalServerError, err)
+				abortWithActionError(c, err)
 				return
 			}
+			for _, val := range logObjs {
+				obj.writeLog(c, val, action.Path)
+			}
 			if !handled {
 				RenderJSON(c, http.StatusOK, r)
 			}
@@ -1271,11 +1736,18 @@ func (obj *AdminObject) handleAction(c *gin.Context) {
 			}
 			return
 		}
+
+		if err := obj.checkAccess(c, db, modelObj, PermissionUpdate); err != nil {
+			abortWithAccessError(c, err)
+			return
+		}
+
 		handled, r, err := action.Handler(db, c, modelObj)
 		if err != nil {
-			AbortWithJSONError(c, http.StatusInternalServerError, err)
+			abortWithActionError(c, err)
 			return
 		}
+		obj.writeLog(c, modelObj, action.Path)
 
 		if !handled {
 			RenderJSON(c, http.StatusOK, r)
@@ -83,6 +83,8 @@ func authClient(db *gorm.DB, client *TestClient, email, password string, isSuper
 		if isSuper {
 			u.IsStaff = true
 			u.IsSuperUser = true
+			// KEY_USER_ACTIVATED may be cached as true by other tests
+			u.Activated = true
 			db.Save(&u)
 		}
 	}
@@ -173,13 +175,15 @@ func TestAdminCRUD(t *testing.T) {
 		assert.Equal(t, "mock", r.Value)
 	}
 	{
-		var r bool
+		var r Config
 		err := client.CallPatch("/admin/config/?id=1024", gin.H{
 			"key":   "test2",
 			"value": "mock2",
 		}, &r)
 		assert.Nil(t, err)
-		assert.True(t, r)
+		assert.Equal(t, uint(1024), r.ID)
+		assert.Equal(t, "test2", r.Key)
+		assert.Equal(t, "mock2", r.Value)
 	}
 	{
 		var result AdminQueryResult
@@ -385,6 +389,41 @@ func TestAdminForeign(t *testing.T) {
 	assert.Equal(t, uint(1024), vals["item"].(AdminValue).Value)
 }
 
+func TestAdminForeignQuery(t *testing.T) {
+	_, db, client := createAdminTest()
+	for i := 0; i < DefaultForeignLimit+5; i++ {
+		db.Create(&Group{Name: fmt.Sprintf("team-%02d", i)})
+	}
+	db.Create(&Group{Name: "staff"})
+
+	type foreignResult struct {
+		TotalCount int          `json:"total"`
+		Pos        int          `json:"pos"`
+		Limit      int          `json:"limit"`
+		Items      []AdminValue `json:"items"`
+	}
+	var r foreignResult
+	err := client.CallPost("/admin/group/", gin.H{"foreign": true}, &r)
+	assert.Nil(t, err)
+	assert.Equal(t, DefaultForeignLimit, r.Limit)
+	assert.Equal(t, DefaultForeignLimit, len(r.Items))
+	assert.True(t, r.TotalCount > DefaultForeignLimit)
+
+	r = foreignResult{}
+	err = client.CallPost("/admin/group/", gin.H{"foreign": true, "keyword": "team", "pos": 20, "limit": 10}, &r)
+	assert.Nil(t, err)
+	assert.Equal(t, DefaultForeignLimit+5, r.TotalCount)
+	assert.Equal(t, 20, r.Pos)
+	assert.Equal(t, 5, len(r.Items))
+	assert.Contains(t, r.Items[0].Label, "team-20")
+
+	r = foreignResult{}
+	err = client.CallPost("/admin/group/", gin.H{"foreign": true, "keyword": "staff"}, &r)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(r.Items))
+	assert.Contains(t, r.Items[0].Label, "staff")
+}
+
 func TestAdminConvert(t *testing.T) {
 	{
 		var x int64
@@ -439,6 +478,7 @@ func TestAdminUpdatePrimaryKeys(t *testing.T) {
 	{
 		w := httptest.NewRecorder()
 		c := gin.CreateTestContextOnly(w, r)
+		c.Set(UserField, &User{IsSuperUser: true})
 		body := []byte(`{ "name": "test", "joined_at": "2018-09-10T11:02:00Z" }`)
 		c.Request, _ = http.NewRequest(http.MethodPut, "/unittest/?id=100", bytes.NewBuffer(body))
 		c.Request.Header.Add("Content-Type", "application/json")
@@ -449,6 +489,7 @@ func TestAdminUpdatePrimaryKeys(t *testing.T) {
 	{
 		w := httptest.NewRecorder()
 		c := gin.CreateTestContextOnly(w, r)
+		c.Set(UserField, &User{IsSuperUser: true})
 		body := []byte(`{"joined_at": "2000-09-10T11:02:00Z"}`)
 		c.Request, _ = http.NewRequest(http.MethodPatch, "/unittest/?id=100&name=test", bytes.NewBuffer(body))
 		c.Request.Header.Add("Content-Type", "application/json")
@@ -469,6 +510,7 @@ func TestAdminUpdatePrimaryKeys(t *testing.T) {
 	{
 		w := httptest.NewRecorder()
 		c := gin.CreateTestContextOnly(w, r)
+		c.Set(UserField, &User{IsSuperUser: true})
 		body := []byte(`{ "name": "test101",  "id":101 }`)
 		c.Request, _ = http.NewRequest(http.MethodPatch, "/unittest/?id=100&name=test", bytes.NewBuffer(body))
 		c.Request.Header.Add("Content-Type", "application/json")
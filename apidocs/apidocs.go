@@ -2,13 +2,19 @@ package apidocs
 
 import (
 	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
+	"os"
 	"path/filepath"
 	"reflect"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/restsend/carrot"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
 )
 
 //go:embed  apidocs.html
@@ -24,6 +30,7 @@ const (
 	TYPE_BOOLEAN = "boolean"
 	TYPE_OBJECT  = "object"
 	TYPE_MAP     = "map"
+	TYPE_DECIMAL = "decimal" // carrot.Decimal, string encoded such as "12.30"
 )
 
 type DocField struct {
@@ -36,11 +43,14 @@ type DocField struct {
 	CanNull   bool       `json:"canNull,omitempty"`
 	IsArray   bool       `json:"isArray,omitempty"`
 	IsPrimary bool       `json:"isPrimary,omitempty"`
+	Enum      []any      `json:"enum,omitempty"` // Allowed values of the registered carrot.Enum
 	Fields    []DocField `json:"fields,omitempty"`
 }
 
 type WebObjectDoc struct {
+	Version      string     `json:"version,omitempty"`
 	Group        string     `json:"group"`
+	Name         string     `json:"name"`
 	Path         string     `json:"path"`
 	Desc         string     `json:"desc,omitempty"`
 	AuthRequired bool       `json:"authRequired,omitempty"`
@@ -50,11 +60,15 @@ type WebObjectDoc struct {
 	Orders       []string   `json:"orders,omitempty"`
 	Searches     []string   `json:"searches,omitempty"`
 	Editables    []string   `json:"editables,omitempty"`
+	PrimaryKeys  []string   `json:"primaryKeys,omitempty"`  // Path params of the object, such as ["id"]
+	Associations []string   `json:"associations,omitempty"` // Many2many fields can be added and removed, such as ["tags"]
+	Transitions  []string   `json:"transitions,omitempty"`  // State transitions, such as ["publish"]
 	Views        []UriDoc   `json:"views,omitempty"`
 }
 
 type UriDoc struct {
 	MethodRef    any       `json:"-"` // just for quick jump to method
+	Version      string    `json:"version,omitempty"`
 	Group        string    `json:"group"`
 	Path         string    `json:"path"`
 	Desc         string    `json:"desc,omitempty"`
@@ -64,6 +78,41 @@ type UriDoc struct {
 	Response     *DocField `json:"response"`
 }
 
+// Docs is the document served by RegisterHandler.
+type Docs struct {
+	Uris []UriDoc       `json:"uris"`
+	Objs []WebObjectDoc `json:"objs"`
+}
+
+// LoadDocs read the document served by RegisterHandler from a file or an url.
+func LoadDocs(source string) (*Docs, error) {
+	var data []byte
+	var err error
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		var resp *http.Response
+		resp, err = http.Post(source, "application/json", nil)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("load docs from %s fail: %s", source, resp.Status)
+		}
+		data, err = io.ReadAll(resp.Body)
+	} else {
+		data, err = os.ReadFile(source)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var docs Docs
+	if err := json.Unmarshal(data, &docs); err != nil {
+		return nil, err
+	}
+	return &docs, nil
+}
+
 func RegisterHandler(router *gin.RouterGroup, uriDocs []UriDoc, objDocs []WebObjectDoc) {
 	router.POST("/", func(ctx *gin.Context) {
 		docs := map[string]any{
@@ -77,6 +126,16 @@ func RegisterHandler(router *gin.RouterGroup, uriDocs []UriDoc, objDocs []WebObj
 	router.GET("/", func(ctx *gin.Context) {
 		ctx.Data(http.StatusOK, "text/html; charset=utf-8", []byte(apiDocHTML))
 	})
+
+	router.GET("/openapi.json", func(ctx *gin.Context) {
+		title := "API"
+		if db, ok := ctx.Get(carrot.DbField); ok {
+			if name := carrot.GetValue(db.(*gorm.DB), carrot.KEY_SITE_NAME); name != "" {
+				title = name
+			}
+		}
+		carrot.RenderJSON(ctx, http.StatusOK, GetOpenAPIDocument(title, "1.0.0", uriDocs, objDocs))
+	})
 }
 
 func GetDocDefine(obj any) *DocField {
@@ -89,14 +148,20 @@ func GetDocDefine(obj any) *DocField {
 }
 
 func GetWebObjectDocDefine(prefix string, obj carrot.WebObject) WebObjectDoc {
+	// fill the name, primary keys and the fields from carrot tag
+	if err := obj.Build(); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"prefix": prefix,
+			"name":   obj.Name,
+		}).WithError(err).Warn("apidocs: build webobject fail")
+	}
+
 	doc := WebObjectDoc{
 		Group:        obj.Group,
+		Name:         obj.Name,
 		Path:         filepath.Join(prefix, obj.Name),
 		Desc:         obj.Desc,
 		AuthRequired: obj.AuthRequired,
-		Filters:      obj.Filterables,
-		Orders:       obj.Orderables,
-		Searches:     obj.Searchables,
 	}
 	allowMethods := obj.AllowMethods
 	if obj.AllowMethods == 0 {
@@ -119,6 +184,12 @@ func GetWebObjectDocDefine(prefix string, obj carrot.WebObject) WebObjectDoc {
 		doc.AllowMethods = append(doc.AllowMethods, "QUERY")
 	}
 
+	for _, v := range strings.Split(obj.BuildPrimaryPath(""), "/") {
+		if v != "" {
+			doc.PrimaryKeys = append(doc.PrimaryKeys, strings.TrimPrefix(v, ":"))
+		}
+	}
+
 	doc.Fields = GetDocDefine(obj.Model).Fields
 	allFields := []string{}
 	for _, f := range doc.Fields {
@@ -128,12 +199,15 @@ func GetWebObjectDocDefine(prefix string, obj carrot.WebObject) WebObjectDoc {
 	if len(obj.Editables) == 0 {
 		doc.Editables = allFields
 	} else {
-		for _, ef := range obj.Editables {
-			for _, f := range doc.Fields {
-				if ef == f.FieldName {
-					doc.Editables = append(doc.Editables, f.Name)
-				}
-			}
+		doc.Editables = asJSONNames(doc.Fields, obj.Editables)
+	}
+	doc.Filters = asJSONNames(doc.Fields, obj.Filterables)
+	doc.Orders = asJSONNames(doc.Fields, obj.Orderables)
+	doc.Searches = asJSONNames(doc.Fields, obj.Searchables)
+	doc.Associations = asJSONNames(doc.Fields, obj.Associations)
+	if obj.StateMachine != nil {
+		for _, t := range obj.StateMachine.Transitions {
+			doc.Transitions = append(doc.Transitions, t.Name)
 		}
 	}
 
@@ -148,6 +222,26 @@ func GetWebObjectDocDefine(prefix string, obj carrot.WebObject) WebObjectDoc {
 	return doc
 }
 
+// asJSONNames convert struct field names to json names of fields.
+// json paths are kept, such as "Profile.country" => "profile.country".
+func asJSONNames(fields []DocField, names []string) []string {
+	var r []string
+	for _, name := range names {
+		name, path, hasPath := strings.Cut(name, ".")
+		for _, f := range fields {
+			if name != f.FieldName {
+				continue
+			}
+			if hasPath {
+				r = append(r, f.Name+"."+path)
+			} else {
+				r = append(r, f.Name)
+			}
+		}
+	}
+	return r
+}
+
 // parseDocField convert StructField Type to DocFiled.
 func parseDocField(rt reflect.Type, name string, stacks []string) (val DocField) {
 	val.Name = name
@@ -174,7 +268,7 @@ func parseDocField(rt reflect.Type, name string, stacks []string) (val DocField)
 		return val
 	}
 
-	if rt.Kind() != reflect.Struct {
+	if rt.Kind() != reflect.Struct || rt == decimalType {
 		return val
 	}
 
@@ -230,11 +324,19 @@ func parseDocField(rt reflect.Type, name string, stacks []string) (val DocField)
 			fieldRT.IsPrimary = true
 		}
 
+		if enum := carrot.GetFieldEnum(f); enum != nil {
+			for _, v := range enum.Values {
+				fieldRT.Enum = append(fieldRT.Enum, v.Value)
+			}
+		}
+
 		val.Fields = append(val.Fields, fieldRT)
 	}
 	return val
 }
 
+var decimalType = reflect.TypeOf(carrot.Decimal{})
+
 // parseType return type string according to reflect.Type.
 func parseType(rt reflect.Type) string {
 	if rt.Kind() == reflect.Ptr {
@@ -251,8 +353,15 @@ func parseType(rt reflect.Type) string {
 		return TYPE_DATE
 	}
 
+	if rt == decimalType {
+		return TYPE_DECIMAL
+	}
+
 	switch rt.Kind() {
 	case reflect.Array, reflect.Slice:
+		if rt.Elem() == decimalType {
+			return TYPE_DECIMAL
+		}
 		val := rt.Elem().Kind().String()
 		if val == "struct" || val == "ptr" {
 			val = TYPE_OBJECT
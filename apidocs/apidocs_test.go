@@ -1,12 +1,14 @@
 package apidocs
 
 import (
+	"bytes"
 	"reflect"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/restsend/carrot"
+	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"gorm.io/gorm"
 )
@@ -97,3 +99,130 @@ func TestBuildRpcDefine(t *testing.T) {
 	//assert.Equal(t, len(define.Defines), 5)
 	//assert.Equal(t, define.Name, "DemoObject")
 }
+
+func TestTypedWebObjectDocDefine(t *testing.T) {
+	type demoObject struct {
+		UUID string `json:"id" gorm:"primarykey;size:20"`
+		Name string `json:"name"`
+	}
+	o := carrot.WebObjectOf[demoObject]{
+		Name:      "demo",
+		Editables: []string{"Name"},
+	}
+	doc := GetWebObjectDocDefine("/api", o.AsWebObject())
+	assert.Equal(t, "/api/demo", doc.Path)
+	assert.Equal(t, 2, len(doc.Fields))
+	assert.Equal(t, []string{"name"}, doc.Editables)
+}
+
+func TestAssociationsDocDefine(t *testing.T) {
+	type demoTag struct {
+		ID   uint   `json:"id" gorm:"primarykey"`
+		Name string `json:"name"`
+	}
+	type demoArticle struct {
+		ID   uint      `json:"id" gorm:"primarykey"`
+		Tags []demoTag `json:"tags" gorm:"many2many:demo_article_tags"`
+	}
+	o := carrot.WebObjectOf[demoArticle]{
+		Name:         "article",
+		Associations: []string{"Tags"},
+	}
+	doc := GetWebObjectDocDefine("/api", o.AsWebObject())
+	assert.Equal(t, []string{"tags"}, doc.Associations)
+}
+
+func TestEnumDocField(t *testing.T) {
+	type memberForm struct {
+		Name string `json:"name"`
+		Role string `json:"role" carrot:"enum=group_role"`
+	}
+	doc := GetDocDefine(memberForm{})
+	role := doc.Fields[1]
+	assert.Equal(t, []any{carrot.GroupRoleAdmin, carrot.GroupRoleMember}, role.Enum)
+	assert.Equal(t, []any{carrot.GroupRoleAdmin, carrot.GroupRoleMember}, DocFieldToSchema(&role).Enum)
+	assert.Equal(t, `"admin" | "member"`, tsType(&role, ""))
+	assert.Equal(t, carrot.GroupRoleAdmin, GenerateExample(&role))
+
+	errs := ValidateDocField(doc, map[string]any{"name": "bob", "role": "owner"})
+	assert.Equal(t, 1, len(errs))
+	assert.Equal(t, "role", errs[0].Path)
+	assert.Nil(t, ValidateDocField(doc, map[string]any{"name": "bob", "role": "member"}))
+
+	newDoc := GetDocDefine(memberForm{})
+	newDoc.Fields[1].Enum = []any{carrot.GroupRoleMember, "owner"}
+	diff := DiffDocs(&Docs{Uris: []UriDoc{{Path: "/member", Method: "POST", Request: doc}}},
+		&Docs{Uris: []UriDoc{{Path: "/member", Method: "POST", Request: newDoc}}})
+	assert.Equal(t, 2, len(diff.Changes))
+	assert.Equal(t, "[breaking] POST /member request.role: enum value admin removed", diff.Changes[0].String())
+	assert.Equal(t, "[additive] POST /member request.role: enum value owner added", diff.Changes[1].String())
+}
+
+func TestDecimalDocField(t *testing.T) {
+	type orderForm struct {
+		Amount   carrot.Decimal   `json:"amount"`
+		Discount *carrot.Decimal  `json:"discount"`
+		Prices   []carrot.Decimal `json:"prices"`
+	}
+	doc := GetDocDefine(orderForm{})
+	assert.Equal(t, TYPE_DECIMAL, doc.Fields[0].Type)
+	assert.Nil(t, doc.Fields[0].Fields)
+	assert.Equal(t, TYPE_DECIMAL, doc.Fields[1].Type)
+	assert.True(t, doc.Fields[1].CanNull)
+	assert.True(t, doc.Fields[2].IsArray)
+
+	schema := DocFieldToSchema(&doc.Fields[0])
+	assert.Equal(t, "string", schema.Type)
+	assert.Equal(t, "decimal", schema.Format)
+	assert.Equal(t, "string", tsType(&doc.Fields[0], ""))
+	assert.Equal(t, "0.00", GenerateExample(&doc.Fields[0]))
+
+	assert.Nil(t, ValidateDocField(doc, map[string]any{"amount": "12.30", "discount": nil, "prices": []any{"1", 2.5}}))
+	errs := ValidateDocField(doc, map[string]any{"amount": "12,30"})
+	assert.Equal(t, 1, len(errs))
+	assert.Equal(t, "amount", errs[0].Path)
+}
+
+func TestJSONPathDocNames(t *testing.T) {
+	type customer struct {
+		ID      uint           `json:"id" gorm:"primarykey"`
+		Profile carrot.Profile `json:"profile"`
+	}
+	doc := GetWebObjectDocDefine("/api", carrot.WebObject{
+		Model:       customer{},
+		Filterables: []string{"ID", "Profile.country"},
+		Orderables:  []string{"Profile.*"},
+	})
+	assert.Equal(t, []string{"id", "profile.country"}, doc.Filters)
+	assert.Equal(t, []string{"profile.*"}, doc.Orders)
+}
+
+func TestWebObjectDocBuildError(t *testing.T) {
+	type customer struct {
+		ID   uint   `json:"id" gorm:"primarykey"`
+		Name string `json:"name"`
+	}
+	var buf bytes.Buffer
+	out := logrus.StandardLogger().Out
+	logrus.SetOutput(&buf)
+	defer logrus.SetOutput(out)
+
+	GetWebObjectDocDefine("/api", carrot.WebObject{
+		Model:       customer{},
+		Name:        "customer",
+		Filterables: []string{"Mock"},
+	})
+	assert.Contains(t, buf.String(), "apidocs: build webobject fail")
+	assert.Contains(t, buf.String(), "Mock")
+}
+
+func TestRegisteredWebObjectDocDefine(t *testing.T) {
+	type customer struct {
+		ID   uint   `json:"id" gorm:"primarykey"`
+		Name string `json:"name"`
+	}
+	obj := carrot.WebObject{Model: customer{}}
+	assert.Nil(t, obj.Build())
+	doc := GetWebObjectDocDefine("/api", obj)
+	assert.Equal(t, []string{"id"}, doc.PrimaryKeys)
+}
@@ -0,0 +1,43 @@
+// Command docdiff compare two snapshots of the document served by apidocs.RegisterHandler,
+// and exit with 1 if there are breaking changes.
+//
+//	go run github.com/restsend/carrot/apidocs/cmd/docdiff -old docs-v1.json -new http://localhost:8080/api/docs/
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/restsend/carrot/apidocs"
+)
+
+func main() {
+	var oldInput, newInput string
+	flag.StringVar(&oldInput, "old", "", "Old docs json file or url of apidocs handler")
+	flag.StringVar(&newInput, "new", "", "New docs json file or url of apidocs handler")
+	flag.Parse()
+
+	if oldInput == "" || newInput == "" {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	oldDocs, err := apidocs.LoadDocs(oldInput)
+	if err != nil {
+		log.Fatalf("load docs %s fail %v", oldInput, err)
+	}
+	newDocs, err := apidocs.LoadDocs(newInput)
+	if err != nil {
+		log.Fatalf("load docs %s fail %v", newInput, err)
+	}
+
+	diff := apidocs.DiffDocs(oldDocs, newDocs)
+	for _, c := range diff.Changes {
+		fmt.Println(c)
+	}
+	if diff.HasBreaking() {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,48 @@
+// Command docexport export the document served by apidocs.RegisterHandler as static HTML, Markdown,
+// Postman collection or .http files.
+//
+//	go run github.com/restsend/carrot/apidocs/cmd/docexport -i http://localhost:8080/api/docs/ -f markdown -o docs
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"github.com/restsend/carrot/apidocs"
+)
+
+func main() {
+	var input, output, format, title string
+	flag.StringVar(&input, "i", "", "Docs json file or url of apidocs handler")
+	flag.StringVar(&output, "o", "docs", "Output directory")
+	flag.StringVar(&format, "f", "html", "Output format, html, markdown, postman or http")
+	flag.StringVar(&title, "t", "API", "Title of the docs")
+	flag.Parse()
+
+	if input == "" {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	docs, err := apidocs.LoadDocs(input)
+	if err != nil {
+		log.Fatalf("load docs fail %v", err)
+	}
+
+	switch format {
+	case "html":
+		err = apidocs.ExportHTML(output, title, docs.Uris, docs.Objs)
+	case "markdown", "md":
+		err = apidocs.ExportMarkdown(output, title, docs.Uris, docs.Objs)
+	case "postman":
+		err = apidocs.ExportPostman(output, title, docs.Uris, docs.Objs)
+	case "http":
+		err = apidocs.ExportHTTPFiles(output, docs.Uris, docs.Objs)
+	default:
+		log.Fatalf("invalid format %s", format)
+	}
+	if err != nil {
+		log.Fatalf("export fail %v", err)
+	}
+}
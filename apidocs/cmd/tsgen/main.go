@@ -0,0 +1,38 @@
+// Command tsgen generate a TypeScript client from the document served by apidocs.RegisterHandler.
+//
+//	go run github.com/restsend/carrot/apidocs/cmd/tsgen -i http://localhost:8080/api/docs/ -o api.ts
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"github.com/restsend/carrot/apidocs"
+)
+
+func main() {
+	var input, output string
+	flag.StringVar(&input, "i", "", "Docs json file or url of apidocs handler")
+	flag.StringVar(&output, "o", "", "Output file, default is stdout")
+	flag.Parse()
+
+	if input == "" {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	docs, err := apidocs.LoadDocs(input)
+	if err != nil {
+		log.Fatalf("load docs fail %v", err)
+	}
+
+	code := apidocs.GenerateTypeScript(docs.Uris, docs.Objs)
+	if output == "" {
+		os.Stdout.WriteString(code)
+		return
+	}
+	if err := os.WriteFile(output, []byte(code), 0644); err != nil {
+		log.Fatalf("write %s fail %v", output, err)
+	}
+}
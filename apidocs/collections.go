@@ -0,0 +1,312 @@
+package apidocs
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const PostmanSchema = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+
+const (
+	CollectionHostVar  = "host"
+	CollectionTokenVar = "token"
+	DefaultHost        = "http://localhost:8080"
+)
+
+type PostmanCollection struct {
+	Info     PostmanInfo       `json:"info"`
+	Item     []PostmanItem     `json:"item"`
+	Auth     *PostmanAuth      `json:"auth,omitempty"`
+	Variable []PostmanVariable `json:"variable,omitempty"`
+}
+
+type PostmanInfo struct {
+	Name   string `json:"name"`
+	Schema string `json:"schema"`
+}
+
+type PostmanItem struct {
+	Name    string          `json:"name"`
+	Item    []PostmanItem   `json:"item,omitempty"`    // Folder
+	Request *PostmanRequest `json:"request,omitempty"` // Request
+}
+
+type PostmanRequest struct {
+	Method      string          `json:"method"`
+	Description string          `json:"description,omitempty"`
+	Header      []PostmanHeader `json:"header,omitempty"`
+	Body        *PostmanBody    `json:"body,omitempty"`
+	URL         PostmanURL      `json:"url"`
+	Auth        *PostmanAuth    `json:"auth,omitempty"`
+}
+
+type PostmanHeader struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type PostmanBody struct {
+	Mode    string         `json:"mode"`
+	Raw     string         `json:"raw"`
+	Options map[string]any `json:"options,omitempty"`
+}
+
+type PostmanURL struct {
+	Raw      string            `json:"raw"`
+	Host     []string          `json:"host"`
+	Path     []string          `json:"path"`
+	Query    []PostmanVariable `json:"query,omitempty"`
+	Variable []PostmanVariable `json:"variable,omitempty"`
+}
+
+type PostmanVariable struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type PostmanAuth struct {
+	Type   string            `json:"type"`
+	Bearer []PostmanVariable `json:"bearer,omitempty"`
+}
+
+// sampleRequest is an example request of UriDoc or WebObjectDoc route.
+type sampleRequest struct {
+	Group        string
+	Name         string
+	Desc         string
+	Method       string
+	Path         string // gin style path, such as "/api/user/:id"
+	AuthRequired bool
+	Query        map[string]any
+	Body         any
+}
+
+// sampleRequests return the example requests of all routes, grouped as GroupDocs.
+func sampleRequests(uriDocs []UriDoc, objDocs []WebObjectDoc) []sampleRequest {
+	var reqs []sampleRequest
+	for _, g := range GroupDocs(uriDocs, objDocs) {
+		for _, uri := range g.Uris {
+			req := sampleRequest{
+				Group:        g.Name,
+				Name:         uri.Desc,
+				Desc:         uri.Desc,
+				Method:       strings.ToUpper(uri.Method),
+				Path:         uri.Path,
+				AuthRequired: uri.AuthRequired,
+			}
+			if req.Method == "" {
+				req.Method = http.MethodPost
+			}
+			if req.Name == "" {
+				req.Name = req.Method + " " + req.Path
+			}
+			if uri.Request != nil {
+				body := GenerateExample(uri.Request)
+				if query, ok := body.(map[string]any); ok && req.Method == http.MethodGet {
+					req.Query = query
+				} else {
+					req.Body = body
+				}
+			}
+			reqs = append(reqs, req)
+		}
+
+		for _, obj := range g.Objs {
+			newReq := func(name, method, path string, body any) sampleRequest {
+				return sampleRequest{Group: g.Name, Name: name, Method: method, Path: path, AuthRequired: obj.AuthRequired, Body: body}
+			}
+			example := GenerateExample(objectField(obj)).(map[string]any)
+			for _, method := range obj.AllowMethods {
+				switch method {
+				case "GET":
+					reqs = append(reqs, newReq("Get "+obj.Name, http.MethodGet, primaryPath(obj), nil))
+				case "CREATE":
+					reqs = append(reqs, newReq("Create "+obj.Name, http.MethodPut, obj.Path, example))
+				case "EDIT":
+					vals := map[string]any{}
+					for _, k := range obj.Editables {
+						vals[k] = example[k]
+					}
+					reqs = append(reqs, newReq("Update "+obj.Name, http.MethodPatch, primaryPath(obj), vals))
+				case "DELETE":
+					reqs = append(reqs, newReq("Delete "+obj.Name, http.MethodDelete, primaryPath(obj), nil))
+				case "QUERY":
+					reqs = append(reqs, newReq("Query "+obj.Name, http.MethodPost, obj.Path, json.RawMessage(queryFormExample(obj))))
+				}
+			}
+			for _, v := range obj.Views {
+				method := v.Method
+				if method == "" {
+					method = http.MethodPost
+				}
+				name := v.Desc
+				if name == "" {
+					name = "Query " + obj.Name + " " + strings.TrimPrefix(v.Path, obj.Path+"/")
+				}
+				reqs = append(reqs, newReq(name, method, v.Path, json.RawMessage(queryFormExample(obj))))
+			}
+		}
+	}
+	return reqs
+}
+
+func pathParams(path string) []string {
+	var params []string
+	for _, seg := range strings.Split(path, "/") {
+		if strings.HasPrefix(seg, ":") || strings.HasPrefix(seg, "*") {
+			params = append(params, seg[1:])
+		}
+	}
+	return params
+}
+
+func sortedKeys(vals map[string]any) []string {
+	keys := make([]string, 0, len(vals))
+	for k := range vals {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sampleBody(body any) string {
+	if body == nil {
+		return ""
+	}
+	data, _ := json.MarshalIndent(body, "", "  ")
+	return string(data)
+}
+
+// GetPostmanCollection convert uriDocs and objDocs to a Postman v2.1 collection,
+// with {{host}} and {{token}} variables, one folder per group.
+func GetPostmanCollection(name string, uriDocs []UriDoc, objDocs []WebObjectDoc) *PostmanCollection {
+	collection := &PostmanCollection{
+		Info: PostmanInfo{Name: name, Schema: PostmanSchema},
+		Variable: []PostmanVariable{
+			{Key: CollectionHostVar, Value: DefaultHost},
+			{Key: CollectionTokenVar, Value: ""},
+		},
+	}
+
+	for _, req := range sampleRequests(uriDocs, objDocs) {
+		var folder *PostmanItem
+		for i := range collection.Item {
+			if collection.Item[i].Name == req.Group {
+				folder = &collection.Item[i]
+			}
+		}
+		if folder == nil {
+			collection.Item = append(collection.Item, PostmanItem{Name: req.Group})
+			folder = &collection.Item[len(collection.Item)-1]
+		}
+
+		host := "{{" + CollectionHostVar + "}}"
+		r := &PostmanRequest{
+			Method:      req.Method,
+			Description: req.Desc,
+			URL: PostmanURL{
+				Raw:  host + req.Path,
+				Host: []string{host},
+				Path: strings.Split(strings.TrimPrefix(req.Path, "/"), "/"),
+			},
+			Auth: &PostmanAuth{Type: "noauth"},
+		}
+		for _, p := range pathParams(req.Path) {
+			r.URL.Variable = append(r.URL.Variable, PostmanVariable{Key: p, Value: ""})
+		}
+		if len(req.Query) > 0 {
+			var qs []string
+			for _, k := range sortedKeys(req.Query) {
+				v := fmt.Sprintf("%v", req.Query[k])
+				r.URL.Query = append(r.URL.Query, PostmanVariable{Key: k, Value: v})
+				qs = append(qs, k+"="+v)
+			}
+			r.URL.Raw += "?" + strings.Join(qs, "&")
+		}
+		if req.AuthRequired {
+			r.Auth = &PostmanAuth{Type: "bearer", Bearer: []PostmanVariable{{Key: "token", Value: "{{" + CollectionTokenVar + "}}"}}}
+		}
+		if req.Body != nil {
+			r.Header = append(r.Header, PostmanHeader{Key: "Content-Type", Value: "application/json"})
+			r.Body = &PostmanBody{Mode: "raw", Raw: sampleBody(req.Body), Options: map[string]any{"raw": map[string]string{"language": "json"}}}
+		}
+		folder.Item = append(folder.Item, PostmanItem{Name: req.Name, Request: r})
+	}
+	return collection
+}
+
+// GenerateHTTPFile generate the JetBrains .http file of uriDocs and objDocs,
+// with @host, @token and the path params as variables.
+func GenerateHTTPFile(uriDocs []UriDoc, objDocs []WebObjectDoc) string {
+	reqs := sampleRequests(uriDocs, objDocs)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "@%s = %s\n@%s =\n", CollectionHostVar, DefaultHost, CollectionTokenVar)
+	params := map[string]bool{}
+	for _, req := range reqs {
+		for _, p := range pathParams(req.Path) {
+			if !params[p] {
+				params[p] = true
+				fmt.Fprintf(&sb, "@%s = 1\n", p)
+			}
+		}
+	}
+
+	for _, req := range reqs {
+		path := req.Path
+		for _, p := range pathParams(req.Path) {
+			path = strings.Replace(path, ":"+p, "{{"+p+"}}", 1)
+			path = strings.Replace(path, "*"+p, "{{"+p+"}}", 1)
+		}
+		if len(req.Query) > 0 {
+			var qs []string
+			for _, k := range sortedKeys(req.Query) {
+				qs = append(qs, fmt.Sprintf("%s=%v", k, req.Query[k]))
+			}
+			path += "?" + strings.Join(qs, "&")
+		}
+
+		fmt.Fprintf(&sb, "\n### %s\n", req.Name)
+		fmt.Fprintf(&sb, "%s {{%s}}%s\n", req.Method, CollectionHostVar, path)
+		if req.AuthRequired {
+			fmt.Fprintf(&sb, "Authorization: Bearer {{%s}}\n", CollectionTokenVar)
+		}
+		if req.Body != nil {
+			fmt.Fprintf(&sb, "Content-Type: application/json\n\n%s\n", sampleBody(req.Body))
+		}
+	}
+	return sb.String()
+}
+
+// ExportPostman write the Postman collection into dir as "{name}.postman_collection.json".
+func ExportPostman(dir, name string, uriDocs []UriDoc, objDocs []WebObjectDoc) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(GetPostmanCollection(name, uriDocs, objDocs), "", "  ")
+	if err != nil {
+		return err
+	}
+	fname := groupFileName(name) + ".postman_collection.json"
+	return os.WriteFile(filepath.Join(dir, fname), data, 0644)
+}
+
+// ExportHTTPFiles write one .http file per group into dir, such as "auth.http".
+func ExportHTTPFiles(dir string, uriDocs []UriDoc, objDocs []WebObjectDoc) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	groups := GroupDocs(uriDocs, objDocs)
+	files := groupFileNames(groups, ".http")
+	for _, g := range groups {
+		if err := os.WriteFile(filepath.Join(dir, files[g.Name]), []byte(GenerateHTTPFile(g.Uris, g.Objs)), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,262 @@
+package apidocs
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	ChangeBreaking = "breaking"
+	ChangeAdditive = "additive"
+)
+
+// DocChange is a difference between two snapshots of docs, such as:
+//
+//	{Kind: "breaking", Endpoint: "POST /api/auth/login", Field: "request.email", Message: "field is required"}
+type DocChange struct {
+	Kind     string `json:"kind"` // breaking or additive
+	Endpoint string `json:"endpoint"`
+	Field    string `json:"field,omitempty"`
+	Message  string `json:"message"`
+}
+
+func (c DocChange) String() string {
+	if c.Field == "" {
+		return fmt.Sprintf("[%s] %s: %s", c.Kind, c.Endpoint, c.Message)
+	}
+	return fmt.Sprintf("[%s] %s %s: %s", c.Kind, c.Endpoint, c.Field, c.Message)
+}
+
+type DocDiff struct {
+	Changes []DocChange `json:"changes"`
+}
+
+// Breaking return the breaking changes.
+func (d *DocDiff) Breaking() []DocChange {
+	var r []DocChange
+	for _, c := range d.Changes {
+		if c.Kind == ChangeBreaking {
+			r = append(r, c)
+		}
+	}
+	return r
+}
+
+func (d *DocDiff) HasBreaking() bool {
+	return len(d.Breaking()) > 0
+}
+
+func (d *DocDiff) add(kind, endpoint, field, format string, args ...any) {
+	d.Changes = append(d.Changes, DocChange{Kind: kind, Endpoint: endpoint, Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// DiffDocs compare two snapshots of the docs served by RegisterHandler.
+//
+// Breaking changes are removed endpoints, methods and fields, type changes,
+// newly required request fields, removed enum values and newly required auth.
+// New endpoints, optional fields and enum values are additive.
+func DiffDocs(oldDocs, newDocs *Docs) *DocDiff {
+	d := &DocDiff{}
+
+	uriKey := func(v UriDoc) string { return strings.ToUpper(v.Method) + " " + v.Path }
+	newUris := map[string]UriDoc{}
+	for _, v := range newDocs.Uris {
+		newUris[uriKey(v)] = v
+	}
+	oldUris := map[string]bool{}
+	for _, o := range oldDocs.Uris {
+		key := uriKey(o)
+		oldUris[key] = true
+		n, ok := newUris[key]
+		if !ok {
+			d.add(ChangeBreaking, key, "", "endpoint removed")
+			continue
+		}
+		if !o.AuthRequired && n.AuthRequired {
+			d.add(ChangeBreaking, key, "", "authorization required")
+		}
+		d.diffField(key, "request", o.Request, n.Request, true)
+		d.diffField(key, "response", o.Response, n.Response, false)
+	}
+	for _, n := range newDocs.Uris {
+		if key := uriKey(n); !oldUris[key] {
+			d.add(ChangeAdditive, key, "", "endpoint added")
+		}
+	}
+
+	newObjs := map[string]WebObjectDoc{}
+	for _, v := range newDocs.Objs {
+		newObjs[v.Path] = v
+	}
+	oldObjs := map[string]bool{}
+	for _, o := range oldDocs.Objs {
+		oldObjs[o.Path] = true
+		n, ok := newObjs[o.Path]
+		if !ok {
+			d.add(ChangeBreaking, o.Path, "", "object removed")
+			continue
+		}
+		d.diffWebObject(o, n)
+	}
+	for _, n := range newDocs.Objs {
+		if !oldObjs[n.Path] {
+			d.add(ChangeAdditive, n.Path, "", "object added")
+		}
+	}
+	return d
+}
+
+func (d *DocDiff) diffWebObject(o, n WebObjectDoc) {
+	endpoint := o.Path
+	if !o.AuthRequired && n.AuthRequired {
+		d.add(ChangeBreaking, endpoint, "", "authorization required")
+	}
+	if strings.Join(o.PrimaryKeys, "/") != strings.Join(n.PrimaryKeys, "/") {
+		d.add(ChangeBreaking, endpoint, "", "primary keys changed from %v to %v", o.PrimaryKeys, n.PrimaryKeys)
+	}
+
+	d.diffNames(endpoint, "", "method", o.AllowMethods, n.AllowMethods)
+	d.diffNames(endpoint, "", "editable", o.Editables, n.Editables)
+	d.diffNames(endpoint, "", "filter", o.Filters, n.Filters)
+	d.diffNames(endpoint, "", "order", o.Orders, n.Orders)
+	d.diffNames(endpoint, "", "search", o.Searches, n.Searches)
+
+	var oldViews, newViews []string
+	for _, v := range o.Views {
+		oldViews = append(oldViews, v.Path)
+	}
+	for _, v := range n.Views {
+		newViews = append(newViews, v.Path)
+	}
+	d.diffNames(endpoint, "", "view", oldViews, newViews)
+
+	// object fields are both request and response
+	d.diffFields(endpoint, "", o.Fields, n.Fields, false)
+}
+
+// diffNames compare the name lists, removed names are breaking.
+func (d *DocDiff) diffNames(endpoint, field, kind string, oldNames, newNames []string) {
+	contains := func(names []string, name string) bool {
+		for _, v := range names {
+			if v == name {
+				return true
+			}
+		}
+		return false
+	}
+	for _, v := range oldNames {
+		if !contains(newNames, v) {
+			d.add(ChangeBreaking, endpoint, field, "%s %s removed", kind, v)
+		}
+	}
+	for _, v := range newNames {
+		if !contains(oldNames, v) {
+			d.add(ChangeAdditive, endpoint, field, "%s %s added", kind, v)
+		}
+	}
+}
+
+func (d *DocDiff) diffField(endpoint, name string, o, n *DocField, isRequest bool) {
+	switch {
+	case o == nil && n == nil:
+		return
+	case o == nil:
+		if isRequest {
+			d.add(ChangeBreaking, endpoint, name, "%s body added", name)
+		} else {
+			d.add(ChangeAdditive, endpoint, name, "%s body added", name)
+		}
+		return
+	case n == nil:
+		d.add(ChangeBreaking, endpoint, name, "%s body removed", name)
+		return
+	}
+	if o.Type != n.Type || o.IsArray != n.IsArray {
+		d.add(ChangeBreaking, endpoint, name, "type changed from %s to %s", docFieldType(o), docFieldType(n))
+		return
+	}
+	d.diffFields(endpoint, name, o.Fields, n.Fields, isRequest)
+}
+
+func (d *DocDiff) diffFields(endpoint, prefix string, oldFields, newFields []DocField, isRequest bool) {
+	fieldName := func(name string) string {
+		if prefix == "" {
+			return name
+		}
+		return prefix + "." + name
+	}
+
+	newByName := map[string]*DocField{}
+	for i := range newFields {
+		newByName[newFields[i].Name] = &newFields[i]
+	}
+	oldByName := map[string]bool{}
+	for i := range oldFields {
+		o := &oldFields[i]
+		oldByName[o.Name] = true
+		name := fieldName(o.Name)
+		n, ok := newByName[o.Name]
+		if !ok {
+			d.add(ChangeBreaking, endpoint, name, "field removed")
+			continue
+		}
+		if o.Type != n.Type || o.IsArray != n.IsArray {
+			d.add(ChangeBreaking, endpoint, name, "type changed from %s to %s", docFieldType(o), docFieldType(n))
+			continue
+		}
+		d.diffEnum(endpoint, name, o.Enum, n.Enum)
+		if isRequest && !o.Required && n.Required {
+			d.add(ChangeBreaking, endpoint, name, "field is required")
+		}
+		if !isRequest && !o.CanNull && n.CanNull {
+			d.add(ChangeBreaking, endpoint, name, "field can be null")
+		}
+		d.diffFields(endpoint, name, o.Fields, n.Fields, isRequest)
+	}
+
+	for i := range newFields {
+		n := &newFields[i]
+		if oldByName[n.Name] {
+			continue
+		}
+		if isRequest && n.Required {
+			d.add(ChangeBreaking, endpoint, fieldName(n.Name), "required field added")
+		} else {
+			d.add(ChangeAdditive, endpoint, fieldName(n.Name), "field added")
+		}
+	}
+}
+
+// diffEnum compare the enum values, removed values are breaking.
+func (d *DocDiff) diffEnum(endpoint, name string, oldEnum, newEnum []any) {
+	asNames := func(values []any) []string {
+		var r []string
+		for _, v := range values {
+			r = append(r, fmt.Sprint(v))
+		}
+		return r
+	}
+	if len(oldEnum) == 0 && len(newEnum) > 0 {
+		d.add(ChangeBreaking, endpoint, name, "enum values %v added", newEnum)
+		return
+	}
+	if len(newEnum) == 0 {
+		return
+	}
+	d.diffNames(endpoint, name, "enum value", asNames(oldEnum), asNames(newEnum))
+}
+
+func docFieldType(f *DocField) string {
+	if f.IsArray {
+		return f.Type + "[]"
+	}
+	return f.Type
+}
+
+// CheckNoBreakingChanges fail t for each breaking change between oldDocs and newDocs.
+func CheckNoBreakingChanges(t TestingT, oldDocs, newDocs *Docs) {
+	markHelper(t)
+	for _, c := range DiffDocs(oldDocs, newDocs).Breaking() {
+		t.Errorf("breaking change %s", c)
+	}
+}
@@ -0,0 +1,79 @@
+package apidocs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffDocs(t *testing.T) {
+	oldDocs := &Docs{
+		Uris: []UriDoc{
+			{Path: "/api/login", Method: "POST", Request: &DocField{Type: TYPE_OBJECT, Fields: []DocField{
+				{Name: "email", Type: TYPE_STRING, Required: true},
+				{Name: "password", Type: TYPE_STRING},
+			}}, Response: &DocField{Type: TYPE_OBJECT, Fields: []DocField{
+				{Name: "id", Type: TYPE_INT},
+				{Name: "name", Type: TYPE_STRING},
+			}}},
+			{Path: "/api/logout", Method: "GET"},
+		},
+		Objs: []WebObjectDoc{
+			{Path: "/api/product", PrimaryKeys: []string{"id"}, AllowMethods: []string{"GET", "QUERY"},
+				Filters: []string{"name"}, Fields: []DocField{{Name: "id", Type: TYPE_INT}, {Name: "name", Type: TYPE_STRING}}},
+		},
+	}
+
+	diff := DiffDocs(oldDocs, oldDocs)
+	assert.Equal(t, 0, len(diff.Changes))
+	assert.False(t, diff.HasBreaking())
+
+	newDocs := &Docs{
+		Uris: []UriDoc{
+			{Path: "/api/login", Method: "POST", Request: &DocField{Type: TYPE_OBJECT, Fields: []DocField{
+				{Name: "email", Type: TYPE_STRING, Required: true},
+				{Name: "password", Type: TYPE_STRING, Required: true},
+				{Name: "remember", Type: TYPE_BOOLEAN},
+			}}, Response: &DocField{Type: TYPE_OBJECT, Fields: []DocField{
+				{Name: "id", Type: TYPE_STRING},
+			}}},
+			{Path: "/api/profile", Method: "GET"},
+		},
+		Objs: []WebObjectDoc{
+			{Path: "/api/product", PrimaryKeys: []string{"id"}, AllowMethods: []string{"GET", "QUERY", "EDIT"},
+				Fields: []DocField{{Name: "id", Type: TYPE_INT}, {Name: "name", Type: TYPE_STRING}, {Name: "price", Type: TYPE_INT}}},
+		},
+	}
+
+	diff = DiffDocs(oldDocs, newDocs)
+	assert.True(t, diff.HasBreaking())
+
+	var breaking, additive []string
+	for _, c := range diff.Changes {
+		if c.Kind == ChangeBreaking {
+			breaking = append(breaking, c.String())
+		} else {
+			additive = append(additive, c.String())
+		}
+	}
+	assert.ElementsMatch(t, []string{
+		"[breaking] POST /api/login request.password: field is required",
+		"[breaking] POST /api/login response.id: type changed from int to string",
+		"[breaking] POST /api/login response.name: field removed",
+		"[breaking] GET /api/logout: endpoint removed",
+		"[breaking] /api/product: filter name removed",
+	}, breaking)
+	assert.ElementsMatch(t, []string{
+		"[additive] POST /api/login request.remember: field added",
+		"[additive] GET /api/profile: endpoint added",
+		"[additive] /api/product: method EDIT added",
+		"[additive] /api/product price: field added",
+	}, additive)
+
+	// additive changes only
+	tb := &fakeTB{}
+	CheckNoBreakingChanges(tb, oldDocs, &Docs{Uris: append(oldDocs.Uris, UriDoc{Path: "/api/profile", Method: "GET"}), Objs: oldDocs.Objs})
+	assert.Empty(t, tb.errors)
+	CheckNoBreakingChanges(tb, oldDocs, newDocs)
+	assert.Equal(t, len(breaking), len(tb.errors))
+}
@@ -0,0 +1,389 @@
+package apidocs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	htmltemplate "html/template"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+const DefaultGroupName = "Default"
+
+// DocGroup is the docs of the same Group, used by the exporters.
+type DocGroup struct {
+	Name string
+	Uris []UriDoc
+	Objs []WebObjectDoc
+}
+
+// DocFieldRow is a flatten field of DocField, nested fields are named as "items[].name".
+type DocFieldRow struct {
+	Name     string
+	Type     string
+	Required bool
+	CanNull  bool
+	Desc     string
+}
+
+// GroupDocs group uriDocs and objDocs by Group, in the order of first appearance.
+func GroupDocs(uriDocs []UriDoc, objDocs []WebObjectDoc) []DocGroup {
+	var groups []DocGroup
+	indexOf := func(name string) int {
+		if name == "" {
+			name = DefaultGroupName
+		}
+		for i := range groups {
+			if groups[i].Name == name {
+				return i
+			}
+		}
+		groups = append(groups, DocGroup{Name: name})
+		return len(groups) - 1
+	}
+	for _, v := range uriDocs {
+		idx := indexOf(v.Group)
+		groups[idx].Uris = append(groups[idx].Uris, v)
+	}
+	for _, v := range objDocs {
+		idx := indexOf(v.Group)
+		groups[idx].Objs = append(groups[idx].Objs, v)
+	}
+	return groups
+}
+
+// GenerateExample return an example value of f according to the field types.
+func GenerateExample(f *DocField) any {
+	if f == nil {
+		return nil
+	}
+	elem := *f
+	if elem.IsArray {
+		elem.IsArray = false
+		return []any{GenerateExample(&elem)}
+	}
+	if f.Default != nil {
+		return f.Default
+	}
+	if len(f.Enum) > 0 {
+		return f.Enum[0]
+	}
+
+	switch f.Type {
+	case TYPE_DATE:
+		return "2006-01-02T15:04:05Z"
+	case TYPE_STRING:
+		if f.Name != "" {
+			return f.Name
+		}
+		return "string"
+	case TYPE_DECIMAL:
+		return "0.00"
+	case TYPE_BOOLEAN, "bool":
+		return true
+	case TYPE_INT, "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "uintptr":
+		return 0
+	case TYPE_FLOAT, "float32", "float64":
+		return 0.0
+	case TYPE_MAP:
+		return map[string]any{}
+	case TYPE_OBJECT:
+		obj := map[string]any{}
+		for i := range f.Fields {
+			obj[f.Fields[i].Name] = GenerateExample(&f.Fields[i])
+		}
+		return obj
+	}
+	return nil
+}
+
+// FlattenDocFields flatten the nested fields for rendering as a table.
+func FlattenDocFields(fields []DocField) []DocFieldRow {
+	var rows []DocFieldRow
+	flattenDocFields(fields, "", &rows)
+	return rows
+}
+
+func flattenDocFields(fields []DocField, prefix string, rows *[]DocFieldRow) {
+	for _, f := range fields {
+		t := f.Type
+		if t == "" {
+			t = "any"
+		}
+		if f.IsArray {
+			t += "[]"
+		}
+		name := prefix + f.Name
+		*rows = append(*rows, DocFieldRow{Name: name, Type: t, Required: f.Required, CanNull: f.CanNull, Desc: f.Desc})
+		if len(f.Fields) > 0 {
+			if f.IsArray {
+				name += "[]"
+			}
+			flattenDocFields(f.Fields, name+".", rows)
+		}
+	}
+}
+
+func exampleJSON(f *DocField) string {
+	data, _ := json.MarshalIndent(GenerateExample(f), "", "  ")
+	return string(data)
+}
+
+func queryFormExample(obj WebObjectDoc) string {
+	form := map[string]any{"pos": 0, "limit": 20}
+	if len(obj.Filters) > 0 {
+		form["filters"] = []map[string]any{{"name": obj.Filters[0], "op": "=", "value": "value"}}
+	}
+	if len(obj.Orders) > 0 {
+		form["orders"] = []map[string]any{{"name": obj.Orders[0], "op": "desc"}}
+	}
+	if len(obj.Searches) > 0 {
+		form["keyword"] = "keyword"
+	}
+	data, _ := json.MarshalIndent(form, "", "  ")
+	return string(data)
+}
+
+func primaryPath(obj WebObjectDoc) string {
+	p := obj.Path
+	for _, key := range obj.PrimaryKeys {
+		p += "/:" + key
+	}
+	return p
+}
+
+// groupFileName return the file name of the group without the extension, the names without letters
+// or digits, such as "用户" and "-", use the hash of the name.
+func groupFileName(name string) string {
+	if fileName := strings.ToLower(schemaName(name)); fileName != "" {
+		return fileName
+	}
+	return "group-" + nameHash(name)
+}
+
+// groupFileNames return the file names of the groups with ext, such as "shop.md".
+// The names mapped to the same file, such as "Shop" and "shop", are suffixed with the hash of the name.
+func groupFileNames(groups []DocGroup, ext string) map[string]string {
+	files := map[string]string{}
+	used := map[string]bool{}
+	for _, g := range groups {
+		fileName := groupFileName(g.Name) + ext
+		if used[fileName] {
+			fileName = groupFileName(g.Name) + "-" + nameHash(g.Name) + ext
+		}
+		used[fileName] = true
+		files[g.Name] = fileName
+	}
+	return files
+}
+
+func nameHash(name string) string {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	return fmt.Sprintf("%08x", h.Sum32())
+}
+
+// markdownCell escape the text in the markdown table cell.
+func markdownCell(text string) string {
+	return strings.NewReplacer("|", `\|`, "\r\n", " ", "\n", " ").Replace(text)
+}
+
+var exportFuncs = map[string]any{
+	"example":     exampleJSON,
+	"queryForm":   queryFormExample,
+	"flatten":     FlattenDocFields,
+	"primaryPath": primaryPath,
+	"cell":        markdownCell,
+	"join":        strings.Join,
+	"upper":       strings.ToUpper,
+	"objectField": objectField,
+}
+
+const markdownIndexTemplate = `# {{.Title}}
+{{range .Groups}}
+- [{{.Name}}]({{fileName .Name}})
+{{- end}}
+`
+
+const markdownGroupTemplate = `{{define "fields"}}
+| Field | Type | Required | Description |
+| --- | --- | --- | --- |
+{{- range flatten .}}
+| {{cell .Name}} | {{cell .Type}}{{if .CanNull}} (nullable){{end}} | {{if .Required}}yes{{end}} | {{cell .Desc}} |
+{{- end}}
+{{end}}# {{.Name}}
+{{range .Uris}}
+## {{upper .Method}} {{.Path}}
+{{if .Desc}}
+{{.Desc}}
+{{end}}{{if .AuthRequired}}
+Authorization required.
+{{end}}{{if .Request}}
+### Request
+{{if .Request.Fields}}{{template "fields" .Request.Fields}}{{end}}
+` + "```json" + `
+{{example .Request}}
+` + "```" + `
+{{end}}{{if .Response}}
+### Response
+{{if .Response.Fields}}{{template "fields" .Response.Fields}}{{end}}
+` + "```json" + `
+{{example .Response}}
+` + "```" + `
+{{end}}{{end}}{{range .Objs}}
+## {{.Name}}
+{{if .Desc}}
+{{.Desc}}
+{{end}}{{if .AuthRequired}}
+Authorization required.
+{{end}}
+| Method | Path | Description |
+| --- | --- | --- |
+{{- $obj := .}}{{range .AllowMethods}}
+{{- if eq . "GET"}}
+| GET | {{primaryPath $obj}} | Get one object |
+{{- else if eq . "CREATE"}}
+| PUT | {{$obj.Path}} | Create object |
+{{- else if eq . "EDIT"}}
+| PATCH | {{primaryPath $obj}} | Update editable fields |
+{{- else if eq . "DELETE"}}
+| DELETE | {{primaryPath $obj}} | Delete object |
+{{- else if eq . "QUERY"}}
+| POST | {{$obj.Path}} | Query objects |
+{{- end}}{{end}}
+{{- range .Views}}
+| {{if .Method}}{{.Method}}{{else}}POST{{end}} | {{cell .Path}} | {{cell .Desc}} |
+{{- end}}
+
+### Fields
+{{template "fields" .Fields}}
+{{if .Editables}}- Editables: {{join .Editables ", "}}
+{{end}}{{if .Filters}}- Filters: {{join .Filters ", "}}
+{{end}}{{if .Orders}}- Orders: {{join .Orders ", "}}
+{{end}}{{if .Searches}}- Searches: {{join .Searches ", "}}
+{{end}}
+### Example
+` + "```json" + `
+{{example (objectField .)}}
+` + "```" + `
+
+### Query example
+` + "```json" + `
+{{queryForm .}}
+` + "```" + `
+{{end}}`
+
+const htmlTemplate = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body{font-family:-apple-system,Helvetica,Arial,sans-serif;margin:0;display:flex;color:#1f2937}
+nav{width:240px;padding:16px;background:#f3f4f6;height:100vh;position:sticky;top:0;overflow:auto}
+nav a{display:block;color:#374151;text-decoration:none;padding:2px 0;font-size:14px}
+main{flex:1;padding:24px;max-width:960px}
+table{border-collapse:collapse;width:100%;margin:8px 0;font-size:14px}
+th,td{border:1px solid #e5e7eb;padding:4px 8px;text-align:left}
+pre{background:#f9fafb;border:1px solid #e5e7eb;padding:8px;overflow:auto}
+.method{font-weight:bold;color:#2563eb}
+.auth{color:#b45309;font-size:13px}
+</style>
+</head>
+<body>
+{{define "fields"}}<table><tr><th>Field</th><th>Type</th><th>Required</th><th>Description</th></tr>
+{{- range flatten .}}<tr><td>{{.Name}}</td><td>{{.Type}}{{if .CanNull}} (nullable){{end}}</td><td>{{if .Required}}yes{{end}}</td><td>{{.Desc}}</td></tr>{{end}}</table>{{end}}
+<nav><h3>{{.Title}}</h3>
+{{- range .Groups}}<a href="#{{.Name}}">{{.Name}}</a>{{end}}
+</nav>
+<main>
+{{- range .Groups}}
+<section id="{{.Name}}"><h1>{{.Name}}</h1>
+{{- range .Uris}}
+<h2><span class="method">{{upper .Method}}</span> {{.Path}}</h2>
+{{if .Desc}}<p>{{.Desc}}</p>{{end}}{{if .AuthRequired}}<p class="auth">Authorization required</p>{{end}}
+{{if .Request}}<h3>Request</h3>{{if .Request.Fields}}{{template "fields" .Request.Fields}}{{end}}<pre>{{example .Request}}</pre>{{end}}
+{{if .Response}}<h3>Response</h3>{{if .Response.Fields}}{{template "fields" .Response.Fields}}{{end}}<pre>{{example .Response}}</pre>{{end}}
+{{- end}}
+{{- range .Objs}}
+<h2>{{.Name}}</h2>
+{{if .Desc}}<p>{{.Desc}}</p>{{end}}{{if .AuthRequired}}<p class="auth">Authorization required</p>{{end}}
+<table><tr><th>Method</th><th>Path</th><th>Description</th></tr>
+{{- $obj := .}}{{range .AllowMethods}}
+{{- if eq . "GET"}}<tr><td>GET</td><td>{{primaryPath $obj}}</td><td>Get one object</td></tr>
+{{- else if eq . "CREATE"}}<tr><td>PUT</td><td>{{$obj.Path}}</td><td>Create object</td></tr>
+{{- else if eq . "EDIT"}}<tr><td>PATCH</td><td>{{primaryPath $obj}}</td><td>Update editable fields</td></tr>
+{{- else if eq . "DELETE"}}<tr><td>DELETE</td><td>{{primaryPath $obj}}</td><td>Delete object</td></tr>
+{{- else if eq . "QUERY"}}<tr><td>POST</td><td>{{$obj.Path}}</td><td>Query objects</td></tr>
+{{- end}}{{end}}
+{{- range .Views}}<tr><td>{{if .Method}}{{.Method}}{{else}}POST{{end}}</td><td>{{.Path}}</td><td>{{.Desc}}</td></tr>{{end}}
+</table>
+<h3>Fields</h3>{{template "fields" .Fields}}
+<ul>
+{{if .Editables}}<li>Editables: {{join .Editables ", "}}</li>{{end}}
+{{if .Filters}}<li>Filters: {{join .Filters ", "}}</li>{{end}}
+{{if .Orders}}<li>Orders: {{join .Orders ", "}}</li>{{end}}
+{{if .Searches}}<li>Searches: {{join .Searches ", "}}</li>{{end}}
+</ul>
+<h3>Example</h3><pre>{{example (objectField .)}}</pre>
+<h3>Query example</h3><pre>{{queryForm .}}</pre>
+{{- end}}
+</section>
+{{- end}}
+</main>
+</body>
+</html>
+`
+
+func objectField(obj WebObjectDoc) *DocField {
+	return &DocField{Type: TYPE_OBJECT, Fields: obj.Fields}
+}
+
+// ExportMarkdown write the docs as Markdown files into dir,
+// README.md is the index and each group is written to "{group}.md".
+func ExportMarkdown(dir, title string, uriDocs []UriDoc, objDocs []WebObjectDoc) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	groups := GroupDocs(uriDocs, objDocs)
+	files := groupFileNames(groups, ".md")
+	fileName := func(name string) string { return files[name] }
+
+	index := template.Must(template.New("index").Funcs(exportFuncs).Funcs(template.FuncMap{"fileName": fileName}).Parse(markdownIndexTemplate))
+	var buf bytes.Buffer
+	if err := index.Execute(&buf, map[string]any{"Title": title, "Groups": groups}); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), buf.Bytes(), 0644); err != nil {
+		return err
+	}
+
+	tmpl := template.Must(template.New("group").Funcs(exportFuncs).Parse(markdownGroupTemplate))
+	for _, g := range groups {
+		buf.Reset()
+		if err := tmpl.Execute(&buf, g); err != nil {
+			return fmt.Errorf("render group %s fail: %v", g.Name, err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, files[g.Name]), buf.Bytes(), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExportHTML write the docs as a self-contained index.html into dir.
+func ExportHTML(dir, title string, uriDocs []UriDoc, objDocs []WebObjectDoc) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	tmpl := htmltemplate.Must(htmltemplate.New("html").Funcs(exportFuncs).Parse(htmlTemplate))
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, map[string]any{"Title": title, "Groups": GroupDocs(uriDocs, objDocs)}); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, "index.html"), buf.Bytes(), 0644)
+}
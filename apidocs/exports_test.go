@@ -0,0 +1,130 @@
+package apidocs
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/restsend/carrot"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateExample(t *testing.T) {
+	type item struct {
+		Name  string  `json:"name"`
+		Price float64 `json:"price"`
+	}
+	type form struct {
+		Email   string         `json:"email"`
+		Age     int            `json:"age"`
+		Enabled bool           `json:"enabled"`
+		Items   []item         `json:"items"`
+		Extra   map[string]any `json:"extra"`
+	}
+	v := GenerateExample(GetDocDefine(form{}))
+	assert.Equal(t, map[string]any{
+		"email":   "email",
+		"age":     0,
+		"enabled": true,
+		"items":   []any{map[string]any{"name": "name", "price": 0.0}},
+		"extra":   map[string]any{},
+	}, v)
+
+	rows := FlattenDocFields(GetDocDefine(form{}).Fields)
+	assert.Equal(t, "items[].name", rows[4].Name)
+	assert.Equal(t, "object[]", rows[3].Type)
+}
+
+func TestExportDocs(t *testing.T) {
+	type product struct {
+		ID   uint   `json:"id" gorm:"primarykey"`
+		Name string `json:"name" comment:"product name"`
+	}
+	type loginForm struct {
+		Email string `json:"email" binding:"required"`
+	}
+	objDocs := []WebObjectDoc{GetWebObjectDocDefine("/api", carrot.WebObject{
+		Model:       product{},
+		Group:       "Shop",
+		Filterables: []string{"Name"},
+	})}
+	uriDocs := []UriDoc{
+		{Group: "Auth", Path: "/api/auth/login", Method: http.MethodPost, Desc: "Login with email", Request: GetDocDefine(loginForm{})},
+		{Path: "/api/ping", Method: http.MethodGet},
+	}
+
+	groups := GroupDocs(uriDocs, objDocs)
+	assert.Equal(t, 3, len(groups))
+	assert.Equal(t, "Auth", groups[0].Name)
+	assert.Equal(t, DefaultGroupName, groups[1].Name)
+
+	dir := t.TempDir()
+	err := ExportMarkdown(dir, "Demo API", uriDocs, objDocs)
+	assert.Nil(t, err)
+	index, _ := os.ReadFile(filepath.Join(dir, "README.md"))
+	assert.Contains(t, string(index), "- [Shop](shop.md)")
+	auth, _ := os.ReadFile(filepath.Join(dir, "auth.md"))
+	assert.Contains(t, string(auth), "## POST /api/auth/login")
+	assert.Contains(t, string(auth), "| email | string | yes |  |")
+	assert.Contains(t, string(auth), `"email": "email"`)
+	shop, _ := os.ReadFile(filepath.Join(dir, "shop.md"))
+	assert.Contains(t, string(shop), "| GET | /api/product/:id | Get one object |")
+	assert.Contains(t, string(shop), "| name | string |  | product name |")
+	assert.Contains(t, string(shop), "- Filters: name")
+
+	err = ExportHTML(dir, "Demo API", uriDocs, objDocs)
+	assert.Nil(t, err)
+	html, _ := os.ReadFile(filepath.Join(dir, "index.html"))
+	assert.Contains(t, string(html), "<title>Demo API</title>")
+	assert.Contains(t, string(html), `<section id="Shop">`)
+	assert.Contains(t, string(html), "<td>PATCH</td><td>/api/product/:id</td>")
+}
+
+func TestGroupFileName(t *testing.T) {
+	assert.Equal(t, "shop", groupFileName("Shop"))
+	assert.NotEqual(t, "", groupFileName("用户"))
+	assert.NotEqual(t, groupFileName("用户"), groupFileName("订单"))
+	assert.Equal(t, groupFileName("用户"), groupFileName("用户"))
+
+	// the names mapped to the same file are suffixed with the hash
+	files := groupFileNames([]DocGroup{{Name: "Shop"}, {Name: "shop"}, {Name: "User Admin"}, {Name: "user-admin"}}, ".md")
+	assert.Equal(t, "shop.md", files["Shop"])
+	assert.Equal(t, "useradmin.md", files["User Admin"])
+	assert.NotEqual(t, files["Shop"], files["shop"])
+	assert.NotEqual(t, files["User Admin"], files["user-admin"])
+
+	uriDocs := []UriDoc{
+		{Group: "用户", Path: "/api/user", Method: http.MethodGet},
+		{Group: "订单", Path: "/api/order", Method: http.MethodGet},
+		{Group: "Shop", Path: "/api/shop", Method: http.MethodGet, Desc: "a | b"},
+		{Group: "shop", Path: "/api/shop2", Method: http.MethodGet},
+	}
+	dir := t.TempDir()
+	err := ExportMarkdown(dir, "Demo API", uriDocs, nil)
+	assert.Nil(t, err)
+	files = groupFileNames(GroupDocs(uriDocs, nil), ".md")
+	user, _ := os.ReadFile(filepath.Join(dir, files["用户"]))
+	assert.Contains(t, string(user), "/api/user")
+	order, _ := os.ReadFile(filepath.Join(dir, files["订单"]))
+	assert.Contains(t, string(order), "/api/order")
+	shop, _ := os.ReadFile(filepath.Join(dir, files["Shop"]))
+	assert.Contains(t, string(shop), "/api/shop\n")
+	shop2, _ := os.ReadFile(filepath.Join(dir, files["shop"]))
+	assert.Contains(t, string(shop2), "/api/shop2")
+	index, _ := os.ReadFile(filepath.Join(dir, "README.md"))
+	assert.Contains(t, string(index), "[shop]("+files["shop"]+")")
+}
+
+func TestMarkdownCell(t *testing.T) {
+	assert.Equal(t, `a \| b c`, markdownCell("a | b\nc"))
+
+	type item struct {
+		Kind string `json:"kind" comment:"\"a\" | \"b\""`
+	}
+	dir := t.TempDir()
+	err := ExportMarkdown(dir, "Demo API", []UriDoc{{Group: "Shop", Path: "/api/item", Method: http.MethodPost, Request: GetDocDefine(item{})}}, nil)
+	assert.Nil(t, err)
+	data, _ := os.ReadFile(filepath.Join(dir, "shop.md"))
+	assert.Contains(t, string(data), `| kind | string |  | "a" \| "b" |`)
+}
@@ -0,0 +1,433 @@
+package apidocs
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/restsend/carrot"
+)
+
+const OpenAPIVersion = "3.1.0"
+
+const (
+	openAPISchemaRef   = "#/components/schemas/"
+	openAPIResponseRef = "#/components/responses/"
+)
+
+type OpenAPI struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       OpenAPIInfo          `json:"info"`
+	Tags       []OpenAPITag         `json:"tags,omitempty"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components OpenAPIComponents    `json:"components"`
+}
+
+type OpenAPIInfo struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type OpenAPITag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+type OpenAPIComponents struct {
+	Schemas         map[string]*Schema         `json:"schemas,omitempty"`
+	Responses       map[string]*Response       `json:"responses,omitempty"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type   string `json:"type"`             // "http" or "apiKey"
+	Scheme string `json:"scheme,omitempty"` // "bearer"
+	In     string `json:"in,omitempty"`     // "cookie", "header" or "query"
+	Name   string `json:"name,omitempty"`
+}
+
+type PathItem struct {
+	Get    *Operation `json:"get,omitempty"`
+	Put    *Operation `json:"put,omitempty"`
+	Post   *Operation `json:"post,omitempty"`
+	Delete *Operation `json:"delete,omitempty"`
+	Patch  *Operation `json:"patch,omitempty"`
+}
+
+type Operation struct {
+	OperationID string                `json:"operationId,omitempty"`
+	Summary     string                `json:"summary,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"` // "path" or "query"
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema,omitempty"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required,omitempty"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Ref         string               `json:"$ref,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+// Schema is the JSON Schema of OpenAPI 3.1, Type is a string or a list such as ["string", "null"].
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 any                `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Default              any                `json:"default,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties any                `json:"additionalProperties,omitempty"`
+}
+
+// GetOpenAPIDocument convert uriDocs and objDocs to an OpenAPI 3.1 document.
+func GetOpenAPIDocument(title, version string, uriDocs []UriDoc, objDocs []WebObjectDoc) *OpenAPI {
+	doc := &OpenAPI{
+		OpenAPI: OpenAPIVersion,
+		Info:    OpenAPIInfo{Title: title, Version: version},
+		Paths:   map[string]*PathItem{},
+		Components: OpenAPIComponents{
+			Schemas: map[string]*Schema{
+				"QueryForm": DocFieldToSchema(GetDocDefine(carrot.QueryForm{})),
+				"Error": {
+					Type:       "object",
+					Properties: map[string]*Schema{"error": {Type: "string"}},
+					Required:   []string{"error"},
+				},
+			},
+			Responses: map[string]*Response{
+				"Error": {
+					Description: "Error",
+					Content:     jsonContent(&Schema{Ref: openAPISchemaRef + "Error"}),
+				},
+			},
+			SecuritySchemes: map[string]*SecurityScheme{
+				"bearerAuth": {Type: "http", Scheme: "bearer"},
+				"cookieAuth": {Type: "apiKey", In: "cookie", Name: carrot.GetCarrotSessionField()},
+			},
+		},
+	}
+
+	tags := map[string]bool{}
+	addTag := func(name string) {
+		if name != "" && !tags[name] {
+			tags[name] = true
+			doc.Tags = append(doc.Tags, OpenAPITag{Name: name})
+		}
+	}
+
+	for _, uri := range uriDocs {
+		addTag(uri.Group)
+		doc.addOperation(uri.Method, uri.Path, uriDocOperation(uri))
+	}
+
+	for _, obj := range objDocs {
+		addTag(obj.Group)
+		doc.addWebObject(obj)
+	}
+	return doc
+}
+
+func (doc *OpenAPI) addOperation(method, path string, op *Operation) {
+	path = openAPIPath(path)
+	item, ok := doc.Paths[path]
+	if !ok {
+		item = &PathItem{}
+		doc.Paths[path] = item
+	}
+	switch strings.ToUpper(method) {
+	case http.MethodGet:
+		item.Get = op
+	case http.MethodPut:
+		item.Put = op
+	case http.MethodDelete:
+		item.Delete = op
+	case http.MethodPatch:
+		item.Patch = op
+	default:
+		item.Post = op
+	}
+}
+
+func (doc *OpenAPI) addWebObject(obj WebObjectDoc) {
+	name := webObjectName(obj)
+	objRef := &Schema{Ref: openAPISchemaRef + name}
+	doc.Components.Schemas[name] = DocFieldToSchema(&DocField{Type: TYPE_OBJECT, Fields: obj.Fields})
+	doc.Components.Schemas[name+"QueryResult"] = queryResultSchema(objRef)
+	queryResultRef := &Schema{Ref: openAPISchemaRef + name + "QueryResult"}
+
+	var editFields []DocField
+	for _, f := range obj.Fields {
+		for _, e := range obj.Editables {
+			if f.Name == e {
+				editFields = append(editFields, f)
+			}
+		}
+	}
+
+	newOp := func(id, summary string) *Operation {
+		op := &Operation{
+			OperationID: id + name,
+			Summary:     summary,
+			Responses:   map[string]*Response{"default": {Ref: openAPIResponseRef + "Error"}},
+		}
+		if obj.Group != "" {
+			op.Tags = []string{obj.Group}
+		}
+		if obj.AuthRequired {
+			setAuthRequired(op)
+		}
+		return op
+	}
+
+	primaryPath := obj.Path
+	var primaryParams []Parameter
+	for _, key := range obj.PrimaryKeys {
+		primaryPath += "/{" + key + "}"
+		param := Parameter{Name: key, In: "path", Required: true, Schema: &Schema{Type: "string"}}
+		for _, f := range obj.Fields {
+			if f.Name == key {
+				param.Schema = DocFieldToSchema(&DocField{Type: f.Type})
+				param.Description = f.Desc
+			}
+		}
+		primaryParams = append(primaryParams, param)
+	}
+
+	for _, method := range obj.AllowMethods {
+		switch method {
+		case "GET":
+			op := newOp("get", "Get "+obj.Name)
+			op.Parameters = primaryParams
+			op.Responses["200"] = &Response{Description: "OK", Content: jsonContent(objRef)}
+			op.Responses["404"] = &Response{Ref: openAPIResponseRef + "Error"}
+			doc.addOperation(http.MethodGet, primaryPath, op)
+		case "CREATE":
+			op := newOp("create", "Create "+obj.Name)
+			op.RequestBody = &RequestBody{Required: true, Content: jsonContent(objRef)}
+			op.Responses["200"] = &Response{Description: "OK", Content: jsonContent(objRef)}
+			doc.addOperation(http.MethodPut, obj.Path, op)
+		case "EDIT":
+			op := newOp("update", "Update "+obj.Name)
+			op.Parameters = primaryParams
+			op.RequestBody = &RequestBody{Required: true, Content: jsonContent(DocFieldToSchema(&DocField{Type: TYPE_OBJECT, Fields: editFields}))}
+			op.Responses["200"] = &Response{Description: "OK", Content: jsonContent(&Schema{Type: "boolean"})}
+			op.Responses["404"] = &Response{Ref: openAPIResponseRef + "Error"}
+			doc.addOperation(http.MethodPatch, primaryPath, op)
+		case "DELETE":
+			op := newOp("delete", "Delete "+obj.Name)
+			op.Parameters = primaryParams
+			op.Responses["200"] = &Response{Description: "OK", Content: jsonContent(&Schema{Type: "boolean"})}
+			op.Responses["404"] = &Response{Ref: openAPIResponseRef + "Error"}
+			doc.addOperation(http.MethodDelete, primaryPath, op)
+		case "QUERY":
+			op := newOp("query", "Query "+obj.Name)
+			op.RequestBody = &RequestBody{Content: jsonContent(&Schema{Ref: openAPISchemaRef + "QueryForm"})}
+			op.Responses["200"] = &Response{Description: "OK", Content: jsonContent(queryResultRef)}
+			doc.addOperation(http.MethodPost, obj.Path, op)
+		}
+	}
+
+	for _, name := range obj.Transitions {
+		op := newOp("transition", "Transition "+obj.Name+" by "+name)
+		op.OperationID += schemaName(name)
+		op.Parameters = primaryParams
+		op.Responses["200"] = &Response{Description: "OK", Content: jsonContent(objRef)}
+		op.Responses["400"] = &Response{Ref: openAPIResponseRef + "Error"}
+		op.Responses["404"] = &Response{Ref: openAPIResponseRef + "Error"}
+		doc.addOperation(http.MethodPost, primaryPath+"/"+name, op)
+	}
+
+	for _, name := range obj.Associations {
+		// the body is the primary keys of the associated objects, such as [1, 2]
+		keys := &RequestBody{Required: true, Content: jsonContent(&Schema{Type: "array", Items: &Schema{}})}
+		for method, id := range map[string]string{http.MethodPut: "add", http.MethodDelete: "remove"} {
+			op := newOp(id, strings.ToUpper(id[:1])+id[1:]+" "+name+" of "+obj.Name)
+			op.OperationID += schemaName(name)
+			op.Parameters = primaryParams
+			op.RequestBody = keys
+			op.Responses["200"] = &Response{Description: "OK", Content: jsonContent(&Schema{Type: "boolean"})}
+			op.Responses["404"] = &Response{Ref: openAPIResponseRef + "Error"}
+			doc.addOperation(method, primaryPath+"/"+name, op)
+		}
+	}
+
+	for _, v := range obj.Views {
+		viewName := schemaName(strings.ReplaceAll(strings.TrimPrefix(v.Path, obj.Path+"/"), "/", "_"))
+		op := newOp("query", v.Desc)
+		op.OperationID += viewName
+		op.RequestBody = &RequestBody{Content: jsonContent(&Schema{Ref: openAPISchemaRef + "QueryForm"})}
+		op.Responses["200"] = &Response{Description: "OK", Content: jsonContent(queryResultRef)}
+		doc.addOperation(v.Method, v.Path, op)
+	}
+}
+
+func uriDocOperation(uri UriDoc) *Operation {
+	op := &Operation{
+		OperationID: uriOperationID(uri),
+		Summary:     uri.Desc,
+		Responses:   map[string]*Response{"default": {Ref: openAPIResponseRef + "Error"}},
+	}
+	if uri.Group != "" {
+		op.Tags = []string{uri.Group}
+	}
+	if uri.AuthRequired {
+		setAuthRequired(op)
+	}
+
+	for _, seg := range strings.Split(uri.Path, "/") {
+		if strings.HasPrefix(seg, ":") || strings.HasPrefix(seg, "*") {
+			op.Parameters = append(op.Parameters, Parameter{Name: seg[1:], In: "path", Required: true, Schema: &Schema{Type: "string"}})
+		}
+	}
+
+	if uri.Request != nil {
+		if strings.ToUpper(uri.Method) == http.MethodGet {
+			for _, f := range uri.Request.Fields {
+				f := f
+				op.Parameters = append(op.Parameters, Parameter{Name: f.Name, In: "query", Description: f.Desc, Required: f.Required, Schema: DocFieldToSchema(&f)})
+			}
+		} else {
+			op.RequestBody = &RequestBody{Required: true, Content: jsonContent(DocFieldToSchema(uri.Request))}
+		}
+	}
+
+	resp := &Response{Description: "OK"}
+	if uri.Response != nil {
+		resp.Content = jsonContent(DocFieldToSchema(uri.Response))
+	}
+	op.Responses["200"] = resp
+	return op
+}
+
+// DocFieldToSchema convert DocField to JSON Schema.
+func DocFieldToSchema(f *DocField) *Schema {
+	if f == nil {
+		return &Schema{}
+	}
+	s := &Schema{Description: f.Desc, Default: f.Default}
+
+	switch f.Type {
+	case TYPE_DATE:
+		s.Type = "string"
+		s.Format = "date-time"
+	case TYPE_STRING:
+		s.Type = "string"
+	case TYPE_DECIMAL:
+		s.Type = "string"
+		s.Format = "decimal"
+	case TYPE_BOOLEAN, "bool":
+		s.Type = "boolean"
+	case TYPE_INT, "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "uintptr":
+		s.Type = "integer"
+	case TYPE_FLOAT, "float32", "float64":
+		s.Type = "number"
+	case TYPE_MAP:
+		s.Type = "object"
+		s.AdditionalProperties = true
+	case TYPE_OBJECT:
+		s.Type = "object"
+		if len(f.Fields) > 0 {
+			s.Properties = map[string]*Schema{}
+			for i := range f.Fields {
+				field := &f.Fields[i]
+				s.Properties[field.Name] = DocFieldToSchema(field)
+				if field.Required {
+					s.Required = append(s.Required, field.Name)
+				}
+			}
+		}
+	}
+
+	s.Enum = f.Enum
+
+	if f.IsArray {
+		item := *s
+		item.Description = ""
+		item.Default = nil
+		s = &Schema{Type: "array", Items: &item, Description: f.Desc}
+	}
+
+	if f.CanNull && s.Type != nil {
+		s.Type = []string{s.Type.(string), "null"}
+	}
+	return s
+}
+
+func queryResultSchema(item *Schema) *Schema {
+	s := DocFieldToSchema(GetDocDefine(carrot.QueryResult{}))
+	s.Properties["items"] = &Schema{Type: "array", Items: item}
+	return s
+}
+
+func setAuthRequired(op *Operation) {
+	op.Security = []map[string][]string{{"bearerAuth": {}}, {"cookieAuth": {}}}
+	op.Responses["401"] = &Response{Ref: openAPIResponseRef + "Error"}
+}
+
+func jsonContent(s *Schema) map[string]MediaType {
+	return map[string]MediaType{"application/json": {Schema: s}}
+}
+
+// openAPIPath convert gin path to OpenAPI path, such as "/user/:id" => "/user/{id}".
+func openAPIPath(path string) string {
+	segs := strings.Split(path, "/")
+	for i, seg := range segs {
+		if strings.HasPrefix(seg, ":") || strings.HasPrefix(seg, "*") {
+			segs[i] = "{" + seg[1:] + "}"
+		}
+	}
+	return strings.Join(segs, "/")
+}
+
+// schemaName convert name to CamelCase, such as "product_item" => "ProductItem".
+func schemaName(name string) string {
+	var sb strings.Builder
+	for _, v := range strings.FieldsFunc(name, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	}) {
+		sb.WriteString(strings.ToUpper(v[:1]) + v[1:])
+	}
+	return sb.String()
+}
+
+// operationID return the operation id of method and path, such as "POST /auth/login" => "postAuthLogin".
+func operationID(method, path string) string {
+	return strings.ToLower(method) + schemaName(path)
+}
+
+// uriOperationID return the operation id of uri, the version is appended if it's not in the path,
+// such as "postAuthLoginV2".
+func uriOperationID(uri UriDoc) string {
+	id := operationID(uri.Method, uri.Path)
+	if uri.Version != "" && !strings.Contains(uri.Path+"/", "/"+uri.Version+"/") {
+		id += schemaName(uri.Version)
+	}
+	return id
+}
+
+// webObjectName return the schema name of obj with the version, such as "ProductV2".
+func webObjectName(obj WebObjectDoc) string {
+	return schemaName(obj.Name + " " + obj.Version)
+}
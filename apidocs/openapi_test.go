@@ -0,0 +1,140 @@
+package apidocs
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/restsend/carrot"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpenAPIDocument(t *testing.T) {
+	type product struct {
+		ID    uint     `json:"id" gorm:"primarykey" comment:"product id"`
+		Name  string   `json:"name" binding:"required"`
+		Price *float64 `json:"price"`
+		Tags  []string `json:"tags"`
+	}
+	type loginForm struct {
+		Email    string `json:"email" binding:"required"`
+		Password string `json:"password" binding:"required"`
+	}
+
+	obj := carrot.WebObject{
+		Model:        product{},
+		Group:        "shop",
+		AuthRequired: true,
+		Editables:    []string{"Name", "Price"},
+		AllowMethods: carrot.GET | carrot.EDIT | carrot.QUERY,
+		Views:        []carrot.QueryView{{Path: "hot", Desc: "hot products"}},
+	}
+	objDocs := []WebObjectDoc{GetWebObjectDocDefine("/api", obj)}
+	uriDocs := []UriDoc{
+		{Group: "auth", Path: "/auth/login", Method: http.MethodPost, Request: GetDocDefine(loginForm{}), Response: GetDocDefine(carrot.User{})},
+		{Group: "auth", Path: "/auth/info/:id", Method: http.MethodGet, Request: GetDocDefine(struct {
+			Full bool `json:"full"`
+		}{})},
+	}
+
+	doc := GetOpenAPIDocument("demo", "1.0.0", uriDocs, objDocs)
+	assert.Equal(t, OpenAPIVersion, doc.OpenAPI)
+	assert.Equal(t, []OpenAPITag{{Name: "auth"}, {Name: "shop"}}, doc.Tags)
+
+	login := doc.Paths["/auth/login"].Post
+	assert.NotNil(t, login)
+	assert.Equal(t, "postAuthLogin", login.OperationID)
+	body := login.RequestBody.Content["application/json"].Schema
+	assert.Equal(t, []string{"email", "password"}, body.Required)
+
+	info := doc.Paths["/auth/info/{id}"].Get
+	assert.Equal(t, 2, len(info.Parameters))
+	assert.Equal(t, "path", info.Parameters[0].In)
+	assert.Equal(t, "query", info.Parameters[1].In)
+	assert.Equal(t, "boolean", info.Parameters[1].Schema.Type)
+
+	get := doc.Paths["/api/product/{id}"].Get
+	assert.Equal(t, "getProduct", get.OperationID)
+	assert.Equal(t, "integer", get.Parameters[0].Schema.Type)
+	assert.Equal(t, "#/components/schemas/Product", get.Responses["200"].Content["application/json"].Schema.Ref)
+	assert.Equal(t, 2, len(get.Security))
+	assert.NotNil(t, get.Responses["401"])
+	assert.Nil(t, doc.Paths["/api/product/{id}"].Delete)
+
+	patch := doc.Paths["/api/product/{id}"].Patch
+	patchBody := patch.RequestBody.Content["application/json"].Schema
+	assert.Equal(t, 2, len(patchBody.Properties))
+
+	query := doc.Paths["/api/product"].Post
+	assert.Equal(t, "#/components/schemas/QueryForm", query.RequestBody.Content["application/json"].Schema.Ref)
+	assert.Equal(t, "#/components/schemas/ProductQueryResult", query.Responses["200"].Content["application/json"].Schema.Ref)
+	assert.Nil(t, doc.Paths["/api/product"].Put)
+	assert.Equal(t, "queryProductHot", doc.Paths["/api/product/hot"].Post.OperationID)
+
+	schema := doc.Components.Schemas["Product"]
+	assert.Equal(t, []string{"number", "null"}, schema.Properties["price"].Type)
+	assert.Equal(t, "array", schema.Properties["tags"].Type)
+	assert.Equal(t, "string", schema.Properties["tags"].Items.Type)
+	assert.Equal(t, "product id", schema.Properties["id"].Description)
+	assert.Equal(t, "array", doc.Components.Schemas["ProductQueryResult"].Properties["items"].Type)
+	assert.NotNil(t, doc.Components.Schemas["QueryForm"].Properties["filters"])
+
+	r := gin.New()
+	RegisterHandler(r.Group("/docs"), uriDocs, objDocs)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs/openapi.json", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	var served map[string]any
+	err := json.Unmarshal(w.Body.Bytes(), &served)
+	assert.Nil(t, err)
+	assert.Equal(t, OpenAPIVersion, served["openapi"])
+	assert.Equal(t, "API", served["info"].(map[string]any)["title"])
+}
+
+func TestOpenAPIObjectRoutes(t *testing.T) {
+	type postTag struct {
+		ID   uint   `json:"id" gorm:"primarykey"`
+		Name string `json:"name"`
+	}
+	type post struct {
+		ID     uint      `json:"id" gorm:"primarykey"`
+		Status string    `json:"status"`
+		Tags   []postTag `json:"tags" gorm:"many2many:post_tags"`
+	}
+	obj := carrot.WebObject{
+		Model:        post{},
+		Associations: []string{"Tags"},
+		StateMachine: &carrot.StateMachine{
+			Field:       "Status",
+			Transitions: []carrot.StateTransition{{Name: "publish", To: "published"}},
+		},
+	}
+	r := gin.New()
+	err := obj.RegisterObject(r.Group("/api"))
+	assert.Nil(t, err)
+
+	objDoc := GetWebObjectDocDefine("/api", obj)
+	assert.Equal(t, []string{"publish"}, objDoc.Transitions)
+	doc := GetOpenAPIDocument("demo", "1.0", nil, []WebObjectDoc{objDoc})
+
+	// every route served by RegisterObject is in the document
+	for _, route := range r.Routes() {
+		item, ok := doc.Paths[openAPIPath(route.Path)]
+		if !assert.True(t, ok, route.Path) {
+			continue
+		}
+		op := map[string]*Operation{
+			http.MethodGet:    item.Get,
+			http.MethodPut:    item.Put,
+			http.MethodPost:   item.Post,
+			http.MethodPatch:  item.Patch,
+			http.MethodDelete: item.Delete,
+		}[route.Method]
+		assert.NotNil(t, op, route.Method+" "+route.Path)
+	}
+	assert.Equal(t, "transitionPostPublish", doc.Paths["/api/post/{id}/publish"].Post.OperationID)
+	assert.Equal(t, "addPostTags", doc.Paths["/api/post/{id}/tags"].Put.OperationID)
+	assert.Equal(t, "removePostTags", doc.Paths["/api/post/{id}/tags"].Delete.OperationID)
+}
@@ -0,0 +1,163 @@
+package apidocs
+
+import (
+	"net/http"
+	"reflect"
+	"runtime"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// webObjectHandlerPrefix is the handler name prefix of the routes registered by WebObject,
+// such as "github.com/restsend/carrot.(*WebObject).RegisterObject.func1".
+const webObjectHandlerPrefix = "github.com/restsend/carrot.(*WebObject)."
+
+// DiscoverRoutes match routes to uriDocs and objDocs, return the documented UriDocs and the undocumented routes.
+//
+// A route matches an UriDoc when the handler is the MethodRef of the UriDoc, or the method and path are equal.
+// The empty Path and Method of the UriDoc are filled from the matched routes, so an UriDoc with
+// only MethodRef is expanded to one UriDoc per route.
+// Routes under the ignores path prefixes are skipped, such as "/admin".
+func DiscoverRoutes(routes gin.RoutesInfo, uriDocs []UriDoc, objDocs []WebObjectDoc, ignores ...string) ([]UriDoc, gin.RoutesInfo) {
+	var documented []UriDoc
+	var undocumented gin.RoutesInfo
+	matched := make([]bool, len(uriDocs))
+
+	for _, route := range routes {
+		if hasPathPrefix(route.Path, ignores) {
+			continue
+		}
+
+		found := false
+		for i, doc := range uriDocs {
+			if !matchUriDoc(doc, route) {
+				continue
+			}
+			found = true
+			if doc.Path != "" && doc.Method != "" {
+				if !matched[i] {
+					documented = append(documented, doc)
+				}
+			} else {
+				if doc.Path == "" {
+					doc.Path = route.Path
+				}
+				if doc.Method == "" {
+					doc.Method = route.Method
+				}
+				documented = append(documented, doc)
+			}
+			matched[i] = true
+		}
+
+		if !found {
+			for _, obj := range objDocs {
+				if matchWebObjectDoc(obj, route) {
+					found = true
+					break
+				}
+			}
+		}
+
+		if !found {
+			undocumented = append(undocumented, route)
+		}
+	}
+
+	// UriDocs without route are kept as they are
+	for i, doc := range uriDocs {
+		if !matched[i] {
+			documented = append(documented, doc)
+		}
+	}
+	return documented, undocumented
+}
+
+// TestingT is the part of testing.TB used by the checks, such as *testing.T,
+// the library doesn't import the testing package.
+type TestingT interface {
+	Errorf(format string, args ...any)
+}
+
+// markHelper mark the caller as test helper if t supports it.
+func markHelper(t TestingT) {
+	if h, ok := t.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+}
+
+// CheckRoutesDocumented fail t for each route of r which has no documentation.
+func CheckRoutesDocumented(t TestingT, r *gin.Engine, uriDocs []UriDoc, objDocs []WebObjectDoc, ignores ...string) {
+	markHelper(t)
+	_, undocumented := DiscoverRoutes(r.Routes(), uriDocs, objDocs, ignores...)
+	for _, route := range undocumented {
+		t.Errorf("route %s %s (%s) has no documentation", route.Method, route.Path, route.Handler)
+	}
+}
+
+func matchUriDoc(doc UriDoc, route gin.RouteInfo) bool {
+	if doc.Method != "" && !strings.EqualFold(doc.Method, route.Method) {
+		return false
+	}
+	if doc.Path != "" && doc.Path != route.Path {
+		return false
+	}
+	if doc.MethodRef != nil {
+		return functionName(doc.MethodRef) == strings.TrimSuffix(route.Handler, "-fm")
+	}
+	return doc.Path != "" && doc.Method != ""
+}
+
+func matchWebObjectDoc(obj WebObjectDoc, route gin.RouteInfo) bool {
+	primaryPath := obj.Path
+	for _, key := range obj.PrimaryKeys {
+		primaryPath += "/:" + key
+	}
+
+	switch route.Method {
+	case http.MethodGet, http.MethodPatch, http.MethodDelete:
+		if route.Path == primaryPath {
+			return true
+		}
+	case http.MethodPut, http.MethodPost:
+		if route.Path == obj.Path {
+			return true
+		}
+	}
+
+	for _, v := range obj.Views {
+		method := v.Method
+		if method == "" {
+			method = http.MethodPost
+		}
+		if route.Path == v.Path && route.Method == method {
+			return true
+		}
+	}
+
+	// other routes of WebObject, such as the state transitions
+	return strings.HasPrefix(route.Handler, webObjectHandlerPrefix) && strings.HasPrefix(route.Path, obj.Path+"/")
+}
+
+// functionName return the full name of fn, such as "github.com/restsend/carrot.(*AdminObject).handleDelete".
+func functionName(fn any) string {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func {
+		return ""
+	}
+	f := runtime.FuncForPC(v.Pointer())
+	if f == nil {
+		return ""
+	}
+	return strings.TrimSuffix(f.Name(), "-fm")
+}
+
+func hasPathPrefix(path string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if path == prefix || strings.HasPrefix(path, strings.TrimSuffix(prefix, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package apidocs
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/restsend/carrot"
+	"github.com/stretchr/testify/assert"
+)
+
+func handleRouteLogin(c *gin.Context)  {}
+func handleRouteLogout(c *gin.Context) {}
+func handleRouteHidden(c *gin.Context) {}
+
+type fakeTB struct {
+	errors []string
+}
+
+func (t *fakeTB) Errorf(format string, args ...any) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func TestDiscoverRoutes(t *testing.T) {
+	type note struct {
+		ID    uint   `json:"id" gorm:"primarykey"`
+		State string `json:"state"`
+	}
+
+	r := gin.New()
+	api := r.Group("/api")
+	api.POST("/auth/login", handleRouteLogin)
+	api.GET("/auth/logout", handleRouteLogout)
+	api.POST("/auth/logout", handleRouteLogout)
+	api.GET("/hidden", handleRouteHidden)
+	r.GET("/admin/index", handleRouteHidden)
+
+	obj := carrot.WebObject{
+		Model: note{},
+		Views: []carrot.QueryView{{Path: "recent"}},
+		StateMachine: &carrot.StateMachine{
+			Field:       "State",
+			Transitions: []carrot.StateTransition{{Name: "close", To: "closed"}},
+		},
+	}
+	err := obj.RegisterObject(api)
+	assert.Nil(t, err)
+
+	uriDocs := []UriDoc{
+		{MethodRef: handleRouteLogin, Desc: "login"},
+		{MethodRef: handleRouteLogout, Desc: "logout"},
+		{Path: "/api/unknown", Method: http.MethodGet},
+	}
+	objDocs := []WebObjectDoc{GetWebObjectDocDefine("/api", obj)}
+
+	documented, undocumented := DiscoverRoutes(r.Routes(), uriDocs, objDocs, "/admin")
+	assert.Equal(t, 1, len(undocumented))
+	assert.Equal(t, "/api/hidden", undocumented[0].Path)
+
+	assert.Equal(t, 4, len(documented))
+	for _, doc := range documented {
+		switch doc.Desc {
+		case "login":
+			assert.Equal(t, "/api/auth/login", doc.Path)
+			assert.Equal(t, http.MethodPost, doc.Method)
+		case "logout":
+			assert.Equal(t, "/api/auth/logout", doc.Path)
+		default:
+			assert.Equal(t, "/api/unknown", doc.Path)
+		}
+	}
+
+	tb := &fakeTB{}
+	CheckRoutesDocumented(tb, r, uriDocs, objDocs, "/admin")
+	assert.Equal(t, 1, len(tb.errors))
+	assert.Contains(t, tb.errors[0], "GET /api/hidden")
+
+	CheckRoutesDocumented(t, r, uriDocs, objDocs, "/admin", "/api/hidden")
+}
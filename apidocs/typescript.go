@@ -0,0 +1,300 @@
+package apidocs
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+var tsIdentifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+const tsRuntime = `// Code generated by carrot apidocs. DO NOT EDIT.
+
+export type FilterOp = "=" | "<>" | "in" | "not_in" | ">" | ">=" | "<" | "<=" | "like" | "between" | "is not";
+export type OrderOp = "asc" | "desc";
+
+export interface Filter<F extends string = string> {
+  name: F;
+  op: FilterOp;
+  value: any;
+}
+
+export interface Order<O extends string = string> {
+  name: O;
+  op: OrderOp;
+}
+
+export interface QueryForm<F extends string = string, O extends string = string> {
+  pos?: number;
+  limit?: number;
+  keyword?: string;
+  filters?: Filter<F>[];
+  orders?: Order<O>[];
+  countMode?: "exact" | "estimated" | "none";
+}
+
+export interface QueryResult<T> {
+  total?: number;
+  pos?: number;
+  limit?: number;
+  keyword?: string;
+  totalKind?: "exact" | "estimated" | "none";
+  items: T[];
+}
+
+export class ApiError extends Error {
+  constructor(public status: number, message: string) {
+    super(message);
+  }
+}
+
+export interface ClientOptions {
+  baseUrl?: string;
+  token?: string;
+  fetch?: typeof fetch;
+}
+
+export class ApiClient {
+  constructor(public options: ClientOptions = {}) {}
+
+  async request<T>(method: string, path: string, body?: any, query?: Record<string, any>): Promise<T> {
+    let url = (this.options.baseUrl || "") + path;
+    if (query) {
+      const params = new URLSearchParams();
+      for (const [k, v] of Object.entries(query)) {
+        if (v !== undefined && v !== null) params.append(k, String(v));
+      }
+      const qs = params.toString();
+      if (qs) url += "?" + qs;
+    }
+    const headers: Record<string, string> = { "Content-Type": "application/json" };
+    if (this.options.token) headers["Authorization"] = "Bearer " + this.options.token;
+    const doFetch = this.options.fetch || fetch;
+    const resp = await doFetch(url, {
+      method,
+      headers,
+      credentials: "include",
+      body: body === undefined ? undefined : JSON.stringify(body),
+    });
+    const text = await resp.text();
+    const data = text ? JSON.parse(text) : undefined;
+    if (!resp.ok) {
+      throw new ApiError(resp.status, (data && data.error) || resp.statusText);
+    }
+    return data as T;
+  }
+}
+
+export function filter<F extends string>(name: F, op: FilterOp, value: any): Filter<F> {
+  return { name, op, value };
+}
+
+export function order<O extends string>(name: O, op: OrderOp = "asc"): Order<O> {
+  return { name, op };
+}
+`
+
+// GenerateTypeScript generate a typed TypeScript client of uriDocs and objDocs.
+// The output contains the runtime (ApiClient, QueryForm, QueryResult),
+// the interfaces of the models and one function per endpoint.
+func GenerateTypeScript(uriDocs []UriDoc, objDocs []WebObjectDoc) string {
+	var sb strings.Builder
+	sb.WriteString(tsRuntime)
+
+	for _, obj := range objDocs {
+		writeTSWebObject(&sb, obj)
+	}
+	for _, uri := range uriDocs {
+		writeTSUriDoc(&sb, uri)
+	}
+	return sb.String()
+}
+
+func writeTSWebObject(sb *strings.Builder, obj WebObjectDoc) {
+	name := webObjectName(obj)
+	filterType := name + "FilterField"
+	orderType := name + "OrderField"
+
+	fmt.Fprintf(sb, "\n// %s %s\n", name, obj.Desc)
+	fmt.Fprintf(sb, "export interface %s %s\n", name, tsObjectType(obj.Fields, ""))
+	fmt.Fprintf(sb, "export type %s = %s;\n", filterType, tsUnion(obj.Filters))
+	fmt.Fprintf(sb, "export type %s = %s;\n", orderType, tsUnion(obj.Orders))
+	fmt.Fprintf(sb, "export type %sQueryForm = QueryForm<%s, %s>;\n", name, filterType, orderType)
+
+	var keyArgs, keyPath []string
+	for _, key := range obj.PrimaryKeys {
+		keyType := "string | number"
+		for _, f := range obj.Fields {
+			if f.Name == key {
+				keyType = tsType(&f, "")
+			}
+		}
+		arg := tsArgName(key)
+		keyArgs = append(keyArgs, arg+": "+keyType)
+		keyPath = append(keyPath, "${encodeURIComponent(String("+arg+"))}")
+	}
+	objPath := "`" + obj.Path + "`"
+	primaryPath := "`" + strings.Join(append([]string{obj.Path}, keyPath...), "/") + "`"
+	clientArgs := strings.Join(append([]string{"client: ApiClient"}, keyArgs...), ", ")
+
+	editable := tsUnion(obj.Editables)
+	for _, method := range obj.AllowMethods {
+		switch method {
+		case "GET":
+			fmt.Fprintf(sb, "export function get%s(%s): Promise<%s> {\n  return client.request(\"GET\", %s);\n}\n", name, clientArgs, name, primaryPath)
+		case "CREATE":
+			fmt.Fprintf(sb, "export function create%s(client: ApiClient, obj: Partial<%s>): Promise<%s> {\n  return client.request(\"PUT\", %s, obj);\n}\n", name, name, name, objPath)
+		case "EDIT":
+			fmt.Fprintf(sb, "export function update%s(%s, vals: Partial<Pick<%s, %s>>): Promise<boolean> {\n  return client.request(\"PATCH\", %s, vals);\n}\n", name, clientArgs, name, editable, primaryPath)
+		case "DELETE":
+			fmt.Fprintf(sb, "export function delete%s(%s): Promise<boolean> {\n  return client.request(\"DELETE\", %s);\n}\n", name, clientArgs, primaryPath)
+		case "QUERY":
+			fmt.Fprintf(sb, "export function query%s(client: ApiClient, form: %sQueryForm = {}): Promise<QueryResult<%s>> {\n  return client.request(\"POST\", %s, form);\n}\n", name, name, name, objPath)
+		}
+	}
+
+	for _, v := range obj.Views {
+		method := v.Method
+		if method == "" {
+			method = http.MethodPost
+		}
+		viewName := schemaName(strings.ReplaceAll(strings.TrimPrefix(v.Path, obj.Path+"/"), "/", "_"))
+		if v.Desc != "" {
+			fmt.Fprintf(sb, "// %s\n", v.Desc)
+		}
+		fmt.Fprintf(sb, "export function query%s%s(client: ApiClient, form: %sQueryForm = {}): Promise<QueryResult<%s>> {\n  return client.request(%q, `%s`, form);\n}\n", name, viewName, name, name, method, v.Path)
+	}
+}
+
+func writeTSUriDoc(sb *strings.Builder, uri UriDoc) {
+	fn := uriOperationID(uri)
+	typeName := schemaName(fn)
+	method := strings.ToUpper(uri.Method)
+	if method == "" {
+		method = http.MethodPost
+	}
+
+	sb.WriteString("\n")
+	if uri.Desc != "" {
+		fmt.Fprintf(sb, "// %s\n", uri.Desc)
+	}
+
+	args := []string{"client: ApiClient"}
+	var segs []string
+	for _, seg := range strings.Split(uri.Path, "/") {
+		if strings.HasPrefix(seg, ":") || strings.HasPrefix(seg, "*") {
+			arg := tsArgName(seg[1:])
+			args = append(args, arg+": string | number")
+			seg = "${encodeURIComponent(String(" + arg + "))}"
+		}
+		segs = append(segs, seg)
+	}
+	path := "`" + strings.Join(segs, "/") + "`"
+
+	respType := "any"
+	if uri.Response != nil {
+		respType = typeName + "Response"
+		fmt.Fprintf(sb, "export type %s = %s;\n", respType, tsType(uri.Response, ""))
+	}
+
+	body := ""
+	if uri.Request != nil {
+		reqType := typeName + "Request"
+		fmt.Fprintf(sb, "export type %s = %s;\n", reqType, tsType(uri.Request, ""))
+		args = append(args, "req: "+reqType)
+		if method == http.MethodGet {
+			body = ", undefined, req"
+		} else {
+			body = ", req"
+		}
+	}
+
+	fmt.Fprintf(sb, "export function %s(%s): Promise<%s> {\n  return client.request(%q, %s%s);\n}\n", fn, strings.Join(args, ", "), respType, method, path, body)
+}
+
+// tsType return the TypeScript type of f, such as "number", "string[]" or "{ name: string; }".
+func tsType(f *DocField, indent string) string {
+	var t string
+	switch f.Type {
+	case TYPE_DATE, TYPE_STRING, TYPE_DECIMAL:
+		t = "string"
+	case TYPE_BOOLEAN, "bool":
+		t = "boolean"
+	case TYPE_INT, TYPE_FLOAT, "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "uintptr", "float32", "float64":
+		t = "number"
+	case TYPE_MAP:
+		t = "Record<string, any>"
+	case TYPE_OBJECT:
+		if len(f.Fields) > 0 {
+			t = tsObjectType(f.Fields, indent)
+		} else {
+			t = "Record<string, any>"
+		}
+	default:
+		t = "any"
+	}
+	if len(f.Enum) > 0 {
+		var values []string
+		for _, v := range f.Enum {
+			data, _ := json.Marshal(v)
+			values = append(values, string(data))
+		}
+		t = strings.Join(values, " | ")
+		if f.IsArray {
+			t = "(" + t + ")"
+		}
+	}
+	if f.IsArray {
+		if strings.HasPrefix(t, "{") || strings.Contains(t, "<") {
+			t = "Array<" + t + ">"
+		} else {
+			t += "[]"
+		}
+	}
+	return t
+}
+
+func tsObjectType(fields []DocField, indent string) string {
+	var sb strings.Builder
+	sb.WriteString("{\n")
+	for i := range fields {
+		f := &fields[i]
+		if f.Desc != "" {
+			fmt.Fprintf(&sb, "%s  /** %s */\n", indent, f.Desc)
+		}
+		name := f.Name
+		if !tsIdentifier.MatchString(name) {
+			name = fmt.Sprintf("%q", name)
+		}
+		t := tsType(f, indent+"  ")
+		if f.CanNull {
+			fmt.Fprintf(&sb, "%s  %s?: %s | null;\n", indent, name, t)
+		} else {
+			fmt.Fprintf(&sb, "%s  %s: %s;\n", indent, name, t)
+		}
+	}
+	sb.WriteString(indent + "}")
+	return sb.String()
+}
+
+// tsUnion return the string literal union of names, "never" if names is empty.
+func tsUnion(names []string) string {
+	if len(names) == 0 {
+		return "never"
+	}
+	var vals []string
+	for _, v := range names {
+		vals = append(vals, fmt.Sprintf("%q", v))
+	}
+	return strings.Join(vals, " | ")
+}
+
+func tsArgName(name string) string {
+	name = schemaName(name)
+	if name == "" {
+		return "arg"
+	}
+	return strings.ToLower(name[:1]) + name[1:]
+}
@@ -0,0 +1,64 @@
+package apidocs
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/restsend/carrot"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateTypeScript(t *testing.T) {
+	type product struct {
+		UUID  string   `json:"id" gorm:"primarykey;size:20"`
+		Name  string   `json:"name" comment:"product name"`
+		Price *float64 `json:"price"`
+		Tags  []string `json:"tags"`
+	}
+	type loginForm struct {
+		Email    string `json:"email" binding:"required"`
+		Password string `json:"password" binding:"required"`
+	}
+
+	obj := carrot.WebObject{
+		Model:       product{},
+		Editables:   []string{"Name"},
+		Filterables: []string{"Name", "Price"},
+		Orderables:  []string{"Price"},
+		Views:       []carrot.QueryView{{Path: "hot", Desc: "hot products"}},
+	}
+	objDocs := []WebObjectDoc{GetWebObjectDocDefine("/api", obj)}
+	assert.Equal(t, []string{"name", "price"}, objDocs[0].Filters)
+
+	uriDocs := []UriDoc{
+		{Path: "/auth/login", Method: http.MethodPost, Desc: "login", Request: GetDocDefine(loginForm{}), Response: GetDocDefine(true)},
+		{Path: "/auth/info/:id", Method: http.MethodGet},
+	}
+
+	code := GenerateTypeScript(uriDocs, objDocs)
+	assert.Contains(t, code, "export class ApiClient")
+	assert.Contains(t, code, "export interface Product {")
+	assert.Contains(t, code, "  /** product name */\n  name: string;")
+	assert.Contains(t, code, "price?: number | null;")
+	assert.Contains(t, code, "tags: string[];")
+	assert.Contains(t, code, `export type ProductFilterField = "name" | "price";`)
+	assert.Contains(t, code, `export type ProductOrderField = "price";`)
+	assert.Contains(t, code, "export function getProduct(client: ApiClient, id: string): Promise<Product> {\n  return client.request(\"GET\", `/api/product/${encodeURIComponent(String(id))}`);")
+	assert.Contains(t, code, `vals: Partial<Pick<Product, "name">>`)
+	assert.Contains(t, code, "export function queryProduct(client: ApiClient, form: ProductQueryForm = {}): Promise<QueryResult<Product>>")
+	assert.Contains(t, code, "export function queryProductHot(")
+	assert.Contains(t, code, "export type PostAuthLoginRequest = {\n  email: string;\n  password: string;\n};")
+	assert.Contains(t, code, "export function postAuthLogin(client: ApiClient, req: PostAuthLoginRequest): Promise<PostAuthLoginResponse>")
+	assert.Contains(t, code, "export function getAuthInfoId(client: ApiClient, id: string | number): Promise<any>")
+
+	// load from file
+	data, _ := json.Marshal(map[string]any{"uris": uriDocs, "objs": objDocs})
+	fname := filepath.Join(t.TempDir(), "docs.json")
+	os.WriteFile(fname, data, 0644)
+	docs, err := LoadDocs(fname)
+	assert.Nil(t, err)
+	assert.Equal(t, code, GenerateTypeScript(docs.Uris, docs.Objs))
+}
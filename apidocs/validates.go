@@ -0,0 +1,241 @@
+package apidocs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/restsend/carrot"
+	"github.com/sirupsen/logrus"
+)
+
+// FieldError is a violation of DocField, Path is the json path such as "items[0].name".
+type FieldError struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+type ValidationErrors []FieldError
+
+func (errs ValidationErrors) Error() string {
+	var msgs []string
+	for _, e := range errs {
+		if e.Path == "" {
+			msgs = append(msgs, e.Message)
+		} else {
+			msgs = append(msgs, e.Path+": "+e.Message)
+		}
+	}
+	return "validation failed: " + strings.Join(msgs, "; ")
+}
+
+type ValidationOptions struct {
+	ValidateResponse bool                                                     // Validate the responses, default is true in gin test mode
+	OnDrift          func(c *gin.Context, doc *UriDoc, errs ValidationErrors) // Called when the response not match the doc, default is logging
+}
+
+// WithValidation return a middleware which validates the request bodies against UriDoc.Request,
+// invalid requests are rejected with 400 and the field errors, such as:
+//
+//	{"error": "validation failed: email: required", "fields": [{"path": "email", "message": "required"}]}
+//
+// The responses are validated against UriDoc.Response if opts.ValidateResponse, violations
+// are reported to opts.OnDrift as contract drift.
+func WithValidation(uriDocs []UriDoc, opts *ValidationOptions) gin.HandlerFunc {
+	if opts == nil {
+		opts = &ValidationOptions{ValidateResponse: gin.Mode() == gin.TestMode}
+	}
+	onDrift := opts.OnDrift
+	if onDrift == nil {
+		onDrift = func(c *gin.Context, doc *UriDoc, errs ValidationErrors) {
+			logrus.WithFields(logrus.Fields{
+				"method": doc.Method,
+				"path":   doc.Path,
+				"errors": errs,
+			}).Warn("apidocs: response contract drift")
+		}
+	}
+
+	docs := map[string]*UriDoc{}
+	for i := range uriDocs {
+		doc := &uriDocs[i]
+		docs[strings.ToUpper(doc.Method)+" "+doc.Path] = doc
+	}
+
+	return func(c *gin.Context) {
+		doc, ok := docs[c.Request.Method+" "+c.FullPath()]
+		if !ok {
+			c.Next()
+			return
+		}
+
+		if doc.Request != nil && c.Request.Method != http.MethodGet && c.Request.Body != nil {
+			body, err := io.ReadAll(c.Request.Body)
+			if err != nil {
+				carrot.AbortWithJSONError(c, http.StatusBadRequest, err)
+				return
+			}
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+			var val any
+			if len(body) > 0 {
+				if err := json.Unmarshal(body, &val); err != nil {
+					carrot.AbortWithJSONError(c, http.StatusBadRequest, err)
+					return
+				}
+			}
+			if errs := ValidateDocField(doc.Request, val); len(errs) > 0 {
+				c.Abort()
+				carrot.RenderJSON(c, http.StatusBadRequest, gin.H{"error": errs.Error(), "fields": errs})
+				return
+			}
+		}
+
+		if !opts.ValidateResponse || doc.Response == nil {
+			c.Next()
+			return
+		}
+
+		w := &bodyRecorder{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Next()
+
+		if w.Status() != http.StatusOK {
+			return
+		}
+		var val any
+		if err := json.Unmarshal(w.body.Bytes(), &val); err != nil {
+			onDrift(c, doc, ValidationErrors{{Message: err.Error()}})
+			return
+		}
+		if errs := ValidateDocField(doc.Response, val); len(errs) > 0 {
+			onDrift(c, doc, errs)
+		}
+	}
+}
+
+type bodyRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bodyRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *bodyRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// ValidateDocField check the decoded json value against f.
+func ValidateDocField(f *DocField, value any) ValidationErrors {
+	var errs ValidationErrors
+	validateDocField(f, "", value, &errs)
+	return errs
+}
+
+func validateDocField(f *DocField, path string, value any, errs *ValidationErrors) {
+	addError := func(format string, args ...any) {
+		*errs = append(*errs, FieldError{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if value == nil {
+		if f.Required {
+			addError("required")
+		} else if !f.CanNull && !f.IsArray && isScalarType(f.Type) {
+			addError("can't be null")
+		}
+		return
+	}
+
+	if f.IsArray {
+		items, ok := value.([]any)
+		if !ok {
+			addError("must be array")
+			return
+		}
+		elem := *f
+		elem.IsArray = false
+		elem.Required = false
+		for i, item := range items {
+			validateDocField(&elem, fmt.Sprintf("%s[%d]", path, i), item, errs)
+		}
+		return
+	}
+
+	if len(f.Enum) > 0 && !(&carrot.Enum{Values: enumValues(f.Enum)}).Contains(value) {
+		addError("must be one of %v", f.Enum)
+		return
+	}
+
+	switch f.Type {
+	case TYPE_STRING, TYPE_DATE:
+		if _, ok := value.(string); !ok {
+			addError("must be string")
+		}
+	case TYPE_DECIMAL:
+		if _, err := carrot.AsDecimal(value); err != nil {
+			addError("must be decimal")
+		}
+	case TYPE_BOOLEAN, "bool":
+		if _, ok := value.(bool); !ok {
+			addError("must be boolean")
+		}
+	case TYPE_INT, "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "uintptr":
+		if v, ok := value.(float64); !ok || v != math.Trunc(v) {
+			addError("must be integer")
+		}
+	case TYPE_FLOAT, "float32", "float64":
+		if _, ok := value.(float64); !ok {
+			addError("must be number")
+		}
+	case TYPE_MAP:
+		if _, ok := value.(map[string]any); !ok {
+			addError("must be object")
+		}
+	case TYPE_OBJECT:
+		vals, ok := value.(map[string]any)
+		if !ok {
+			addError("must be object")
+			return
+		}
+		for i := range f.Fields {
+			field := &f.Fields[i]
+			fieldPath := field.Name
+			if path != "" {
+				fieldPath = path + "." + field.Name
+			}
+			v, exists := vals[field.Name]
+			if !exists {
+				if field.Required {
+					*errs = append(*errs, FieldError{Path: fieldPath, Message: "required"})
+				}
+				continue
+			}
+			validateDocField(field, fieldPath, v, errs)
+		}
+	}
+}
+
+func enumValues(values []any) []carrot.EnumValue {
+	var r []carrot.EnumValue
+	for _, v := range values {
+		r = append(r, carrot.EnumValue{Value: v})
+	}
+	return r
+}
+
+func isScalarType(t string) bool {
+	switch t {
+	case "", TYPE_OBJECT, TYPE_MAP:
+		return false
+	}
+	return true
+}
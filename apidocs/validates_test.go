@@ -0,0 +1,104 @@
+package apidocs
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateDocField(t *testing.T) {
+	type item struct {
+		Name  string `json:"name" binding:"required"`
+		Count int    `json:"count"`
+	}
+	type form struct {
+		Email string   `json:"email" binding:"required"`
+		Age   *int     `json:"age"`
+		Score float64  `json:"score"`
+		Tags  []string `json:"tags"`
+		Items []item   `json:"items"`
+	}
+	f := GetDocDefine(form{})
+
+	var val any
+	json.Unmarshal([]byte(`{"email":"a@b.c","age":null,"score":1.5,"tags":null,"items":[{"name":"x","count":1}]}`), &val)
+	assert.Equal(t, 0, len(ValidateDocField(f, val)))
+
+	json.Unmarshal([]byte(`{"score":"1","tags":[1],"items":[{"count":1.5}]}`), &val)
+	errs := ValidateDocField(f, val)
+	assert.Equal(t, ValidationErrors{
+		{Path: "email", Message: "required"},
+		{Path: "score", Message: "must be number"},
+		{Path: "tags[0]", Message: "must be string"},
+		{Path: "items[0].name", Message: "required"},
+		{Path: "items[0].count", Message: "must be integer"},
+	}, errs)
+	assert.Contains(t, errs.Error(), "email: required")
+
+	errs = ValidateDocField(f, []any{})
+	assert.Equal(t, "must be object", errs[0].Message)
+}
+
+func TestWithValidation(t *testing.T) {
+	type loginForm struct {
+		Email    string `json:"email" binding:"required"`
+		Password string `json:"password" binding:"required"`
+	}
+	type loginResult struct {
+		ID    uint   `json:"id"`
+		Email string `json:"email"`
+	}
+
+	uriDocs := []UriDoc{
+		{Path: "/auth/login", Method: http.MethodPost, Request: GetDocDefine(loginForm{}), Response: GetDocDefine(loginResult{})},
+	}
+
+	var drifts ValidationErrors
+	r := gin.New()
+	r.Use(WithValidation(uriDocs, &ValidationOptions{
+		ValidateResponse: true,
+		OnDrift: func(c *gin.Context, doc *UriDoc, errs ValidationErrors) {
+			drifts = append(drifts, errs...)
+		},
+	}))
+	r.POST("/auth/login", func(c *gin.Context) {
+		var form loginForm
+		if err := c.BindJSON(&form); err != nil {
+			return
+		}
+		if form.Email == "bad@b.c" {
+			c.JSON(http.StatusOK, gin.H{"id": "1", "email": form.Email})
+			return
+		}
+		c.JSON(http.StatusOK, loginResult{ID: 1, Email: form.Email})
+	})
+
+	send := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/auth/login", strings.NewReader(body)))
+		return w
+	}
+
+	w := send(`{"email":"a@b.c"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var res struct {
+		Error  string           `json:"error"`
+		Fields ValidationErrors `json:"fields"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &res)
+	assert.Equal(t, ValidationErrors{{Path: "password", Message: "required"}}, res.Fields)
+
+	w = send(`{"email":"a@b.c","password":"123"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"email":"a@b.c"`)
+	assert.Equal(t, 0, len(drifts))
+
+	w = send(`{"email":"bad@b.c","password":"123"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, ValidationErrors{{Path: "id", Message: "must be integer"}}, drifts)
+}
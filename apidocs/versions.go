@@ -0,0 +1,50 @@
+package apidocs
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/gin-gonic/gin"
+	"github.com/restsend/carrot"
+)
+
+// RegisterVersion register objs and uriDocs under the route group "/{version}" of r,
+// such as "/api/v2/product", and return the docs with the full paths and the version.
+//
+// UriDoc is registered when MethodRef is a gin handler, otherwise only the doc is returned.
+// objs must not be shared between versions, since RegisterObject keeps the pointers.
+func RegisterVersion(r *gin.RouterGroup, version string, objs []carrot.WebObject, uriDocs []UriDoc) ([]UriDoc, []WebObjectDoc, error) {
+	if version == "" {
+		return nil, nil, fmt.Errorf("invalid version")
+	}
+	g := r.Group("/" + version)
+
+	var objDocs []WebObjectDoc
+	for idx := range objs {
+		obj := &objs[idx]
+		if err := obj.RegisterObject(g); err != nil {
+			return nil, nil, fmt.Errorf("register object %s fail: %v", obj.Name, err)
+		}
+		doc := GetWebObjectDocDefine(g.BasePath(), *obj)
+		doc.Version = version
+		objDocs = append(objDocs, doc)
+	}
+
+	var docs []UriDoc
+	for _, doc := range uriDocs {
+		var handler gin.HandlerFunc
+		switch fn := doc.MethodRef.(type) {
+		case gin.HandlerFunc:
+			handler = fn
+		case func(*gin.Context):
+			handler = fn
+		}
+		if handler != nil {
+			g.Handle(doc.Method, doc.Path, handler)
+		}
+		doc.Path = path.Join(g.BasePath(), doc.Path)
+		doc.Version = version
+		docs = append(docs, doc)
+	}
+	return docs, objDocs, nil
+}
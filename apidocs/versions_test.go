@@ -0,0 +1,94 @@
+package apidocs
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/restsend/carrot"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisterVersion(t *testing.T) {
+	type product struct {
+		ID   uint   `json:"id" gorm:"primarykey"`
+		Name string `json:"name"`
+	}
+	db, err := carrot.InitDatabase(nil, "", "")
+	assert.Nil(t, err)
+	db.AutoMigrate(&product{})
+	db.Create(&product{ID: 1, Name: "demo"})
+
+	r := gin.New()
+	r.Use(func(ctx *gin.Context) {
+		ctx.Set(carrot.DbField, db)
+		ctx.Next()
+	})
+	ping := func(c *gin.Context) { c.JSON(http.StatusOK, "pong") }
+	uriDocs := []UriDoc{
+		{Group: "Ping", Path: "/ping", Method: http.MethodGet, MethodRef: ping},
+		{Group: "Ping", Path: "/echo", Method: http.MethodPost},
+	}
+	newObjs := func() []carrot.WebObject {
+		return []carrot.WebObject{{Model: product{}, AllowMethods: carrot.GET}}
+	}
+
+	v1Uris, v1Objs, err := RegisterVersion(r.Group("/api"), "v1", newObjs(), uriDocs)
+	assert.Nil(t, err)
+	v2Uris, v2Objs, err := RegisterVersion(r.Group("/api"), "v2", newObjs(), uriDocs)
+	assert.Nil(t, err)
+
+	assert.Equal(t, "/api/v1/ping", v1Uris[0].Path)
+	assert.Equal(t, "v1", v1Uris[0].Version)
+	assert.Equal(t, "/api/v2/echo", v2Uris[1].Path)
+	assert.Equal(t, "/api/v1/product", v1Objs[0].Path)
+	assert.Equal(t, "v1", v1Objs[0].Version)
+	assert.Equal(t, "/api/v2/product", v2Objs[0].Path)
+	assert.Equal(t, "/ping", uriDocs[0].Path)
+
+	client := carrot.NewTestClient(r)
+	var pong string
+	err = client.CallGet("/api/v2/ping", nil, &pong)
+	assert.Nil(t, err)
+	assert.Equal(t, "pong", pong)
+
+	var obj product
+	err = client.CallGet("/api/v1/product/1", nil, &obj)
+	assert.Nil(t, err)
+	assert.Equal(t, "demo", obj.Name)
+
+	_, _, err = RegisterVersion(r.Group("/api"), "", nil, nil)
+	assert.NotNil(t, err)
+
+	// the docs of both versions are rendered together without duplicate names
+	uris := append(v1Uris, v2Uris...)
+	objs := append(v1Objs, v2Objs...)
+	doc := GetOpenAPIDocument("demo", "1.0", uris, objs)
+	assert.Contains(t, doc.Components.Schemas, "ProductV1")
+	assert.Contains(t, doc.Components.Schemas, "ProductV2QueryResult")
+	assert.Equal(t, "getProductV1", doc.Paths["/api/v1/product/{id}"].Get.OperationID)
+	assert.Equal(t, "getProductV2", doc.Paths["/api/v2/product/{id}"].Get.OperationID)
+	ids := map[string]bool{}
+	for _, item := range doc.Paths {
+		for _, op := range []*Operation{item.Get, item.Put, item.Post, item.Patch, item.Delete} {
+			if op != nil {
+				assert.False(t, ids[op.OperationID], op.OperationID)
+				ids[op.OperationID] = true
+			}
+		}
+	}
+	assert.Equal(t, "postEchoV2", uriOperationID(UriDoc{Path: "/echo", Method: http.MethodPost, Version: "v2"}))
+
+	code := GenerateTypeScript(uris, objs)
+	exports := map[string]bool{}
+	for _, line := range strings.Split(code, "\n") {
+		if fields := strings.Fields(line); len(fields) > 2 && fields[0] == "export" {
+			name := strings.FieldsFunc(fields[2], func(r rune) bool { return r == '(' || r == '<' })[0]
+			assert.False(t, exports[name], name)
+			exports[name] = true
+		}
+	}
+	assert.True(t, exports["getProductV1"])
+	assert.True(t, exports["ProductV2"])
+}
@@ -0,0 +1,271 @@
+package carrot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+var ErrInvalidAssociation = errors.New("invalid association")
+var ErrAssociationNotFound = errors.New("associated object not found")
+
+// isMany2ManyField return true if the field is a gorm many2many association, such as:
+//
+//	Tags []Tag `gorm:"many2many:article_tags"`
+func isMany2ManyField(f reflect.StructField) bool {
+	if f.Type.Kind() != reflect.Slice {
+		return false
+	}
+	return strings.Contains(strings.ToLower(f.Tag.Get("gorm")), "many2many:")
+}
+
+// many2manyRelation return the many2many relationship of the field name of model.
+func many2manyRelation(db *gorm.DB, model any, name string) (*schema.Relationship, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return nil, err
+	}
+	rel, ok := stmt.Schema.Relationships.Relations[name]
+	if !ok || rel.Type != schema.Many2Many {
+		return nil, fmt.Errorf("%w: %s is not a many2many field of %s", ErrInvalidAssociation, name, stmt.Schema.Name)
+	}
+	if rel.FieldSchema.PrioritizedPrimaryField == nil {
+		return nil, fmt.Errorf("%w: %s not has primaryKey", ErrInvalidAssociation, rel.FieldSchema.Name)
+	}
+	return rel, nil
+}
+
+// loadAssociations load the associated objects of the field name by the primary keys, all keys must exist.
+// Return the pointer of the slice of the objects.
+func loadAssociations(db *gorm.DB, model any, name string, keys []any) (reflect.Value, error) {
+	rel, err := many2manyRelation(db, model, name)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	targets := reflect.New(reflect.SliceOf(rel.FieldSchema.ModelType))
+	if len(keys) == 0 {
+		return targets, nil
+	}
+
+	uniques := map[string]bool{}
+	for _, k := range keys {
+		uniques[fmt.Sprintf("%v", k)] = true
+	}
+	pk := rel.FieldSchema.PrioritizedPrimaryField
+	if err := db.Where(fmt.Sprintf("%s IN ?", pk.DBName), keys).Find(targets.Interface()).Error; err != nil {
+		return reflect.Value{}, err
+	}
+	if targets.Elem().Len() != len(uniques) {
+		return reflect.Value{}, fmt.Errorf("%w: %s", ErrAssociationNotFound, name)
+	}
+	return targets, nil
+}
+
+// associationKeys return the primary keys of the objects as a set of text.
+func associationKeys(rel *schema.Relationship, objs reflect.Value) map[string]bool {
+	keys := map[string]bool{}
+	for i := 0; i < objs.Len(); i++ {
+		v, _ := rel.FieldSchema.PrioritizedPrimaryField.ValueOf(context.Background(), reflect.Indirect(objs.Index(i)))
+		keys[fmt.Sprintf("%v", v)] = true
+	}
+	return keys
+}
+
+// replaceAssociations replace the associated objects of the field name by the primary keys,
+// return true if the associations are changed.
+func replaceAssociations(db *gorm.DB, val any, name string, keys []any) (bool, error) {
+	targets, err := loadAssociations(db, val, name, keys)
+	if err != nil {
+		return false, err
+	}
+	rel, _ := many2manyRelation(db, val, name)
+	olds := reflect.New(reflect.SliceOf(rel.FieldSchema.ModelType))
+	if err := db.Model(val).Association(name).Find(olds.Interface()); err != nil {
+		return false, err
+	}
+	if reflect.DeepEqual(associationKeys(rel, olds.Elem()), associationKeys(rel, targets.Elem())) {
+		return false, nil
+	}
+
+	association := db.Model(val).Association(name)
+	if targets.Elem().Len() == 0 {
+		err = association.Clear()
+	} else {
+		err = association.Replace(targets.Interface())
+	}
+	return err == nil, err
+}
+
+// popMany2Many remove the values of the many2many fields from vals, the values are the primary keys
+// of the associated objects, such as [1, 2] or [{"value": 1}, {"value": 2}].
+// The fields not in Editables are ignored, the same as UnmarshalFrom.
+func (obj *AdminObject) popMany2Many(vals map[string]any) (map[string][]any, error) {
+	editables := make(map[string]bool)
+	for _, v := range obj.Editables {
+		editables[v] = true
+	}
+	values := map[string][]any{}
+	for _, field := range obj.Fields {
+		if field.Foreign == nil || !field.Foreign.Many2Many {
+			continue
+		}
+		v, ok := vals[field.Name]
+		if !ok {
+			continue
+		}
+		delete(vals, field.Name)
+		if len(editables) > 0 && !editables[field.Name] {
+			continue
+		}
+
+		items, ok := v.([]any)
+		if !ok && v != nil {
+			return nil, fmt.Errorf("%w: %s must be a list", ErrInvalidAssociation, field.Name)
+		}
+		keys := make([]any, 0, len(items))
+		for _, item := range items {
+			if m, ok := item.(map[string]any); ok {
+				item = m["value"]
+			}
+			if item == nil {
+				return nil, fmt.Errorf("%w: %s has empty value", ErrInvalidAssociation, field.Name)
+			}
+			keys = append(keys, item)
+		}
+		values[field.Name] = keys
+	}
+	return values, nil
+}
+
+// saveMany2Many replace the associations of val, return the changed fields.
+func (obj *AdminObject) saveMany2Many(tx *gorm.DB, val any, values map[string][]any) ([]string, error) {
+	var changed []string
+	for _, field := range obj.Fields {
+		keys, ok := values[field.Name]
+		if !ok {
+			continue
+		}
+		ok, err := replaceAssociations(tx, val, field.Foreign.fieldName, keys)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			changed = append(changed, field.Name)
+		}
+	}
+	return changed, nil
+}
+
+// marshalMany2Many render the associated objects as AdminValue, the label is String() of the object.
+func (f *AdminForeign) marshalMany2Many(objs reflect.Value) []AdminValue {
+	items := make([]AdminValue, 0, objs.Len())
+	for i := 0; i < objs.Len(); i++ {
+		elm := reflect.Indirect(objs.Index(i))
+		v := AdminValue{Value: elm.FieldByName(f.primaryKey).Interface()}
+		if sv, ok := elm.Interface().(fmt.Stringer); ok {
+			v.Label = sv.String()
+		} else {
+			v.Label = fmt.Sprintf("%v", v.Value)
+		}
+		items = append(items, v)
+	}
+	return items
+}
+
+// registerAssociations registers the endpoints of the Associations:
+//
+//   - PUT /{name}/{primary keys}/{association} -> Add the objects by the primary keys, such as [1, 2]
+//   - DELETE /{name}/{primary keys}/{association} -> Remove the objects by the primary keys
+func (obj *WebObject) registerAssociations(r *gin.RouterGroup, primaryKeyPath string) {
+	for _, name := range obj.Associations {
+		name := name
+		p := name
+		for k, v := range obj.jsonToFields {
+			if v == name {
+				p = k
+				break
+			}
+		}
+		r.PUT(filepath.Join(primaryKeyPath, p), func(c *gin.Context) {
+			handleAssociationObject(c, obj, name, true)
+		})
+		r.DELETE(filepath.Join(primaryKeyPath, p), func(c *gin.Context) {
+			handleAssociationObject(c, obj, name, false)
+		})
+	}
+}
+
+// checkAssociations check the Associations are many2many fields of the model.
+func (obj *WebObject) checkAssociations() error {
+	for _, name := range obj.Associations {
+		f, ok := obj.modelElem.FieldByName(name)
+		if !ok || !isMany2ManyField(f) {
+			return fmt.Errorf("%w: %s is not a many2many field of %s", ErrInvalidAssociation, name, obj.Name)
+		}
+	}
+	return nil
+}
+
+func handleAssociationObject(c *gin.Context, obj *WebObject, name string, add bool) {
+	keys, err := obj.getPrimaryValues(c)
+	if err != nil {
+		AbortWithJSONError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	var ids []any
+	if err := c.BindJSON(&ids); err != nil {
+		AbortWithJSONError(c, http.StatusBadRequest, err)
+		return
+	}
+	if len(ids) == 0 {
+		AbortWithJSONError(c, http.StatusBadRequest, ErrNotChanged)
+		return
+	}
+
+	db := getDbConnection(c, obj.GetDB, false)
+	val := reflect.New(obj.modelElem).Interface()
+	result := obj.buildPrimaryCondition(db, keys).Session(&gorm.Session{}).Take(val)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			AbortWithJSONError(c, http.StatusNotFound, ErrNotFound)
+		} else {
+			AbortWithJSONError(c, http.StatusInternalServerError, result.Error)
+		}
+		return
+	}
+
+	if !obj.checkAccess(c, db, val, PermissionUpdate) {
+		return
+	}
+
+	targets, err := loadAssociations(db, val, name, ids)
+	if err != nil {
+		if errors.Is(err, ErrAssociationNotFound) {
+			AbortWithJSONError(c, http.StatusBadRequest, err)
+		} else {
+			AbortWithJSONError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	association := db.Model(val).Association(name)
+	if add {
+		err = association.Append(targets.Interface())
+	} else {
+		err = association.Delete(targets.Interface())
+	}
+	if err != nil {
+		AbortWithJSONError(c, http.StatusInternalServerError, err)
+		return
+	}
+	RenderJSON(c, http.StatusOK, true)
+}
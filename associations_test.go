@@ -0,0 +1,151 @@
+package carrot
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type testTag struct {
+	ID   uint   `json:"id" gorm:"primaryKey"`
+	Name string `json:"name" gorm:"size:40"`
+}
+
+func (t testTag) String() string {
+	return t.Name
+}
+
+type testArticle struct {
+	ID    uint      `json:"id" gorm:"primaryKey"`
+	Title string    `json:"title" gorm:"size:100"`
+	Tags  []testTag `json:"tags" gorm:"many2many:test_article_tags"`
+}
+
+func articleTags(db *gorm.DB, id uint) []string {
+	var article testArticle
+	db.Preload("Tags").First(&article, id)
+	var names []string
+	for _, tag := range article.Tags {
+		names = append(names, tag.Name)
+	}
+	return names
+}
+
+func TestAdminMany2Many(t *testing.T) {
+	r := gin.Default()
+	db, _ := InitDatabase(nil, "", "")
+	InitCarrot(db, r)
+	MakeMigrates(db, []any{&testTag{}, &testArticle{}})
+	db.Create(&[]testTag{{Name: "go"}, {Name: "web"}})
+
+	objs := append(GetCarrotAdminObjects(),
+		AdminObject{Model: &testArticle{}, Name: "Article"},
+		AdminObject{Model: &testTag{}, Name: "TestTag"},
+	)
+	RegisterAdmins(r.Group("/admin"), db, objs)
+	client := NewTestClient(r)
+	authClient(db, client, "bob@restsend.com", "--", true)
+
+	{
+		obj := AdminObject{Model: &testArticle{}, Name: "Article"}
+		assert.Nil(t, obj.Build(db))
+		field := obj.Fields[2]
+		assert.Equal(t, "tags", field.Name)
+		assert.True(t, field.NotColumn)
+		assert.True(t, field.Foreign.Many2Many)
+		assert.Equal(t, "testtag", field.Foreign.Path)
+	}
+	{
+		// the many2many fields not in Editables are ignored
+		obj := AdminObject{Model: &testArticle{}, Name: "Article", Editables: []string{"Title"}}
+		assert.Nil(t, obj.Build(db))
+		vals := map[string]any{"title": "mock", "tags": []any{float64(1)}}
+		values, err := obj.popMany2Many(vals)
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(values))
+		assert.Equal(t, map[string]any{"title": "mock"}, vals)
+
+		obj = AdminObject{Model: &testArticle{}, Name: "Article", Editables: []string{"Title", "Tags"}}
+		assert.Nil(t, obj.Build(db))
+		values, err = obj.popMany2Many(map[string]any{"tags": []any{float64(1)}})
+		assert.Nil(t, err)
+		assert.Equal(t, []any{float64(1)}, values["tags"])
+	}
+
+	err := client.CallPut("/admin/article/", gin.H{"title": "hello", "tags": []uint{1, 2}}, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"go", "web"}, articleTags(db, 1))
+
+	var item map[string]any
+	err = client.CallPost("/admin/article/?id=1", nil, &item)
+	assert.Nil(t, err)
+	tags := item["tags"].([]any)
+	assert.Equal(t, 2, len(tags))
+	assert.Equal(t, map[string]any{"value": float64(1), "label": "go"}, tags[0])
+
+	err = client.CallPatch("/admin/article/?id=1", gin.H{"title": "hello", "tags": []gin.H{{"value": 2}}}, &item)
+	assert.Nil(t, err)
+	assert.Equal(t, []any{map[string]any{"value": float64(2), "label": "web"}}, item["tags"])
+	assert.Equal(t, []string{"web"}, articleTags(db, 1))
+
+	var log AdminLog
+	db.Where("object_type", "test_articles").Where("action", AdminLogUpdate).Last(&log)
+	assert.Equal(t, "tags", log.Fields)
+
+	// the title is not changed if the tags are invalid
+	err = client.CallPatch("/admin/article/?id=1", gin.H{"title": "mock", "tags": []uint{1, 99}}, nil)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), ErrAssociationNotFound.Error())
+	var article testArticle
+	db.First(&article, 1)
+	assert.Equal(t, "hello", article.Title)
+	assert.Equal(t, []string{"web"}, articleTags(db, 1))
+
+	err = client.CallPatch("/admin/article/?id=1", gin.H{"tags": []uint{}}, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(articleTags(db, 1)))
+}
+
+func TestObjectAssociations(t *testing.T) {
+	db, _ := gorm.Open(sqlite.Open("file::memory:"), nil)
+	db.AutoMigrate(&testTag{}, &testArticle{})
+	db.Create(&[]testTag{{Name: "go"}, {Name: "web"}})
+	db.Create(&testArticle{Title: "hello"})
+
+	r := gin.Default()
+	r.Use(WithGormDB(db))
+	obj := WebObject{
+		Model:        testArticle{},
+		Name:         "article",
+		Associations: []string{"Tags"},
+	}
+	assert.Nil(t, obj.RegisterObject(&r.RouterGroup))
+
+	send := func(method, path, body string) int {
+		req := httptest.NewRequest(method, path, bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, send(http.MethodPut, "/article/1/tags", "[1,2]"))
+	assert.Equal(t, []string{"go", "web"}, articleTags(db, 1))
+
+	assert.Equal(t, http.StatusOK, send(http.MethodDelete, "/article/1/tags", "[1]"))
+	assert.Equal(t, []string{"web"}, articleTags(db, 1))
+
+	assert.Equal(t, http.StatusBadRequest, send(http.MethodPut, "/article/1/tags", "[1,99]"))
+	assert.Equal(t, []string{"web"}, articleTags(db, 1))
+	assert.Equal(t, http.StatusBadRequest, send(http.MethodPut, "/article/1/tags", "[]"))
+	assert.Equal(t, http.StatusNotFound, send(http.MethodPut, "/article/9/tags", "[1]"))
+
+	invalid := WebObject{Model: testArticle{}, Name: "invalid", Associations: []string{"Title"}}
+	assert.ErrorIs(t, invalid.RegisterObject(&r.RouterGroup), ErrInvalidAssociation)
+}
@@ -1,6 +1,8 @@
 package carrot
 
 import (
+	"database/sql"
+	"errors"
 	"io"
 	"log"
 	"os"
@@ -50,3 +52,30 @@ func MakeMigrates(db *gorm.DB, insts []any) error {
 	}
 	return nil
 }
+
+var ErrEstimateNotSupported = errors.New("estimate count not supported")
+
+// EstimateCount return the estimated rows of table from the dialect statistics,
+// pg uses pg_class.reltuples, mysql uses information_schema.TABLES.
+// ErrEstimateNotSupported is returned for other dialects or tables without statistics.
+func EstimateCount(db *gorm.DB, table string) (int64, error) {
+	var query string
+	switch db.Dialector.Name() {
+	case "postgres":
+		query = "SELECT CAST(reltuples AS BIGINT) FROM pg_class WHERE oid = to_regclass(?)"
+	case "mysql":
+		query = "SELECT TABLE_ROWS FROM information_schema.TABLES WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?"
+	default:
+		return 0, ErrEstimateNotSupported
+	}
+
+	var c sql.NullInt64
+	if err := db.Session(&gorm.Session{NewDB: true}).Raw(query, table).Scan(&c).Error; err != nil {
+		return 0, err
+	}
+	// reltuples is -1 if the table has never been analyzed
+	if !c.Valid || c.Int64 < 0 {
+		return 0, ErrEstimateNotSupported
+	}
+	return c.Int64, nil
+}
@@ -0,0 +1,284 @@
+package carrot
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+const (
+	DefaultDecimalPrecision = 20
+	DefaultDecimalScale     = 4
+	MaxDecimalExponent      = 1000 // the exponent and scale of the parsed string are limited
+)
+
+var ErrInvalidDecimal = errors.New("invalid decimal")
+
+var decimalType = reflect.TypeOf(Decimal{})
+
+// Decimal is an exact decimal number for money amounts, such as:
+//
+//	Price Decimal `json:"price" gorm:"precision:12;scale:2"`
+//
+// It is encoded as string in JSON ("12.30"), and stored as decimal(precision,scale) in the database.
+type Decimal struct {
+	coef *big.Int // nil is zero
+	exp  int      // value is coef * 10^-exp
+}
+
+// NewDecimal return value * 10^-exp, such as NewDecimal(1230, 2) is 12.30.
+func NewDecimal(value int64, exp int) Decimal {
+	if exp < 0 {
+		return Decimal{coef: new(big.Int).Mul(big.NewInt(value), pow10(-exp))}
+	}
+	return Decimal{coef: big.NewInt(value), exp: exp}
+}
+
+// ParseDecimal parse the decimal string, such as "12.30", "-0.5" and "1e3".
+func ParseDecimal(s string) (Decimal, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Decimal{}, ErrInvalidDecimal
+	}
+	mantissa, exponent := s, 0
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		e, err := strconv.Atoi(s[i+1:])
+		if err != nil {
+			return Decimal{}, fmt.Errorf("%w: %s", ErrInvalidDecimal, s)
+		}
+		if e > MaxDecimalExponent || e < -MaxDecimalExponent {
+			return Decimal{}, fmt.Errorf("%w: exponent out of range %s", ErrInvalidDecimal, s)
+		}
+		mantissa, exponent = s[:i], e
+	}
+
+	exp := 0
+	if intPart, fracPart, ok := strings.Cut(mantissa, "."); ok {
+		mantissa = intPart + fracPart
+		exp = len(fracPart)
+	}
+	if mantissa == "" || mantissa == "-" || mantissa == "+" {
+		return Decimal{}, fmt.Errorf("%w: %s", ErrInvalidDecimal, s)
+	}
+	for i, c := range mantissa {
+		if (c < '0' || c > '9') && !(i == 0 && (c == '-' || c == '+')) {
+			return Decimal{}, fmt.Errorf("%w: %s", ErrInvalidDecimal, s)
+		}
+	}
+	coef, ok := new(big.Int).SetString(mantissa, 10)
+	if !ok {
+		return Decimal{}, fmt.Errorf("%w: %s", ErrInvalidDecimal, s)
+	}
+	d := Decimal{coef: coef, exp: exp - exponent}
+	if d.exp > MaxDecimalExponent || d.exp < -MaxDecimalExponent {
+		return Decimal{}, fmt.Errorf("%w: exponent out of range %s", ErrInvalidDecimal, s)
+	}
+	if d.exp < 0 {
+		d.coef.Mul(d.coef, pow10(-d.exp))
+		d.exp = 0
+	}
+	return d, nil
+}
+
+// MustParseDecimal is like ParseDecimal but panics if s is invalid.
+func MustParseDecimal(s string) Decimal {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// AsDecimal convert the json values (string or number), integers and Decimal to Decimal.
+func AsDecimal(v any) (Decimal, error) {
+	switch val := v.(type) {
+	case Decimal:
+		return val, nil
+	case *Decimal:
+		if val == nil {
+			return Decimal{}, ErrInvalidDecimal
+		}
+		return *val, nil
+	case string:
+		return ParseDecimal(val)
+	case []byte:
+		return ParseDecimal(string(val))
+	case json.Number:
+		return ParseDecimal(val.String())
+	case float64:
+		return ParseDecimal(strconv.FormatFloat(val, 'f', -1, 64))
+	case float32:
+		return ParseDecimal(strconv.FormatFloat(float64(val), 'f', -1, 32))
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return ParseDecimal(fmt.Sprintf("%d", val))
+	}
+	return Decimal{}, fmt.Errorf("%w: %v", ErrInvalidDecimal, v)
+}
+
+// asDecimalValues convert the filter value to Decimal, array values are converted one by one.
+func asDecimalValues(v any) (any, error) {
+	items, ok := v.([]any)
+	if !ok {
+		return AsDecimal(v)
+	}
+	vals := make([]any, 0, len(items))
+	for _, item := range items {
+		d, err := AsDecimal(item)
+		if err != nil {
+			return nil, err
+		}
+		vals = append(vals, d)
+	}
+	return vals, nil
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+func (d Decimal) coefficient() *big.Int {
+	if d.coef == nil {
+		return new(big.Int)
+	}
+	return d.coef
+}
+
+// rescale return the coefficient of d with exp.
+func (d Decimal) rescale(exp int) *big.Int {
+	c := new(big.Int).Set(d.coefficient())
+	if exp > d.exp {
+		c.Mul(c, pow10(exp-d.exp))
+	}
+	return c
+}
+
+func (d Decimal) IsZero() bool {
+	return d.coefficient().Sign() == 0
+}
+
+func (d Decimal) Sign() int {
+	return d.coefficient().Sign()
+}
+
+// Cmp return -1, 0 or 1 if d is less than, equal to or greater than o.
+func (d Decimal) Cmp(o Decimal) int {
+	exp := max(d.exp, o.exp)
+	return d.rescale(exp).Cmp(o.rescale(exp))
+}
+
+func (d Decimal) Equal(o Decimal) bool {
+	return d.Cmp(o) == 0
+}
+
+func (d Decimal) Add(o Decimal) Decimal {
+	exp := max(d.exp, o.exp)
+	return Decimal{coef: new(big.Int).Add(d.rescale(exp), o.rescale(exp)), exp: exp}
+}
+
+func (d Decimal) Sub(o Decimal) Decimal {
+	exp := max(d.exp, o.exp)
+	return Decimal{coef: new(big.Int).Sub(d.rescale(exp), o.rescale(exp)), exp: exp}
+}
+
+func (d Decimal) Mul(o Decimal) Decimal {
+	return Decimal{coef: new(big.Int).Mul(d.coefficient(), o.coefficient()), exp: d.exp + o.exp}
+}
+
+// Round round half away from zero to places decimal places.
+func (d Decimal) Round(places int) Decimal {
+	if places < 0 {
+		places = 0
+	}
+	if d.exp <= places {
+		return Decimal{coef: d.rescale(places), exp: places}
+	}
+	div := pow10(d.exp - places)
+	q, r := new(big.Int).QuoRem(d.coefficient(), div, new(big.Int))
+	if new(big.Int).Mul(new(big.Int).Abs(r), big.NewInt(2)).Cmp(div) >= 0 {
+		q.Add(q, big.NewInt(int64(d.Sign())))
+	}
+	return Decimal{coef: q, exp: places}
+}
+
+// Float64 return the nearest float64 value, it may lose precision.
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// String return the decimal string with the scale of d, such as "12.30".
+func (d Decimal) String() string {
+	s := new(big.Int).Abs(d.coefficient()).String()
+	if d.exp > 0 {
+		if len(s) <= d.exp {
+			s = strings.Repeat("0", d.exp-len(s)+1) + s
+		}
+		s = s[:len(s)-d.exp] + "." + s[len(s)-d.exp:]
+	}
+	if d.Sign() < 0 {
+		s = "-" + s
+	}
+	return s
+}
+
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + d.String() + `"`), nil
+}
+
+// UnmarshalJSON accept both string and number, such as "12.30" and 12.30.
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		s = unquoted
+	}
+	v, err := ParseDecimal(s)
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+func (d *Decimal) Scan(value any) error {
+	if value == nil {
+		*d = Decimal{}
+		return nil
+	}
+	v, err := AsDecimal(value)
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+func (d Decimal) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+func (Decimal) GormDataType() string {
+	return "decimal"
+}
+
+// GormDBDataType return decimal(precision,scale) from the gorm tag, default is decimal(20,4).
+func (Decimal) GormDBDataType(db *gorm.DB, field *schema.Field) string {
+	precision, scale := field.Precision, field.Scale
+	if precision <= 0 {
+		precision = DefaultDecimalPrecision
+	}
+	if scale <= 0 && !strings.Contains(strings.ToLower(field.Tag.Get("gorm")), "scale:") {
+		scale = DefaultDecimalScale
+	}
+	return fmt.Sprintf("decimal(%d,%d)", precision, scale)
+}
@@ -0,0 +1,154 @@
+package carrot
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type decimalProduct struct {
+	ID       uint     `json:"id" gorm:"primarykey"`
+	Name     string   `json:"name" gorm:"size:40"`
+	Price    Decimal  `json:"price" gorm:"precision:12;scale:2"`
+	Discount *Decimal `json:"discount"`
+}
+
+func TestParseDecimal(t *testing.T) {
+	for s, expect := range map[string]string{
+		"12.30":  "12.30",
+		"-0.5":   "-0.5",
+		"+7":     "7",
+		".25":    "0.25",
+		"1e3":    "1000",
+		"1.5E-3": "0.0015",
+		"0.00":   "0.00",
+		"123456789012345678901234567890.123456789": "123456789012345678901234567890.123456789",
+	} {
+		d, err := ParseDecimal(s)
+		assert.Nil(t, err, s)
+		assert.Equal(t, expect, d.String(), s)
+	}
+	for _, s := range []string{"", "-", "abc", "1.2.3", "1e", "0x10", "1,5",
+		"1e1000000000", "1e-1000000000", "1.5e-1000", "0." + strings.Repeat("0", 1000) + "1"} {
+		_, err := ParseDecimal(s)
+		assert.ErrorIs(t, err, ErrInvalidDecimal, s)
+	}
+	large, err := ParseDecimal("1e1000")
+	assert.Nil(t, err)
+	assert.Len(t, large.String(), 1001)
+
+	a := MustParseDecimal("0.1")
+	b := MustParseDecimal("0.2")
+	assert.Equal(t, "0.3", a.Add(b).String())
+	assert.Equal(t, "-0.1", a.Sub(b).String())
+	assert.Equal(t, "0.02", a.Mul(b).String())
+	assert.Equal(t, -1, a.Cmp(b))
+	assert.True(t, MustParseDecimal("0.30").Equal(a.Add(b)))
+	assert.Equal(t, "12.35", MustParseDecimal("12.345").Round(2).String())
+	assert.Equal(t, "-12.35", MustParseDecimal("-12.345").Round(2).String())
+	assert.Equal(t, "12.00", NewDecimal(12, 0).Round(2).String())
+	assert.Equal(t, "12.30", NewDecimal(1230, 2).String())
+	assert.Equal(t, "1200", NewDecimal(12, -2).String())
+	assert.True(t, Decimal{}.IsZero())
+	assert.Equal(t, "0", Decimal{}.String())
+
+	d, err := AsDecimal(float64(19.99))
+	assert.Nil(t, err)
+	assert.Equal(t, "19.99", d.String())
+	_, err = AsDecimal(true)
+	assert.ErrorIs(t, err, ErrInvalidDecimal)
+
+	var p decimalProduct
+	err = json.Unmarshal([]byte(`{"price":"19.90","discount":1.5}`), &p)
+	assert.Nil(t, err)
+	assert.Equal(t, "19.90", p.Price.String())
+	assert.Equal(t, "1.5", p.Discount.String())
+	data, _ := json.Marshal(p)
+	assert.Contains(t, string(data), `"price":"19.90","discount":"1.5"`)
+}
+
+func TestWebObjectDecimal(t *testing.T) {
+	db, _ := InitDatabase(nil, "", "")
+	err := db.AutoMigrate(decimalProduct{})
+	assert.Nil(t, err)
+
+	r := gin.New()
+	r.Use(WithGormDB(db))
+	webobject := WebObject{
+		Model:       decimalProduct{},
+		Name:        "product",
+		Editables:   []string{"Price", "Discount"},
+		Filterables: []string{"Price"},
+	}
+	err = webobject.RegisterObject(&r.RouterGroup)
+	assert.Nil(t, err)
+	client := NewTestClient(r)
+
+	var product decimalProduct
+	err = client.CallPut("/product", gin.H{"name": "pen", "price": "19.99"}, &product)
+	assert.Nil(t, err)
+	assert.Equal(t, "19.99", product.Price.String())
+	assert.Nil(t, product.Discount)
+
+	w := client.Post(http.MethodPut, "/product", []byte(`{"name":"bad","price":"19,99"}`))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	err = client.CallPatch("/product/1", gin.H{"price": "29.95", "discount": 5}, nil)
+	assert.Nil(t, err)
+	w = client.Post(http.MethodPatch, "/product/1", []byte(`{"price":"free"}`))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), ErrInvalidDecimal.Error())
+
+	err = client.CallGet("/product/1", nil, &product)
+	assert.Nil(t, err)
+	assert.True(t, MustParseDecimal("29.95").Equal(product.Price))
+	assert.True(t, NewDecimal(5, 0).Equal(*product.Discount))
+
+	var result QueryResult
+	err = client.CallPost("/product", gin.H{"filters": []Filter{{Name: "price", Op: FilterOpGreaterOrEqual, Value: "29.95"}}}, &result)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, result.TotalCount)
+
+	w = client.Post(http.MethodPost, "/product", []byte(`{"filters":[{"name":"price","op":"in","value":["1.0","x"]}]}`))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestAdminObjectDecimal(t *testing.T) {
+	db, _ := InitDatabase(nil, "", "")
+	db.AutoMigrate(decimalProduct{})
+
+	obj := AdminObject{
+		Model:     &decimalProduct{},
+		Name:      "Product",
+		Editables: []string{"Name", "Price", "Discount"},
+	}
+	err := obj.Build(db)
+	assert.Nil(t, err)
+	for _, f := range obj.Fields {
+		if f.Name == "price" || f.Name == "discount" {
+			assert.Equal(t, "decimal", f.Type)
+		}
+	}
+
+	val, err := convertValue(decimalType, "12.50", false)
+	assert.Nil(t, err)
+	assert.Equal(t, "12.50", val.(*Decimal).String())
+	_, err = convertValue(decimalType, "x", false)
+	assert.NotNil(t, err)
+
+	elem, err := obj.UnmarshalFrom(reflect.New(obj.modelElem), nil, map[string]any{"name": "pen", "price": "12.50", "discount": "1.25"})
+	assert.Nil(t, err)
+	p := elem.(*decimalProduct)
+	assert.Equal(t, "12.50", p.Price.String())
+	assert.Equal(t, "1.25", p.Discount.String())
+
+	data, err := obj.MarshalOne(nil, p)
+	assert.Nil(t, err)
+	out, _ := json.Marshal(data)
+	assert.Contains(t, string(out), `"price":"12.50"`)
+}
@@ -0,0 +1,136 @@
+package carrot
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+const (
+	EnumGroupRole = "group_role"
+)
+
+var ErrInvalidEnumValue = errors.New("invalid enum value")
+var ErrEnumNotRegistered = errors.New("enum not registered")
+
+type EnumValue struct {
+	Value any    `json:"value"`
+	Label string `json:"label"`
+}
+
+// Enum declares the allowed values and labels of a field once, admin, WebObject and apidocs share it.
+type Enum struct {
+	Name   string      `json:"name"`
+	Values []EnumValue `json:"values"`
+}
+
+var enumsLock sync.RWMutex
+var enumsByName = map[string]*Enum{}
+var enumsByType = map[reflect.Type]*Enum{}
+
+func init() {
+	RegisterEnum(EnumGroupRole, EnumValue{GroupRoleAdmin, "Admin"}, EnumValue{GroupRoleMember, "Member"})
+}
+
+// RegisterEnum register the values by name, used by the fields tagged as:
+//
+//	Role string `carrot:"enum=group_role"`
+func RegisterEnum(name string, values ...EnumValue) *Enum {
+	e := &Enum{Name: name, Values: values}
+	enumsLock.Lock()
+	defer enumsLock.Unlock()
+	enumsByName[name] = e
+	return e
+}
+
+// RegisterEnumType register the values of type T, the fields of type T use them without tag, such as:
+//
+//	type OrderStatus string
+//	RegisterEnumType[OrderStatus]("order_status", EnumValue{OrderStatus("paid"), "Paid"})
+func RegisterEnumType[T any](name string, values ...EnumValue) *Enum {
+	e := RegisterEnum(name, values...)
+	enumsLock.Lock()
+	defer enumsLock.Unlock()
+	enumsByType[reflect.TypeOf((*T)(nil)).Elem()] = e
+	return e
+}
+
+// GetEnum return the enum by name, nil if not registered.
+func GetEnum(name string) *Enum {
+	enumsLock.RLock()
+	defer enumsLock.RUnlock()
+	return enumsByName[name]
+}
+
+// GetFieldEnum return the enum of the struct field, by `carrot:"enum=name"` tag or by the field type.
+func GetFieldEnum(f reflect.StructField) *Enum {
+	if name := parseCarrotTag(f.Tag.Get("carrot")).Get(TagEnum); name != "" {
+		return GetEnum(name)
+	}
+	rt := f.Type
+	for rt.Kind() == reflect.Ptr || rt.Kind() == reflect.Slice {
+		rt = rt.Elem()
+	}
+	enumsLock.RLock()
+	defer enumsLock.RUnlock()
+	return enumsByType[rt]
+}
+
+// lookupFieldEnum is like GetFieldEnum, and return ErrEnumNotRegistered if the enum of the tag is not registered.
+func lookupFieldEnum(f reflect.StructField) (*Enum, error) {
+	if name := parseCarrotTag(f.Tag.Get("carrot")).Get(TagEnum); name != "" {
+		if e := GetEnum(name); e != nil {
+			return e, nil
+		}
+		return nil, fmt.Errorf("%w: %s of field %s", ErrEnumNotRegistered, name, f.Name)
+	}
+	return GetFieldEnum(f), nil
+}
+
+// Contains check the value, json numbers are matched with the integer values, such as 1.0 and 1.
+func (e *Enum) Contains(value any) bool {
+	s := fmt.Sprint(value)
+	for _, v := range e.Values {
+		if fmt.Sprint(v.Value) == s {
+			return true
+		}
+	}
+	return false
+}
+
+// GetLabel return the label of value, empty if not found.
+func (e *Enum) GetLabel(value any) string {
+	s := fmt.Sprint(value)
+	for _, v := range e.Values {
+		if fmt.Sprint(v.Value) == s {
+			return v.Label
+		}
+	}
+	return ""
+}
+
+// Check return ErrInvalidEnumValue if value or any item of the array value is not allowed.
+func (e *Enum) Check(value any) error {
+	if items, ok := value.([]any); ok {
+		for _, v := range items {
+			if err := e.Check(v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if !e.Contains(value) {
+		return fmt.Errorf("%w: %v", ErrInvalidEnumValue, value)
+	}
+	return nil
+}
+
+// Choices return the admin select options.
+func (e *Enum) Choices() []AdminSelectOption {
+	var choices []AdminSelectOption
+	for _, v := range e.Values {
+		choices = append(choices, AdminSelectOption{Label: v.Label, Value: v.Value})
+	}
+	return choices
+}
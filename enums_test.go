@@ -0,0 +1,140 @@
+package carrot
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type enumPriority int
+
+type enumTask struct {
+	ID       uint         `json:"id" gorm:"primarykey"`
+	Status   string       `json:"status" gorm:"size:20" carrot:"filter,enum=task_status"`
+	Priority enumPriority `json:"priority"`
+}
+
+func init() {
+	RegisterEnum("task_status", EnumValue{"todo", "Todo"}, EnumValue{"done", "Done"})
+	RegisterEnumType[enumPriority]("task_priority", EnumValue{enumPriority(1), "Low"}, EnumValue{enumPriority(2), "High"})
+}
+
+func TestEnumRegistry(t *testing.T) {
+	e := GetEnum("task_status")
+	assert.NotNil(t, e)
+	assert.True(t, e.Contains("todo"))
+	assert.False(t, e.Contains("doing"))
+	assert.Equal(t, "Done", e.GetLabel("done"))
+	assert.Nil(t, e.Check([]any{"todo", "done"}))
+	assert.ErrorIs(t, e.Check([]any{"todo", "doing"}), ErrInvalidEnumValue)
+	assert.Nil(t, GetEnum("not_exists"))
+
+	f, _ := reflect.TypeOf(enumTask{}).FieldByName("Priority")
+	p := GetFieldEnum(f)
+	assert.NotNil(t, p)
+	assert.Equal(t, "task_priority", p.Name)
+	assert.True(t, p.Contains(float64(2)))
+	assert.False(t, p.Contains(float64(3)))
+
+	f, _ = reflect.TypeOf(GroupMember{}).FieldByName("Role")
+	assert.Equal(t, EnumGroupRole, GetFieldEnum(f).Name)
+}
+
+func TestUnregisteredEnum(t *testing.T) {
+	type typoTask struct {
+		ID     uint   `json:"id" gorm:"primarykey"`
+		Status string `json:"status" carrot:"enum=task_stauts"`
+	}
+	webobject := WebObject{Model: typoTask{}}
+	err := webobject.Build()
+	assert.ErrorIs(t, err, ErrEnumNotRegistered)
+	assert.Contains(t, err.Error(), "task_stauts")
+
+	db, _ := gorm.Open(sqlite.Open("file::memory:"), nil)
+	obj := AdminObject{Model: &typoTask{}, Name: "Task"}
+	err = obj.Build(db)
+	assert.ErrorIs(t, err, ErrEnumNotRegistered)
+	assert.Contains(t, err.Error(), "task_stauts")
+}
+
+func TestWebObjectEnum(t *testing.T) {
+	db, _ := gorm.Open(sqlite.Open("file::memory:"), nil)
+	db.AutoMigrate(enumTask{})
+
+	r := gin.New()
+	r.Use(WithGormDB(db))
+	webobject := WebObject{
+		Model:        enumTask{},
+		Name:         "task",
+		Editables:    []string{"Status", "Priority"},
+		AllowMethods: CREATE | EDIT | QUERY,
+	}
+	err := webobject.RegisterObject(&r.RouterGroup)
+	assert.Nil(t, err)
+	client := NewTestClient(r)
+
+	w := client.Post(http.MethodPut, "/task", []byte(`{"status":"doing"}`))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), ErrInvalidEnumValue.Error())
+
+	w = client.Post(http.MethodPut, "/task", []byte(`{"status":"todo","priority":3}`))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var task enumTask
+	err = client.CallPut("/task", gin.H{"status": "todo", "priority": 2}, &task)
+	assert.Nil(t, err)
+	assert.Equal(t, "todo", task.Status)
+
+	w = client.Post(http.MethodPatch, "/task/1", []byte(`{"status":"doing"}`))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = client.Post(http.MethodPatch, "/task/1", []byte(`{"status":"done"}`))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = client.Post(http.MethodPost, "/task", []byte(`{"filters":[{"name":"status","op":"in","value":["done","doing"]}]}`))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var result QueryResult
+	err = client.CallPost("/task", gin.H{"filters": []Filter{{Name: "status", Op: FilterOpEqual, Value: "done"}}}, &result)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, result.TotalCount)
+}
+
+func TestAdminObjectEnum(t *testing.T) {
+	db, _ := InitDatabase(nil, "", "")
+	obj := AdminObject{
+		Model: &enumTask{},
+		Name:  "Task",
+		Attributes: map[string]AdminAttribute{
+			"Priority": {Choices: []AdminSelectOption{{"Normal", 1}}},
+		},
+	}
+	err := obj.Build(db)
+	assert.Nil(t, err)
+	for _, f := range obj.Fields {
+		switch f.Name {
+		case "status":
+			assert.Equal(t, []AdminSelectOption{{"Todo", "todo"}, {"Done", "done"}}, f.Attribute.Choices)
+		case "priority":
+			assert.Equal(t, []AdminSelectOption{{"Normal", 1}}, f.Attribute.Choices)
+		}
+	}
+
+	objs := GetCarrotAdminObjects()
+	for i := range objs {
+		if objs[i].Name != "GroupMember" {
+			continue
+		}
+		assert.Nil(t, objs[i].Build(db))
+		for _, f := range objs[i].Fields {
+			if f.Name == "role" {
+				assert.Equal(t, GroupRoleMember, f.Attribute.Default)
+				assert.Equal(t, 2, len(f.Attribute.Choices))
+			}
+		}
+	}
+}
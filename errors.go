@@ -21,6 +21,7 @@ var ErrEmailRequired = errors.New("email required")
 var ErrNotFound = errors.New("not found")
 var ErrNotChanged = errors.New("not changed")
 var ErrInvalidView = errors.New("with invalid view")
+var ErrInvalidQueryItem = errors.New("invalid query item")
 
 var ErrOnlySuperUser = errors.New("only super user can do this")
 var ErrInvalidPrimaryKey = errors.New("invalid primary key")
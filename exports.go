@@ -0,0 +1,317 @@
+package carrot
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	ExportFormatCSV  = "csv"
+	ExportFormatXLSX = "xlsx"
+)
+
+const DefaultExportBatchSize = 500
+
+var ErrInvalidExportFormat = errors.New("invalid export format")
+
+// exportWriter write the rows of the export file.
+type exportWriter interface {
+	WriteRow(values []any) error
+	Close() error
+}
+
+// handleExport export the objects matched by the QueryForm of the list page, orders and keyword are kept,
+// pos and limit are ignored. Only the selected objects are exported if keys is set, such as:
+//
+//	POST /admin/user/_export?format=xlsx&keys=[{"id":1},{"id":2}]
+func (obj *AdminObject) handleExport(c *gin.Context) {
+	if !obj.checkPermission(c, PermissionRead) {
+		return
+	}
+	format := strings.ToLower(c.DefaultQuery("format", ExportFormatCSV))
+	if format != ExportFormatCSV && format != ExportFormatXLSX {
+		AbortWithJSONError(c, http.StatusBadRequest, ErrInvalidExportFormat)
+		return
+	}
+
+	db, form, err := DefaultPrepareQuery(getDbConnection(c, obj.GetDB, false), c)
+	if err != nil {
+		AbortWithJSONError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if v := c.Query("keys"); v != "" {
+		var keys []map[string]any
+		if err := json.Unmarshal([]byte(v), &keys); err != nil {
+			AbortWithJSONError(c, http.StatusBadRequest, err)
+			return
+		}
+		if len(keys) > 0 {
+			cond := db.Session(&gorm.Session{NewDB: true})
+			for _, key := range keys {
+				cond = cond.Or(key)
+			}
+			db = db.Where(cond)
+		}
+	}
+
+	if user := CurrentUser(c); obj.OwnerField != "" && (user == nil || !user.IsSuperUser) {
+		db, err = scopeObjectAccess(db, obj.Model, obj.OwnerField, user)
+		if err != nil {
+			abortWithAccessError(c, err)
+			return
+		}
+	}
+
+	form.Pos = 0
+	form.Limit = DefaultExportBatchSize
+	form.CountMode = CountModeNone
+	c.Set(KeyAdminQueryForm, form)
+
+	// query the first batch before writing, so the errors can be rendered as json
+	r, err := obj.QueryObjects(db, form, c)
+	if err != nil {
+		AbortWithJSONError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	name := strings.ToLower(obj.PluralName)
+	if name == "" {
+		name = strings.ToLower(obj.Name)
+	}
+	filename := fmt.Sprintf("%s-%s.%s", name, time.Now().Format("20060102150405"), format)
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+
+	var w exportWriter
+	if format == ExportFormatXLSX {
+		c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		w, err = newXLSXWriter(c.Writer)
+	} else {
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		w = newCSVWriter(c.Writer)
+	}
+	if err != nil {
+		AbortWithJSONError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.Status(http.StatusOK)
+
+	fields := obj.exportFields()
+	header := make([]any, 0, len(fields))
+	for _, f := range fields {
+		header = append(header, f.Label)
+	}
+	err = w.WriteRow(header)
+	for err == nil {
+		for _, item := range r.Items {
+			row := make([]any, 0, len(fields))
+			for _, f := range fields {
+				row = append(row, item[f.Name])
+			}
+			if err = w.WriteRow(row); err != nil {
+				break
+			}
+		}
+		if err != nil || len(r.Items) < form.Limit {
+			break
+		}
+		form.Pos += form.Limit
+		r, err = obj.QueryObjects(db, form, c)
+	}
+	if err == nil {
+		err = w.Close()
+	}
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"name":   obj.Name,
+			"format": format,
+		}).WithError(err).Warn("admin: export fail")
+	}
+}
+
+// exportFields return the shown fields of obj, all fields if Shows is empty.
+func (obj *AdminObject) exportFields() []AdminField {
+	if len(obj.Shows) == 0 {
+		return obj.Fields
+	}
+	var fields []AdminField
+	for _, name := range obj.Shows {
+		for _, f := range obj.Fields {
+			if f.Name == name {
+				fields = append(fields, f)
+				break
+			}
+		}
+	}
+	return fields
+}
+
+// formatExportValue format the value of MarshalOne as text, foreign keys are rendered as labels.
+func formatExportValue(v any) string {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return ""
+		}
+		v = rv.Elem().Interface()
+	}
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return escapeFormula(val)
+	case AdminValue:
+		return escapeFormula(val.Label)
+	case time.Time:
+		if val.IsZero() {
+			return ""
+		}
+		return val.Format(time.RFC3339)
+	case Decimal:
+		return val.String()
+	case fmt.Stringer:
+		return escapeFormula(val.String())
+	}
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		return string(data)
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// escapeFormula prefix the text with "'" if it starts like a formula, the spreadsheet apps don't evaluate it.
+func escapeFormula(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func newCSVWriter(w io.Writer) *csvWriter {
+	return &csvWriter{w: csv.NewWriter(w)}
+}
+
+func (w *csvWriter) WriteRow(values []any) error {
+	record := make([]string, 0, len(values))
+	for _, v := range values {
+		record = append(record, formatExportValue(v))
+	}
+	return w.w.Write(record)
+}
+
+func (w *csvWriter) Close() error {
+	w.w.Flush()
+	return w.w.Error()
+}
+
+// xlsxWriter write a single sheet workbook, the rows are streamed into the zip file.
+// Numbers are written as number cells, others as inline strings.
+type xlsxWriter struct {
+	zw    *zip.Writer
+	sheet io.Writer
+}
+
+func newXLSXWriter(w io.Writer) (*xlsxWriter, error) {
+	zw := zip.NewWriter(w)
+	sheet, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	_, err = io.WriteString(sheet, xml.Header+`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	if err != nil {
+		return nil, err
+	}
+	return &xlsxWriter{zw: zw, sheet: sheet}, nil
+}
+
+// xlsxMaxInteger is the first integer with more digits than a number cell keeps.
+const xlsxMaxInteger = 1e15
+
+func (w *xlsxWriter) WriteRow(values []any) error {
+	var sb strings.Builder
+	sb.WriteString("<row>")
+	for _, v := range values {
+		rv := reflect.Indirect(reflect.ValueOf(v))
+		switch rv.Kind() {
+		case reflect.Int, reflect.Int64:
+			// the numbers of Excel keep 15 digits, the larger integers are written as text
+			if n := rv.Int(); n > -xlsxMaxInteger && n < xlsxMaxInteger {
+				fmt.Fprintf(&sb, "<c><v>%d</v></c>", n)
+				continue
+			}
+		case reflect.Uint, reflect.Uint64:
+			if n := rv.Uint(); n < xlsxMaxInteger {
+				fmt.Fprintf(&sb, "<c><v>%d</v></c>", n)
+				continue
+			}
+		case reflect.Int8, reflect.Int16, reflect.Int32,
+			reflect.Uint8, reflect.Uint16, reflect.Uint32,
+			reflect.Float32, reflect.Float64:
+			fmt.Fprintf(&sb, "<c><v>%v</v></c>", rv.Interface())
+			continue
+		}
+		sb.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+		xml.EscapeText(&sb, []byte(formatExportValue(v)))
+		sb.WriteString("</t></is></c>")
+	}
+	sb.WriteString("</row>")
+	_, err := io.WriteString(w.sheet, sb.String())
+	return err
+}
+
+func (w *xlsxWriter) Close() error {
+	if _, err := io.WriteString(w.sheet, "</sheetData></worksheet>"); err != nil {
+		return err
+	}
+	parts := []struct {
+		name string
+		body string
+	}{
+		{"[Content_Types].xml", `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+			`</Types>`},
+		{"_rels/.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"xl/workbook.xml", `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets>` +
+			`</workbook>`},
+		{"xl/_rels/workbook.xml.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+			`</Relationships>`},
+	}
+	for _, p := range parts {
+		f, err := w.zw.Create(p.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, xml.Header+p.body); err != nil {
+			return err
+		}
+	}
+	return w.zw.Close()
+}
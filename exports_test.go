@@ -0,0 +1,152 @@
+package carrot
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestFormatExportValue(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	var nilTime *time.Time
+	assert.Equal(t, "", formatExportValue(nil))
+	assert.Equal(t, "", formatExportValue(nilTime))
+	assert.Equal(t, "2024-01-02T03:04:05Z", formatExportValue(now))
+	assert.Equal(t, "2024-01-02T03:04:05Z", formatExportValue(&now))
+	assert.Equal(t, "bob", formatExportValue(AdminValue{Value: 1, Label: "bob"}))
+	assert.Equal(t, "'=HYPERLINK(\"http://mock\")", formatExportValue(`=HYPERLINK("http://mock")`))
+	assert.Equal(t, "'@SUM(A1)", formatExportValue(AdminValue{Value: 1, Label: "@SUM(A1)"}))
+	assert.Equal(t, "'+1", formatExportValue("+1"))
+	assert.Equal(t, "'-1", formatExportValue("-1"))
+	assert.Equal(t, "-1", formatExportValue(-1))
+	assert.Equal(t, "-12.30", formatExportValue(MustParseDecimal("-12.30")))
+	assert.Equal(t, "12.30", formatExportValue(MustParseDecimal("12.30")))
+	assert.Equal(t, "true", formatExportValue(true))
+	assert.Equal(t, "42", formatExportValue(uint(42)))
+	assert.Equal(t, `{"avatar":"mock"}`, formatExportValue(Profile{Avatar: "mock"}))
+}
+
+func TestXLSXWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := newXLSXWriter(&buf)
+	assert.Nil(t, err)
+	assert.Nil(t, w.WriteRow([]any{"Name", "Age"}))
+	assert.Nil(t, w.WriteRow([]any{"<bob>", 42}))
+	assert.Nil(t, w.Close())
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.Nil(t, err)
+	files := map[string]string{}
+	for _, f := range zr.File {
+		r, _ := f.Open()
+		data, _ := io.ReadAll(r)
+		r.Close()
+		files[f.Name] = string(data)
+	}
+	assert.Contains(t, files, "[Content_Types].xml")
+	assert.Contains(t, files, "xl/workbook.xml")
+	sheet := files["xl/worksheets/sheet1.xml"]
+	assert.Contains(t, sheet, `<t xml:space="preserve">&lt;bob&gt;</t>`)
+	assert.Contains(t, sheet, `<c><v>42</v></c>`)
+}
+
+func TestXLSXWriterLargeInteger(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := newXLSXWriter(&buf)
+	assert.Nil(t, err)
+	assert.Nil(t, w.WriteRow([]any{int64(999999999999999), int64(1234567890123456789), uint64(18446744073709551615), int64(-1234567890123456789)}))
+	assert.Nil(t, w.Close())
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.Nil(t, err)
+	var sheet string
+	for _, f := range zr.File {
+		if f.Name == "xl/worksheets/sheet1.xml" {
+			r, _ := f.Open()
+			data, _ := io.ReadAll(r)
+			r.Close()
+			sheet = string(data)
+		}
+	}
+	assert.Contains(t, sheet, `<c><v>999999999999999</v></c>`)
+	assert.Contains(t, sheet, `<t xml:space="preserve">1234567890123456789</t>`)
+	assert.Contains(t, sheet, `<t xml:space="preserve">18446744073709551615</t>`)
+	assert.Contains(t, sheet, `<t xml:space="preserve">-1234567890123456789</t>`)
+}
+
+func TestAdminExport(t *testing.T) {
+	_, db, client := createAdminTest()
+	alice, _ := CreateUser(db, "alice@restsend.com", "1")
+	bob, _ := GetUserByEmail(db, "bob@restsend.com")
+	group := Group{Name: "staff"}
+	db.Create(&group)
+	db.Create(&GroupMember{UserID: alice.ID, GroupID: group.ID, Role: GroupRoleAdmin})
+	db.Create(&GroupMember{UserID: bob.ID, GroupID: group.ID, Role: GroupRoleMember})
+
+	export := func(query string, form *QueryForm) ([][]string, http.Header) {
+		body, _ := Marshal(form)
+		w := client.Post(http.MethodPost, "/admin/groupmember/_export"+query, body)
+		assert.Equal(t, http.StatusOK, w.Code)
+		records, err := csv.NewReader(w.Body).ReadAll()
+		assert.Nil(t, err)
+		return records, w.Header()
+	}
+
+	{
+		records, header := export("", &QueryForm{Orders: []Order{{Name: "id", Op: OrderOpAsc}}, Limit: 1})
+		assert.Contains(t, header.Get("Content-Type"), "text/csv")
+		assert.Contains(t, header.Get("Content-Disposition"), "attachment; filename=groupmembers-")
+		assert.Equal(t, 3, len(records))
+		assert.Equal(t, []string{"Id", "User", "Group", "Role", "Created At"}, records[0])
+		assert.Equal(t, "alice@restsend.com", records[1][1])
+		assert.Equal(t, "staff(1)", records[1][2])
+		assert.Equal(t, GroupRoleAdmin, records[1][3])
+		assert.Equal(t, "bob@restsend.com", records[2][1])
+	}
+	{
+		records, _ := export("", &QueryForm{Filters: []Filter{{Name: "role", Op: FilterOpEqual, Value: GroupRoleMember}}})
+		assert.Equal(t, 2, len(records))
+		assert.Equal(t, "bob@restsend.com", records[1][1])
+	}
+	{
+		// the rows with the same order value are exported by the primary key
+		var query string
+		db.Callback().Query().After("gorm:query").Register("test:export_query", func(tx *gorm.DB) {
+			if tx.Statement.Table == "group_members" {
+				query = tx.Statement.SQL.String()
+			}
+		})
+		records, _ := export("", &QueryForm{Orders: []Order{{Name: "group_id", Op: OrderOpDesc}}})
+		db.Callback().Query().Remove("test:export_query")
+		assert.Contains(t, query, "ORDER BY `group_members`.group_id DESC,`group_members`.`id`")
+		assert.Equal(t, 3, len(records))
+		assert.Equal(t, "alice@restsend.com", records[1][1])
+		assert.Equal(t, "bob@restsend.com", records[2][1])
+	}
+	{
+		keys := url.QueryEscape(fmt.Sprintf(`[{"id":%d}]`, 1))
+		records, _ := export("?keys="+keys, &QueryForm{})
+		assert.Equal(t, 2, len(records))
+		assert.Equal(t, "alice@restsend.com", records[1][1])
+	}
+	{
+		w := client.Post(http.MethodPost, "/admin/groupmember/_export?format=xlsx", nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Header().Get("Content-Disposition"), ".xlsx")
+		_, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+		assert.Nil(t, err)
+	}
+	{
+		w := client.Post(http.MethodPost, "/admin/groupmember/_export?format=pdf", nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	}
+}
@@ -0,0 +1,267 @@
+package carrot
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// QueryResultOf is the typed QueryResult of WebObjectOf[T].
+type QueryResultOf[T any] struct {
+	TotalCount int    `json:"total,omitempty"`
+	Pos        int    `json:"pos,omitempty"`
+	Limit      int    `json:"limit,omitempty"`
+	Keyword    string `json:"keyword,omitempty"`
+	TotalKind  string `json:"totalKind,omitempty"` // exact, estimated or none
+	Items      []T    `json:"items"`
+}
+
+// WebObjectOf is the typed WebObject, the hooks receive *T instead of any, such as:
+//
+//	products := WebObjectOf[Product]{
+//		Editables: []string{"Name"},
+//		BeforeCreate: func(db *gorm.DB, c *gin.Context, p *Product) error {
+//			p.UUID = RandText(8)
+//			return nil
+//		},
+//	}
+//	RegisterObjects(r, []WebObject{products.AsWebObject()})
+type WebObjectOf[T any] struct {
+	Group        string
+	Name         string
+	Desc         string
+	AuthRequired bool
+	Editables    []string
+	Filterables  []string
+	Orderables   []string
+	Searchables  []string
+	OwnerField   string
+	StateMachine *StateMachine
+	Associations []string
+	GetDB        GetDB
+	PrepareQuery PrepareQuery
+	BeforeCreate func(db *gorm.DB, c *gin.Context, obj *T) error
+	BeforeUpdate func(db *gorm.DB, c *gin.Context, obj *T, vals map[string]any) error
+	BeforeDelete func(db *gorm.DB, c *gin.Context, obj *T) error
+	// BeforeRender may replace the object by returning a non-nil *T,
+	// use BeforeQueryRender to render the query result in another shape.
+	BeforeRender      func(db *gorm.DB, c *gin.Context, obj *T) (*T, error)
+	BeforeQueryRender func(db *gorm.DB, c *gin.Context, r *QueryResultOf[T]) (any, error)
+
+	Views        []QueryViewOf[T]
+	AllowMethods int
+}
+
+// QueryViewOf is the typed QueryView of WebObjectOf[T], BeforeQueryRender render the result of the view,
+// default is BeforeQueryRender of the object.
+type QueryViewOf[T any] struct {
+	Path              string
+	Method            string
+	Desc              string
+	Prepare           PrepareQuery
+	BeforeQueryRender func(db *gorm.DB, c *gin.Context, r *QueryResultOf[T]) (any, error)
+}
+
+// AsWebObject convert obj to WebObject, which can be used by RegisterObjects and apidocs.
+func (obj *WebObjectOf[T]) AsWebObject() WebObject {
+	r := WebObject{
+		Model:        new(T),
+		Group:        obj.Group,
+		Name:         obj.Name,
+		Desc:         obj.Desc,
+		AuthRequired: obj.AuthRequired,
+		Editables:    obj.Editables,
+		Filterables:  obj.Filterables,
+		Orderables:   obj.Orderables,
+		Searchables:  obj.Searchables,
+		OwnerField:   obj.OwnerField,
+		StateMachine: obj.StateMachine,
+		Associations: obj.Associations,
+		GetDB:        obj.GetDB,
+		PrepareQuery: obj.PrepareQuery,
+		AllowMethods: obj.AllowMethods,
+	}
+	for _, v := range obj.Views {
+		view := QueryView{Path: v.Path, Method: v.Method, Desc: v.Desc, Prepare: v.Prepare}
+		if v.BeforeQueryRender != nil {
+			view.BeforeQueryRender = typedQueryRender(v.BeforeQueryRender)
+		}
+		r.Views = append(r.Views, view)
+	}
+
+	if fn := obj.BeforeCreate; fn != nil {
+		r.BeforeCreate = func(db *gorm.DB, c *gin.Context, vptr any) error {
+			return fn(db, c, vptr.(*T))
+		}
+	}
+	if fn := obj.BeforeUpdate; fn != nil {
+		r.BeforeUpdate = func(db *gorm.DB, c *gin.Context, vptr any, vals map[string]any) error {
+			return fn(db, c, vptr.(*T), vals)
+		}
+	}
+	if fn := obj.BeforeDelete; fn != nil {
+		r.BeforeDelete = func(db *gorm.DB, c *gin.Context, vptr any) error {
+			return fn(db, c, vptr.(*T))
+		}
+	}
+	if fn := obj.BeforeRender; fn != nil {
+		r.BeforeRender = func(db *gorm.DB, c *gin.Context, vptr any) (any, error) {
+			v, err := fn(db, c, vptr.(*T))
+			if v == nil {
+				return nil, err
+			}
+			return v, err
+		}
+	}
+	if fn := obj.BeforeQueryRender; fn != nil {
+		r.BeforeQueryRender = typedQueryRender(fn)
+	}
+	return r
+}
+
+// typedQueryRender convert the typed BeforeQueryRender to BeforeQueryRenderFunc.
+func typedQueryRender[T any](fn func(db *gorm.DB, c *gin.Context, r *QueryResultOf[T]) (any, error)) BeforeQueryRenderFunc {
+	return func(db *gorm.DB, c *gin.Context, qr *QueryResult) (any, error) {
+		r, err := AsQueryResultOf[T](qr)
+		if err != nil {
+			return nil, err
+		}
+		return fn(db, c, r)
+	}
+}
+
+// RegisterObject register the routes of obj, see WebObject.RegisterObject.
+func (obj *WebObjectOf[T]) RegisterObject(r *gin.RouterGroup) error {
+	webobject := obj.AsWebObject()
+	return webobject.RegisterObject(r)
+}
+
+// AsQueryResultOf convert the items of r to []T, ErrInvalidQueryItem is returned if any item is not T or *T.
+func AsQueryResultOf[T any](r *QueryResult) (*QueryResultOf[T], error) {
+	result := &QueryResultOf[T]{
+		TotalCount: r.TotalCount,
+		Pos:        r.Pos,
+		Limit:      r.Limit,
+		Keyword:    r.Keyword,
+		TotalKind:  r.TotalKind,
+		Items:      make([]T, 0, len(r.Items)),
+	}
+	for _, item := range r.Items {
+		switch v := item.(type) {
+		case *T:
+			result.Items = append(result.Items, *v)
+		case T:
+			result.Items = append(result.Items, v)
+		default:
+			return nil, fmt.Errorf("%w: %T", ErrInvalidQueryItem, item)
+		}
+	}
+	return result, nil
+}
+
+// AdminObjectOf is the typed AdminObject, the hooks receive *T instead of any.
+type AdminObjectOf[T any] struct {
+	Group        string
+	Name         string
+	Desc         string
+	Path         string
+	Shows        []string
+	Orders       []Order
+	Editables    []string
+	Filterables  []string
+	Orderables   []string
+	Searchables  []string
+	Requireds    []string
+	PrimaryKeys  []string
+	UniqueKeys   []string
+	EditPage     string
+	ListPage     string
+	Scripts      []AdminScript
+	Styles       []string
+	Actions      []AdminAction
+	Inlines      []AdminInline
+	LockField    string
+	Icon         *AdminIcon
+	Invisible    bool
+	Attributes   map[string]AdminAttribute
+	AccessCheck  AdminAccessCheck
+	OwnerField   string
+	StateMachine *StateMachine
+	GetDB        GetDB
+	ViewOnSite   func(db *gorm.DB, c *gin.Context, obj *T) string
+	BeforeCreate func(db *gorm.DB, c *gin.Context, obj *T) error
+	BeforeUpdate func(db *gorm.DB, c *gin.Context, obj *T, vals map[string]any) error
+	BeforeDelete func(db *gorm.DB, c *gin.Context, obj *T) error
+	// BeforeRender may replace the object by returning a non-nil *T.
+	BeforeRender func(db *gorm.DB, c *gin.Context, obj *T) (*T, error)
+}
+
+// AsAdminObject convert obj to AdminObject, which can be used by RegisterAdmins.
+func (obj *AdminObjectOf[T]) AsAdminObject() AdminObject {
+	r := AdminObject{
+		Model:        new(T),
+		Group:        obj.Group,
+		Name:         obj.Name,
+		Desc:         obj.Desc,
+		Path:         obj.Path,
+		Shows:        obj.Shows,
+		Orders:       obj.Orders,
+		Editables:    obj.Editables,
+		Filterables:  obj.Filterables,
+		Orderables:   obj.Orderables,
+		Searchables:  obj.Searchables,
+		Requireds:    obj.Requireds,
+		PrimaryKeys:  obj.PrimaryKeys,
+		UniqueKeys:   obj.UniqueKeys,
+		EditPage:     obj.EditPage,
+		ListPage:     obj.ListPage,
+		Scripts:      obj.Scripts,
+		Styles:       obj.Styles,
+		Actions:      obj.Actions,
+		Inlines:      obj.Inlines,
+		LockField:    obj.LockField,
+		Icon:         obj.Icon,
+		Invisible:    obj.Invisible,
+		Attributes:   obj.Attributes,
+		AccessCheck:  obj.AccessCheck,
+		OwnerField:   obj.OwnerField,
+		StateMachine: obj.StateMachine,
+		GetDB:        obj.GetDB,
+	}
+
+	if fn := obj.ViewOnSite; fn != nil {
+		r.ViewOnSite = func(db *gorm.DB, c *gin.Context, vptr any) string {
+			// vptr may be replaced by BeforeRender
+			if v, ok := vptr.(*T); ok {
+				return fn(db, c, v)
+			}
+			return ""
+		}
+	}
+	if fn := obj.BeforeCreate; fn != nil {
+		r.BeforeCreate = func(db *gorm.DB, c *gin.Context, vptr any) error {
+			return fn(db, c, vptr.(*T))
+		}
+	}
+	if fn := obj.BeforeUpdate; fn != nil {
+		r.BeforeUpdate = func(db *gorm.DB, c *gin.Context, vptr any, vals map[string]any) error {
+			return fn(db, c, vptr.(*T), vals)
+		}
+	}
+	if fn := obj.BeforeDelete; fn != nil {
+		r.BeforeDelete = func(db *gorm.DB, c *gin.Context, vptr any) error {
+			return fn(db, c, vptr.(*T))
+		}
+	}
+	if fn := obj.BeforeRender; fn != nil {
+		r.BeforeRender = func(db *gorm.DB, c *gin.Context, vptr any) (any, error) {
+			v, err := fn(db, c, vptr.(*T))
+			if v == nil {
+				return nil, err
+			}
+			return v, err
+		}
+	}
+	return r
+}
@@ -0,0 +1,148 @@
+package carrot
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type typedItem struct {
+	ID   uint   `json:"id" gorm:"primarykey"`
+	Name string `json:"name" gorm:"size:100"`
+	Age  int    `json:"age"`
+}
+
+func TestWebObjectOf(t *testing.T) {
+	db, _ := gorm.Open(sqlite.Open("file::memory:"), nil)
+	db.AutoMigrate(typedItem{})
+	db.Create(&typedItem{ID: 1, Name: "alice", Age: 10})
+
+	var names []string
+	r := gin.New()
+	r.Use(WithGormDB(db))
+	items := WebObjectOf[typedItem]{
+		Name:      "item",
+		Editables: []string{"Name", "Age"},
+		BeforeCreate: func(db *gorm.DB, c *gin.Context, obj *typedItem) error {
+			if obj.Age < 0 {
+				return errors.New("invalid age")
+			}
+			return nil
+		},
+		BeforeRender: func(db *gorm.DB, c *gin.Context, obj *typedItem) (*typedItem, error) {
+			obj.Name = "hello " + obj.Name
+			return nil, nil
+		},
+		BeforeQueryRender: func(db *gorm.DB, c *gin.Context, r *QueryResultOf[typedItem]) (any, error) {
+			for _, v := range r.Items {
+				names = append(names, v.Name)
+			}
+			return r, nil
+		},
+		Views: []QueryViewOf[typedItem]{{
+			Path: "names",
+			BeforeQueryRender: func(db *gorm.DB, c *gin.Context, r *QueryResultOf[typedItem]) (any, error) {
+				var names []string
+				for _, v := range r.Items {
+					names = append(names, v.Name)
+				}
+				return names, nil
+			},
+		}, {
+			Path: "all",
+		}},
+	}
+	RegisterObjects(&r.RouterGroup, []WebObject{items.AsWebObject()})
+	client := NewTestClient(r)
+
+	w := client.Post(http.MethodPut, "/item", []byte(`{"name":"bob","age":-1}`))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var item typedItem
+	err := client.CallPut("/item", typedItem{Name: "bob", Age: 20}, &item)
+	assert.Nil(t, err)
+
+	err = client.CallGet("/item/1", nil, &item)
+	assert.Nil(t, err)
+	assert.Equal(t, "hello alice", item.Name)
+
+	var res QueryResultOf[typedItem]
+	err = client.CallPost("/item", nil, &res)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, res.TotalCount)
+	assert.Equal(t, []string{"hello alice", "hello bob"}, names)
+	assert.Equal(t, "hello bob", res.Items[1].Name)
+
+	// the views are typed, the object's BeforeQueryRender is the default
+	var viewNames []string
+	err = client.CallPost("/item/names", nil, &viewNames)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"hello alice", "hello bob"}, viewNames)
+	names = nil
+	err = client.CallPost("/item/all", nil, &res)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(names))
+
+	// the items in other shapes are not dropped silently
+	_, err = AsQueryResultOf[typedItem](&QueryResult{Items: []any{&typedItem{}, map[string]any{}}})
+	assert.ErrorIs(t, err, ErrInvalidQueryItem)
+}
+
+func TestAdminObjectOf(t *testing.T) {
+	db, _ := InitDatabase(nil, "", "")
+	db.AutoMigrate(typedItem{})
+
+	items := AdminObjectOf[typedItem]{
+		Name:  "Item",
+		Shows: []string{"ID", "Name"},
+		BeforeCreate: func(db *gorm.DB, c *gin.Context, obj *typedItem) error {
+			obj.Age = 18
+			return nil
+		},
+		ViewOnSite: func(db *gorm.DB, c *gin.Context, obj *typedItem) string {
+			return "/item/" + obj.Name
+		},
+		BeforeRender: func(db *gorm.DB, c *gin.Context, obj *typedItem) (*typedItem, error) {
+			if obj.Name == "" {
+				return nil, nil
+			}
+			return &typedItem{ID: obj.ID, Name: "hello " + obj.Name}, nil
+		},
+	}
+	obj := items.AsAdminObject()
+	err := obj.Build(db)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"id", "name"}, obj.Shows)
+
+	val := &typedItem{Name: "alice"}
+	err = obj.BeforeCreate(db, nil, val)
+	assert.Nil(t, err)
+	assert.Equal(t, 18, val.Age)
+	assert.Equal(t, "/item/alice", obj.ViewOnSite(db, nil, val))
+	assert.Equal(t, "", obj.ViewOnSite(db, nil, map[string]any{}))
+
+	rr, err := obj.BeforeRender(db, nil, val)
+	assert.Nil(t, err)
+	assert.Equal(t, "hello alice", rr.(*typedItem).Name)
+	rr, err = obj.BeforeRender(db, nil, &typedItem{})
+	assert.Nil(t, err)
+	assert.Nil(t, rr)
+
+	locked := AdminObjectOf[typedItem]{
+		Name:        "Item",
+		PrimaryKeys: []string{"ID"},
+		UniqueKeys:  []string{"Name"},
+		Inlines:     []AdminInline{{Field: "Children"}},
+		LockField:   "Age",
+	}
+	obj = locked.AsAdminObject()
+	assert.Equal(t, []string{"ID"}, obj.PrimaryKeys)
+	assert.Equal(t, []string{"Name"}, obj.UniqueKeys)
+	assert.Equal(t, "Children", obj.Inlines[0].Field)
+	assert.Equal(t, "Age", obj.LockField)
+}
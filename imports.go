@@ -0,0 +1,413 @@
+package carrot
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+const (
+	ImportModePreview = "preview" // convert the first rows, nothing is written
+	ImportModeDryRun  = "dryrun"  // create all rows in a transaction and rollback
+	ImportModeCommit  = "commit"  // create all rows, rollback all if any row fails
+)
+
+const DefaultImportPreviewRows = 10
+
+var ErrInvalidImportMode = errors.New("invalid import mode")
+var ErrInvalidImportMapping = errors.New("invalid import mapping")
+var ErrForeignNotFound = errors.New("foreign object not found")
+
+// errImportRollback rollback the import transaction of preview, dry-run and failed commit.
+var errImportRollback = errors.New("import rollback")
+
+type AdminImportError struct {
+	Line  int    `json:"line"` // line of the csv file, the header is line 1
+	Error string `json:"error"`
+}
+
+type AdminImportResult struct {
+	Headers []string           `json:"headers"`
+	Mapping map[string]string  `json:"mapping"`          // csv header => field name
+	Total   int                `json:"total"`            // rows processed
+	Created int                `json:"created"`          // rows created, only for commit
+	Rows    []map[string]any   `json:"rows,omitempty"`   // converted rows, only for preview
+	Errors  []AdminImportError `json:"errors,omitempty"` // per row errors
+}
+
+// handleImport import the objects from the csv file, such as:
+//
+//	POST /admin/user/_import?mode=preview
+//	Content-Type: multipart/form-data
+//
+//	file: the csv file, the first line is the header
+//	mapping: {"Email": "email", "Name": "display_name"}, guessed by the field names and labels if empty
+func (obj *AdminObject) handleImport(c *gin.Context) {
+	if !obj.checkPermission(c, PermissionCreate) {
+		return
+	}
+	if obj.OwnerField != "" && CurrentUser(c) == nil {
+		AbortWithJSONError(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+
+	mode := c.DefaultQuery("mode", ImportModePreview)
+	if mode != ImportModePreview && mode != ImportModeDryRun && mode != ImportModeCommit {
+		AbortWithJSONError(c, http.StatusBadRequest, ErrInvalidImportMode)
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		AbortWithJSONError(c, http.StatusBadRequest, err)
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		AbortWithJSONError(c, http.StatusBadRequest, err)
+		return
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	headers, err := reader.Read()
+	if err != nil {
+		AbortWithJSONError(c, http.StatusBadRequest, err)
+		return
+	}
+	if len(headers) > 0 {
+		headers[0] = strings.TrimPrefix(headers[0], "\ufeff")
+	}
+
+	mapping := map[string]string{}
+	if v := c.PostForm("mapping"); v != "" {
+		if err := json.Unmarshal([]byte(v), &mapping); err != nil {
+			AbortWithJSONError(c, http.StatusBadRequest, err)
+			return
+		}
+	} else {
+		mapping = obj.guessImportMapping(headers)
+	}
+
+	columns, err := obj.importColumns(headers, mapping)
+	if err != nil {
+		AbortWithJSONError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	result := AdminImportResult{Headers: headers, Mapping: mapping}
+	db := getDbConnection(c, obj.GetDB, true)
+	var created []any
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		foreigns := map[string]*importForeign{}
+		for {
+			if mode == ImportModePreview && result.Total >= DefaultImportPreviewRows {
+				break
+			}
+			record, err := reader.Read()
+			if err == io.EOF {
+				break
+			}
+			line, _ := reader.FieldPos(0)
+			if err != nil && !errors.Is(err, csv.ErrFieldCount) {
+				return err
+			}
+			result.Total++
+
+			elm, err := obj.importRow(tx, record, columns, foreigns)
+			if err == nil && mode == ImportModePreview {
+				var item map[string]any
+				if item, err = obj.MarshalOne(c, elm); err == nil {
+					result.Rows = append(result.Rows, item)
+				}
+			} else if err == nil {
+				err = tx.Transaction(func(tx *gorm.DB) error {
+					if err := obj.setOwner(c, elm); err != nil {
+						return err
+					}
+					if obj.BeforeCreate != nil {
+						if err := obj.BeforeCreate(tx, c, elm); err != nil {
+							return err
+						}
+					}
+					return tx.Create(elm).Error
+				})
+			}
+			if err != nil {
+				result.Errors = append(result.Errors, AdminImportError{Line: line, Error: err.Error()})
+				continue
+			}
+			created = append(created, elm)
+		}
+
+		if mode != ImportModeCommit || len(result.Errors) > 0 {
+			return errImportRollback
+		}
+		return nil
+	})
+
+	if err != nil && !errors.Is(err, errImportRollback) {
+		AbortWithJSONError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if mode == ImportModeCommit && len(result.Errors) == 0 {
+		result.Created = len(created)
+		var fields []string
+		for _, f := range columns {
+			if f != nil {
+				fields = append(fields, f.Name)
+			}
+		}
+		for _, elm := range created {
+			obj.writeLog(c, elm, AdminLogCreate, fields...)
+		}
+	}
+	RenderJSON(c, http.StatusOK, result)
+}
+
+// importable check the field can be imported, the field must be a column and editable.
+// The state field can only be changed by transitions.
+func (obj *AdminObject) importable(field *AdminField) bool {
+	if field.NotColumn || (field.Foreign != nil && field.Foreign.hasMany) {
+		return false
+	}
+	if obj.StateMachine != nil && field.fieldName == obj.StateMachine.Field {
+		return false
+	}
+	if len(obj.Editables) == 0 {
+		return true
+	}
+	for _, v := range obj.Editables {
+		if v == field.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// guessImportMapping map the headers to the importable fields with the same name or label, case insensitive.
+func (obj *AdminObject) guessImportMapping(headers []string) map[string]string {
+	mapping := map[string]string{}
+	for _, h := range headers {
+		h = strings.TrimSpace(h)
+		for i := range obj.Fields {
+			f := &obj.Fields[i]
+			if !obj.importable(f) {
+				continue
+			}
+			if strings.EqualFold(h, f.Name) || strings.EqualFold(h, f.Label) || strings.EqualFold(h, f.fieldName) {
+				mapping[h] = f.Name
+				break
+			}
+		}
+	}
+	return mapping
+}
+
+// importColumns return the fields of the csv columns by mapping, nil for the unmapped columns.
+// All Requireds must be mapped.
+func (obj *AdminObject) importColumns(headers []string, mapping map[string]string) ([]*AdminField, error) {
+	columns := make([]*AdminField, len(headers))
+	mapped := map[string]bool{}
+	for idx, h := range headers {
+		name := mapping[strings.TrimSpace(h)]
+		if name == "" {
+			continue
+		}
+		var field *AdminField
+		for i := range obj.Fields {
+			if obj.Fields[i].Name == name {
+				field = &obj.Fields[i]
+				break
+			}
+		}
+		if field == nil || !obj.importable(field) {
+			return nil, fmt.Errorf("%w: %s is not importable", ErrInvalidImportMapping, name)
+		}
+		if mapped[name] {
+			return nil, fmt.Errorf("%w: %s is mapped more than once", ErrInvalidImportMapping, name)
+		}
+		mapped[name] = true
+		columns[idx] = field
+	}
+	if len(mapped) == 0 {
+		return nil, fmt.Errorf("%w: no field is mapped", ErrInvalidImportMapping)
+	}
+	for _, name := range obj.Requireds {
+		if !mapped[name] {
+			return nil, fmt.Errorf("%w: %s is required", ErrInvalidImportMapping, name)
+		}
+	}
+	return columns, nil
+}
+
+// importRow convert the csv record to the object, empty values are ignored.
+func (obj *AdminObject) importRow(db *gorm.DB, record []string, columns []*AdminField, foreigns map[string]*importForeign) (any, error) {
+	vals := map[string]any{}
+	for idx, field := range columns {
+		if field == nil {
+			continue
+		}
+		var s string
+		if idx < len(record) {
+			s = strings.TrimSpace(record[idx])
+		}
+		if s == "" {
+			for _, name := range obj.Requireds {
+				if name == field.Name {
+					return nil, fmt.Errorf("%s is required", field.Name)
+				}
+			}
+			continue
+		}
+
+		var val any = s
+		var err error
+		if field.Foreign != nil {
+			f, ok := foreigns[field.Name]
+			if !ok {
+				sf, _ := obj.modelElem.FieldByName(field.Foreign.fieldName)
+				f = &importForeign{db: db, model: sf.Type, searchables: field.Foreign.searchables}
+				foreigns[field.Name] = f
+			}
+			val, err = f.resolve(s)
+		} else {
+			val, err = importValue(field, s)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", field.Name, err)
+		}
+		vals[field.Name] = val
+	}
+	return obj.UnmarshalFrom(reflect.New(obj.modelElem), nil, vals)
+}
+
+// importValue parse the text for convertValue, integers are checked and json values are decoded.
+func importValue(field *AdminField, s string) (any, error) {
+	elemType := field.elemType
+	if field.IsArray {
+		var v any
+		err := json.Unmarshal([]byte(s), &v)
+		return v, err
+	}
+	switch elemType.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.ParseInt(s, 10, 64)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.ParseUint(s, 10, 64)
+	case reflect.Map, reflect.Slice, reflect.Struct:
+		if field.Type == "datetime" || elemType == decimalType {
+			return s, nil
+		}
+		var v any
+		err := json.Unmarshal([]byte(s), &v)
+		return v, err
+	}
+	return s, nil
+}
+
+// importForeign resolve the foreign value by the primary key or the label, the label is String() of the foreign object.
+// The labels are looked up by the searchable columns of the foreign admin object, such as "staff(1)" of Group is
+// looked up by name "staff(1)" or "staff", and the results are cached per value.
+type importForeign struct {
+	db          *gorm.DB
+	model       reflect.Type
+	pk          *schema.Field
+	searchables []string       // searchable columns of the foreign admin object
+	labels      map[string]any // label => primary value, nil if not found
+}
+
+func (f *importForeign) resolve(value string) (any, error) {
+	if f.model.Kind() == reflect.Ptr {
+		f.model = f.model.Elem()
+	}
+	if f.pk == nil {
+		stmt := &gorm.Statement{DB: f.db}
+		if err := stmt.Parse(reflect.New(f.model).Interface()); err != nil {
+			return nil, err
+		}
+		if stmt.Schema.PrioritizedPrimaryField == nil {
+			return nil, fmt.Errorf("%s not has primaryKey", stmt.Schema.Name)
+		}
+		f.pk = stmt.Schema.PrioritizedPrimaryField
+		f.labels = map[string]any{}
+	}
+
+	// the value is the primary key
+	var key any = value
+	isKey := true
+	switch f.pk.FieldType.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v, err := strconv.ParseInt(value, 10, 64)
+		key, isKey = v, err == nil
+	}
+	if isKey {
+		var c int64
+		if err := f.db.Model(reflect.New(f.model).Interface()).Where(map[string]any{f.pk.DBName: key}).Count(&c).Error; err != nil {
+			return nil, err
+		}
+		if c > 0 {
+			return key, nil
+		}
+	}
+
+	// the value is the label
+	v, ok := f.labels[value]
+	if !ok {
+		var err error
+		if v, err = f.lookupLabel(value); err != nil {
+			return nil, err
+		}
+		f.labels[value] = v
+	}
+	if v == nil {
+		return nil, fmt.Errorf("%w: %s", ErrForeignNotFound, value)
+	}
+	return v, nil
+}
+
+// lookupLabel query at most DefaultForeignLimit rows which searchable columns equal to the label,
+// or the text before the parentheses, then match the String() of the rows. Return nil if not found.
+func (f *importForeign) lookupLabel(label string) (any, error) {
+	terms := []any{label}
+	if i := strings.LastIndex(label, "("); i > 0 && strings.HasSuffix(label, ")") {
+		terms = append(terms, strings.TrimSpace(label[:i]))
+	}
+	var conds []clause.Expression
+	for _, name := range f.searchables {
+		if strings.Contains(name, ".") {
+			continue // json path
+		}
+		conds = append(conds, clause.IN{Column: clause.Column{Name: name}, Values: terms})
+	}
+	if len(conds) == 0 {
+		return nil, nil
+	}
+
+	rows := reflect.New(reflect.SliceOf(f.model))
+	err := f.db.Model(reflect.New(f.model).Interface()).Where(clause.Or(conds...)).Limit(DefaultForeignLimit).Find(rows.Interface()).Error
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < rows.Elem().Len(); i++ {
+		row := rows.Elem().Index(i).Addr()
+		if sv, ok := row.Interface().(fmt.Stringer); ok && sv.String() == label {
+			return row.Elem().FieldByName(f.pk.Name).Interface(), nil
+		}
+	}
+	return nil, nil
+}
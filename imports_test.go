@@ -0,0 +1,236 @@
+package carrot
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminImport(t *testing.T) {
+	_, db, client := createAdminTest()
+	alice, _ := CreateUser(db, "alice@restsend.com", "1")
+	bob, _ := GetUserByEmail(db, "bob@restsend.com")
+	group := Group{Name: "staff"}
+	db.Create(&group)
+
+	doImport := func(path, mode, data string, mapping map[string]string) (int, AdminImportResult) {
+		var body bytes.Buffer
+		w := multipart.NewWriter(&body)
+		fw, _ := w.CreateFormFile("file", "import.csv")
+		fw.Write([]byte(data))
+		if mapping != nil {
+			v, _ := json.Marshal(mapping)
+			w.WriteField("mapping", string(v))
+		}
+		w.Close()
+
+		req, _ := http.NewRequest(http.MethodPost, path+"?mode="+mode, &body)
+		req.Header.Set("Content-Type", w.FormDataContentType())
+		resp := client.SendReq(path, req)
+		var r AdminImportResult
+		json.Unmarshal(resp.Body.Bytes(), &r)
+		return resp.Code, r
+	}
+	countMembers := func() int64 {
+		var c int64
+		db.Model(&GroupMember{}).Count(&c)
+		return c
+	}
+
+	data := "\ufeffUser,Group,Role\n" +
+		"alice@restsend.com,staff(1),admin\n" +
+		fmt.Sprintf("%d,%d,member\n", bob.ID, group.ID)
+	{
+		code, r := doImport("/admin/groupmember/_import", ImportModePreview, data, nil)
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, []string{"User", "Group", "Role"}, r.Headers)
+		assert.Equal(t, map[string]string{"User": "user", "Group": "group", "Role": "role"}, r.Mapping)
+		assert.Equal(t, 2, r.Total)
+		assert.Equal(t, 0, len(r.Errors))
+		assert.Equal(t, 2, len(r.Rows))
+		assert.Equal(t, float64(alice.ID), r.Rows[0]["user"].(map[string]any)["value"])
+		assert.Equal(t, float64(bob.ID), r.Rows[1]["user"].(map[string]any)["value"])
+		assert.Equal(t, GroupRoleMember, r.Rows[1]["role"])
+		assert.Equal(t, int64(0), countMembers())
+	}
+	{
+		bad := data + "nobody@restsend.com,staff(1),member\n" + "1,,member\n"
+		code, r := doImport("/admin/groupmember/_import", ImportModeDryRun, bad, nil)
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, 4, r.Total)
+		assert.Equal(t, 2, len(r.Errors))
+		assert.Equal(t, 4, r.Errors[0].Line)
+		assert.Contains(t, r.Errors[0].Error, ErrForeignNotFound.Error())
+		assert.Equal(t, 5, r.Errors[1].Line)
+		assert.Contains(t, r.Errors[1].Error, "group is required")
+		assert.Equal(t, int64(0), countMembers())
+
+		code, r = doImport("/admin/groupmember/_import", ImportModeCommit, bad, nil)
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, 0, r.Created)
+		assert.Equal(t, 2, len(r.Errors))
+		assert.Equal(t, int64(0), countMembers())
+	}
+	{
+		code, r := doImport("/admin/groupmember/_import", ImportModeDryRun, data, nil)
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, 0, len(r.Errors))
+		assert.Equal(t, int64(0), countMembers())
+
+		code, r = doImport("/admin/groupmember/_import", ImportModeCommit, data, nil)
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, 2, r.Created)
+		assert.Equal(t, int64(2), countMembers())
+
+		var logs int64
+		db.Model(&AdminLog{}).Where("object_type", "group_members").Where("action", AdminLogCreate).Count(&logs)
+		assert.Equal(t, int64(2), logs)
+	}
+	{
+		// the mapping must contain the required fields
+		code, _ := doImport("/admin/groupmember/_import", ImportModePreview, data, map[string]string{"User": "user", "Group": "group"})
+		assert.Equal(t, http.StatusBadRequest, code)
+
+		code, _ = doImport("/admin/groupmember/_import", ImportModePreview, data, map[string]string{"User": "created_at", "Group": "group", "Role": "role"})
+		assert.Equal(t, http.StatusBadRequest, code)
+
+		code, _ = doImport("/admin/groupmember/_import", "mock", data, nil)
+		assert.Equal(t, http.StatusBadRequest, code)
+	}
+	{
+		// BeforeCreate is called for each row
+		code, r := doImport("/admin/adminlog/_import", ImportModeDryRun, "Action\nmock\n", nil)
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, 1, len(r.Errors))
+		assert.Equal(t, ErrAdminLogReadOnly.Error(), r.Errors[0].Error)
+	}
+}
+
+func TestImportValue(t *testing.T) {
+	obj := AdminObject{Model: &User{}, Path: "user"}
+	db, _ := InitDatabase(nil, "", "")
+	assert.Nil(t, obj.Build(db))
+
+	field := func(name string) *AdminField {
+		for i := range obj.Fields {
+			if obj.Fields[i].Name == name {
+				return &obj.Fields[i]
+			}
+		}
+		return nil
+	}
+	v, err := importValue(field("id"), "12")
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(12), v)
+
+	_, err = importValue(field("id"), "abc")
+	assert.NotNil(t, err)
+
+	v, err = importValue(field("profile"), `{"avatar":"mock"}`)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]any{"avatar": "mock"}, v)
+
+	v, err = importValue(field("last_login"), "2024-01-02")
+	assert.Nil(t, err)
+	assert.Equal(t, "2024-01-02", v)
+
+	v, err = importValue(field("email"), "bob@restsend.com")
+	assert.Nil(t, err)
+	assert.Equal(t, "bob@restsend.com", v)
+}
+
+func TestImportForeign(t *testing.T) {
+	db, _ := InitDatabase(nil, "", "")
+	MakeMigrates(db, []any{&Group{}})
+	staff := Group{Name: "staff"}
+	db.Create(&staff)
+	db.Create(&Group{Name: "sales"})
+
+	f := &importForeign{db: db, model: reflect.TypeOf(Group{}), searchables: []string{"name"}}
+	v, err := f.resolve(staff.String())
+	assert.Nil(t, err)
+	assert.Equal(t, staff.ID, v)
+	v, err = f.resolve(fmt.Sprintf("%d", staff.ID))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(staff.ID), v)
+
+	// the label is cached
+	db.Where("id", staff.ID).Delete(&Group{})
+	v, err = f.resolve(staff.String())
+	assert.Nil(t, err)
+	assert.Equal(t, staff.ID, v)
+
+	_, err = f.resolve("staff(999)")
+	assert.ErrorIs(t, err, ErrForeignNotFound)
+	assert.Len(t, f.labels, 2)
+
+	// the labels are not resolved without searchables
+	f = &importForeign{db: db, model: reflect.TypeOf(Group{})}
+	_, err = f.resolve("sales(2)")
+	assert.ErrorIs(t, err, ErrForeignNotFound)
+}
+
+func TestAdminImportOwner(t *testing.T) {
+	type Ticket struct {
+		ID      uint   `json:"id" gorm:"primarykey"`
+		OwnerID uint   `json:"ownerId"`
+		Title   string `json:"title" gorm:"size:100"`
+		Status  string `json:"status" gorm:"size:20;default:open"`
+	}
+
+	r := gin.Default()
+	db, _ := InitDatabase(nil, "", "")
+	InitCarrot(db, r)
+	db.AutoMigrate(Ticket{})
+	objs := append(GetCarrotAdminObjects(), AdminObject{
+		Model:        &Ticket{},
+		Name:         "Ticket",
+		OwnerField:   "OwnerID",
+		StateMachine: &StateMachine{Field: "Status", Transitions: []StateTransition{{Name: "close", To: "closed"}}},
+	})
+	RegisterAdmins(r.Group("/admin"), db, objs)
+
+	alice, _ := CreateUser(db, "alice@restsend.com", "--")
+	bob, _ := CreateUser(db, "bob@restsend.com", "--")
+	bob.IsStaff = true
+	bob.Activated = true
+	db.Save(bob)
+	createAdminGroup(db, bob, GroupTypeAdmin, "ticket.*")
+	client := NewTestClient(r)
+	authClient(db, client, "bob@restsend.com", "--", false)
+
+	doImport := func(data string, mapping map[string]string) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		w := multipart.NewWriter(&body)
+		fw, _ := w.CreateFormFile("file", "import.csv")
+		fw.Write([]byte(data))
+		v, _ := json.Marshal(mapping)
+		w.WriteField("mapping", string(v))
+		w.Close()
+
+		req, _ := http.NewRequest(http.MethodPost, "/admin/ticket/_import?mode="+ImportModeCommit, &body)
+		req.Header.Set("Content-Type", w.FormDataContentType())
+		return client.SendReq("/admin/ticket/_import", req)
+	}
+
+	// the owner is always current user for non-superusers
+	w := doImport(fmt.Sprintf("Title,Owner\nhello,%d\n", alice.ID), map[string]string{"Title": "title", "Owner": "owner_id"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	var ticket Ticket
+	db.Take(&ticket)
+	assert.Equal(t, "hello", ticket.Title)
+	assert.Equal(t, bob.ID, ticket.OwnerID)
+
+	// the state can only be changed by transitions
+	w = doImport("Title,Status\nworld,closed\n", map[string]string{"Title": "title", "Status": "status"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), ErrInvalidImportMapping.Error())
+}
@@ -0,0 +1,306 @@
+package carrot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+var ErrInvalidInline = errors.New("invalid inline")
+
+// AdminInline edit the has-many children in the edit page of the parent, such as the Extra of Group.
+// The parent and children are saved in one transaction, the children not in the form are deleted.
+type AdminInline struct {
+	Field       string       `json:"field"`               // Has many field of the parent, such as "Extra"
+	Label       string       `json:"label,omitempty"`     // Label of the inline, the label of the field if empty
+	Editables   []string     `json:"editables"`           // Editable fields of the child, all columns except the keys if empty
+	Requireds   []string     `json:"requireds,omitempty"` // Required fields of the child
+	PrimaryKeys []string     `json:"primaryKeys"`         // Primary keys of the child
+	Fields      []AdminField `json:"fields"`
+	fieldName   string
+	object      *AdminObject
+	relation    *schema.Relationship
+}
+
+// buildInlines build the child objects of the inlines, the foreign keys to the parent are not editable.
+func (obj *AdminObject) buildInlines(db *gorm.DB) error {
+	if len(obj.Inlines) == 0 {
+		return nil
+	}
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(obj.Model); err != nil {
+		return err
+	}
+	for idx := range obj.Inlines {
+		inline := &obj.Inlines[idx]
+		rel, ok := stmt.Schema.Relationships.Relations[inline.Field]
+		if !ok || rel.Type != schema.HasMany {
+			return fmt.Errorf("%w: %s is not a has many field of %s", ErrInvalidInline, inline.Field, obj.Name)
+		}
+
+		child := &AdminObject{
+			Model:     reflect.New(rel.FieldSchema.ModelType).Interface(),
+			Name:      inline.Field,
+			Editables: inline.Editables,
+			Requireds: inline.Requireds,
+		}
+		if err := child.Build(db); err != nil {
+			return err
+		}
+
+		foreignKeys := map[string]bool{}
+		for _, ref := range rel.References {
+			foreignKeys[ref.ForeignKey.DBName] = true
+		}
+		var fields []AdminField
+		var editables []string
+		for _, f := range child.Fields {
+			if foreignKeys[f.Name] {
+				continue
+			}
+			fields = append(fields, f)
+			if len(inline.Editables) == 0 && !f.NotColumn && !f.IsAutoID && (f.Foreign == nil || !f.Foreign.hasMany) {
+				editables = append(editables, f.Name)
+			}
+		}
+		if len(inline.Editables) > 0 {
+			for _, name := range child.Editables {
+				if !foreignKeys[name] {
+					editables = append(editables, name)
+				}
+			}
+		}
+		child.Fields = fields
+		child.Editables = editables
+
+		inline.fieldName = inline.Field
+		inline.Field = db.NamingStrategy.ColumnName(obj.tableName, inline.Field)
+		inline.Fields = fields
+		inline.Editables = editables
+		inline.Requireds = child.Requireds
+		inline.PrimaryKeys = child.PrimaryKeys
+		inline.object = child
+		inline.relation = rel
+		if inline.Label == "" {
+			for _, f := range obj.Fields {
+				if f.Name == inline.Field {
+					inline.Label = f.Label
+					break
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// getInline return the inline of the field name, nil if the field is not inline.
+func (obj *AdminObject) getInline(name string) *AdminInline {
+	for idx := range obj.Inlines {
+		if obj.Inlines[idx].Field == name {
+			return &obj.Inlines[idx]
+		}
+	}
+	return nil
+}
+
+// omitInlines skip saving the inline associations with the parent, they are saved by saveInlines.
+func (obj *AdminObject) omitInlines(tx *gorm.DB) *gorm.DB {
+	if len(obj.Inlines) == 0 {
+		return tx
+	}
+	names := make([]string, 0, len(obj.Inlines))
+	for _, inline := range obj.Inlines {
+		names = append(names, inline.fieldName)
+	}
+	return tx.Omit(names...)
+}
+
+// popInlines remove the children of the inlines from vals, the inlines not in vals are not changed.
+func (obj *AdminObject) popInlines(vals map[string]any) (map[string][]map[string]any, error) {
+	children := map[string][]map[string]any{}
+	for _, inline := range obj.Inlines {
+		v, ok := vals[inline.Field]
+		if !ok {
+			continue
+		}
+		delete(vals, inline.Field)
+
+		items, ok := v.([]any)
+		if !ok && v != nil {
+			return nil, fmt.Errorf("%w: %s must be a list", ErrInvalidInline, inline.Field)
+		}
+		rows := make([]map[string]any, 0, len(items))
+		for _, item := range items {
+			row, ok := item.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("%w: %s must be a list of objects", ErrInvalidInline, inline.Field)
+			}
+			rows = append(rows, row)
+		}
+		children[inline.Field] = rows
+	}
+	return children, nil
+}
+
+// saveInlines save the children of parent, return the changed inline fields.
+func (obj *AdminObject) saveInlines(tx *gorm.DB, parent any, children map[string][]map[string]any) ([]string, error) {
+	var changed []string
+	for idx := range obj.Inlines {
+		inline := &obj.Inlines[idx]
+		rows, ok := children[inline.Field]
+		if !ok {
+			continue
+		}
+		ok, err := inline.save(tx, parent, rows)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			changed = append(changed, inline.Field)
+		}
+	}
+	return changed, nil
+}
+
+// deleteInlines delete all children of parent.
+func (obj *AdminObject) deleteInlines(tx *gorm.DB, parent any) error {
+	for idx := range obj.Inlines {
+		inline := &obj.Inlines[idx]
+		err := tx.Where(inline.parentConds(parent)).Delete(reflect.New(inline.relation.FieldSchema.ModelType).Interface()).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parentConds return the foreign key values of the children, such as {"object_type": "group", "object_id": 1}.
+func (inline *AdminInline) parentConds(parent any) map[string]any {
+	pv := reflect.Indirect(reflect.ValueOf(parent))
+	conds := map[string]any{}
+	for _, ref := range inline.relation.References {
+		if ref.OwnPrimaryKey {
+			conds[ref.ForeignKey.DBName], _ = ref.PrimaryKey.ValueOf(context.Background(), pv)
+		} else if ref.PrimaryValue != "" {
+			conds[ref.ForeignKey.DBName] = ref.PrimaryValue
+		}
+	}
+	return conds
+}
+
+// childKey return the primary values of the child as text, empty if the child is not saved.
+// The json numbers are float64, they are formatted without the exponent, such as 1000000.
+func (inline *AdminInline) childKey(values func(f *schema.Field) any) string {
+	var keys []string
+	for _, f := range inline.relation.FieldSchema.PrimaryFields {
+		var v string
+		if fv, ok := values(f).(float64); ok {
+			v = strconv.FormatFloat(fv, 'f', -1, 64)
+		} else {
+			v = fmt.Sprintf("%v", values(f))
+		}
+		if v == "" || v == "0" || v == "<nil>" {
+			return ""
+		}
+		keys = append(keys, v)
+	}
+	return strings.Join(keys, ",")
+}
+
+// save create, update and delete the children of parent to match rows, return true if any child is changed.
+// The rows with primary values update the exist children, others are created.
+func (inline *AdminInline) save(tx *gorm.DB, parent any, rows []map[string]any) (bool, error) {
+	conds := inline.parentConds(parent)
+	modelType := inline.relation.FieldSchema.ModelType
+
+	exists := reflect.New(reflect.SliceOf(modelType))
+	if err := tx.Where(conds).Find(exists.Interface()).Error; err != nil {
+		return false, err
+	}
+	olds := map[string]reflect.Value{}
+	for i := 0; i < exists.Elem().Len(); i++ {
+		elm := exists.Elem().Index(i)
+		key := inline.childKey(func(f *schema.Field) any {
+			v, _ := f.ValueOf(context.Background(), elm)
+			return v
+		})
+		olds[key] = elm.Addr()
+	}
+
+	changed := false
+	for _, row := range rows {
+		key := inline.childKey(func(f *schema.Field) any {
+			return row[f.DBName]
+		})
+		elm := reflect.New(modelType)
+		var old any
+		if key != "" {
+			v, ok := olds[key]
+			if !ok {
+				return false, fmt.Errorf("%w: %s %s not found", ErrInvalidInline, inline.Field, key)
+			}
+			delete(olds, key)
+			elm.Elem().Set(v.Elem())
+			old = v.Interface()
+		}
+
+		val, err := inline.object.UnmarshalFrom(elm, nil, row)
+		if err != nil {
+			return false, fmt.Errorf("%w: %s %v", ErrInvalidInline, inline.Field, err)
+		}
+		for _, ref := range inline.relation.References {
+			if err := ref.ForeignKey.Set(context.Background(), elm.Elem(), conds[ref.ForeignKey.DBName]); err != nil {
+				return false, err
+			}
+		}
+
+		if old == nil {
+			err = tx.Create(val).Error
+			changed = true
+		} else if len(inline.object.changedFields(old, val, row)) > 0 {
+			err = tx.Save(val).Error
+			changed = true
+		}
+		if err != nil {
+			return false, err
+		}
+	}
+
+	for _, v := range olds {
+		if err := tx.Delete(v.Interface()).Error; err != nil {
+			return false, err
+		}
+		changed = true
+	}
+	return changed, nil
+}
+
+// marshal render the children with the fields of the child object.
+func (inline *AdminInline) marshal(c *gin.Context, children reflect.Value) ([]map[string]any, error) {
+	items := make([]map[string]any, 0, children.Len())
+	for i := 0; i < children.Len(); i++ {
+		item, err := inline.object.MarshalOne(c, children.Index(i).Interface())
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// abortWithRelationError abort with 400 for the invalid inline children and associations, 500 for others.
+func abortWithRelationError(c *gin.Context, err error) {
+	if errors.Is(err, ErrInvalidInline) || errors.Is(err, ErrInvalidAssociation) || errors.Is(err, ErrAssociationNotFound) {
+		AbortWithJSONError(c, http.StatusBadRequest, err)
+		return
+	}
+	AbortWithJSONError(c, http.StatusInternalServerError, err)
+}
@@ -0,0 +1,126 @@
+package carrot
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminInlineBuild(t *testing.T) {
+	db, _ := InitDatabase(nil, "", "")
+	obj := AdminObject{Model: &Group{}, Name: "Group", Inlines: []AdminInline{{Field: "Extra"}}}
+	assert.Nil(t, obj.Build(db))
+
+	inline := obj.getInline("extra")
+	assert.NotNil(t, inline)
+	assert.Equal(t, "Extra", inline.Label)
+	assert.Equal(t, []string{"id"}, inline.PrimaryKeys)
+	// the foreign keys to the parent are not editable
+	assert.Equal(t, []string{"key", "value"}, inline.Editables)
+	for _, f := range inline.Fields {
+		assert.NotEqual(t, "object_id", f.Name)
+		assert.NotEqual(t, "object_type", f.Name)
+	}
+
+	obj = AdminObject{Model: &Group{}, Name: "Group", Inlines: []AdminInline{{Field: "Name"}}}
+	assert.ErrorIs(t, obj.Build(db), ErrInvalidInline)
+}
+
+func TestAdminInline(t *testing.T) {
+	_, db, client := createAdminTest()
+
+	var group Group
+	err := client.CallPut("/admin/group/", gin.H{
+		"name": "staff",
+		"extra": []gin.H{
+			{"key": "k1", "value": "v1"},
+			{"key": "k2", "value": "v2"},
+		},
+	}, nil)
+	assert.Nil(t, err)
+	err = db.Where("name", "staff").First(&group).Error
+	assert.Nil(t, err)
+
+	member := GroupMember{GroupID: group.ID, Role: GroupRoleMember, Extra: []GroupExtra{{Key: "k1", Value: "member"}}}
+	db.Create(&member)
+
+	loadExtras := func() []GroupExtra {
+		var extras []GroupExtra
+		db.Where("object_type", "group").Where("object_id", group.ID).Order("id").Find(&extras)
+		return extras
+	}
+	extras := loadExtras()
+	assert.Equal(t, 2, len(extras))
+	assert.Equal(t, "k1", extras[0].Key)
+	assert.Equal(t, "v2", extras[1].Value)
+
+	keys := fmt.Sprintf("?id=%d", group.ID)
+	var item map[string]any
+	err = client.CallPost("/admin/group/"+keys, nil, &item)
+	assert.Nil(t, err)
+	children := item["extra"].([]any)
+	assert.Equal(t, 2, len(children))
+	assert.Equal(t, float64(extras[0].ID), children[0].(map[string]any)["id"])
+
+	// update k1, remove k2 and add k3
+	err = client.CallPatch("/admin/group/"+keys, gin.H{
+		"name": "staff2",
+		"extra": []gin.H{
+			{"id": extras[0].ID, "key": "k1", "value": "new"},
+			{"key": "k3", "value": "v3"},
+		},
+	}, &item)
+	assert.Nil(t, err)
+	assert.Equal(t, "staff2", item["name"])
+	assert.Equal(t, 2, len(item["extra"].([]any)))
+	extras = loadExtras()
+	assert.Equal(t, 2, len(extras))
+	assert.Equal(t, "new", extras[0].Value)
+	assert.Equal(t, "k3", extras[1].Key)
+
+	var log AdminLog
+	db.Where("object_type", "groups").Where("action", AdminLogUpdate).Last(&log)
+	assert.Equal(t, "name,extra", log.Fields)
+
+	// the children are not changed if extra is not in the form
+	err = client.CallPatch("/admin/group/"+keys, gin.H{"name": "staff3"}, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(loadExtras()))
+
+	// the child of other parent can not be updated, the parent is not changed
+	err = client.CallPatch("/admin/group/"+keys, gin.H{
+		"name":  "mock",
+		"extra": []gin.H{{"id": member.Extra[0].ID, "key": "k1", "value": "hack"}},
+	}, nil)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), ErrInvalidInline.Error())
+	db.First(&group, group.ID)
+	assert.Equal(t, "staff3", group.Name)
+	assert.Equal(t, 2, len(loadExtras()))
+
+	err = client.CallPatch("/admin/group/"+keys, gin.H{"extra": "mock"}, nil)
+	assert.NotNil(t, err)
+
+	// the large ids are decoded as float64 from json
+	large := GroupExtra{ID: 1000000, ObjectType: "group", ObjectID: group.ID, Key: "k4", Value: "v4"}
+	db.Create(&large)
+	err = client.CallPatch("/admin/group/"+keys, gin.H{
+		"extra": []gin.H{{"id": large.ID, "key": "k4", "value": "large"}},
+	}, nil)
+	assert.Nil(t, err)
+	extras = loadExtras()
+	assert.Equal(t, 1, len(extras))
+	assert.Equal(t, "large", extras[0].Value)
+
+	var ok bool
+	err = client.CallDelete("/admin/group/"+keys, nil, &ok)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(loadExtras()))
+
+	var memberExtra GroupExtra
+	err = db.Where("object_type", "member").First(&memberExtra).Error
+	assert.Nil(t, err)
+	assert.Equal(t, "member", memberExtra.Value)
+}
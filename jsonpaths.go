@@ -0,0 +1,104 @@
+package carrot
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var jsonPathSegment = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// splitJSONPath split the dotted name into the field and the json path inside it,
+// such as "profile.country" => "profile", ["country"]. Names without dot have no path.
+func splitJSONPath(name string) (string, []string, error) {
+	parts := strings.Split(name, ".")
+	for _, p := range parts[1:] {
+		if !jsonPathSegment.MatchString(p) {
+			return "", nil, fmt.Errorf("invalid json path %q", name)
+		}
+	}
+	return parts[0], parts[1:], nil
+}
+
+// jsonPathAllowed check the dotted name of field is whitelisted, "Profile.*" allows all paths of Profile.
+func jsonPathAllowed(allows map[string]struct{}, field string, path []string) bool {
+	if _, ok := allows[field+"."+strings.Join(path, ".")]; ok {
+		return true
+	}
+	_, ok := allows[field+".*"]
+	return ok
+}
+
+// jsonPathColumn return the SQL expression of the value at path inside the json column, such as:
+//
+//	sqlite:   json_extract(`users`.`profile`, '$.country')
+//	mysql:    JSON_UNQUOTE(JSON_EXTRACT(`users`.`profile`, '$.country'))
+//	postgres: ("users"."profile"::jsonb #>> '{country}')
+//
+// The path segments must be checked by splitJSONPath.
+func jsonPathColumn(db *gorm.DB, table, column string, path []string) string {
+	switch db.Dialector.Name() {
+	case "postgres":
+		return fmt.Sprintf(`("%s"."%s"::jsonb #>> '{%s}')`, table, column, strings.Join(path, ","))
+	case "mysql":
+		return fmt.Sprintf("JSON_UNQUOTE(JSON_EXTRACT(`%s`.`%s`, '$.%s'))", table, column, strings.Join(path, "."))
+	default:
+		return fmt.Sprintf("json_extract(`%s`.`%s`, '$.%s')", table, column, strings.Join(path, "."))
+	}
+}
+
+// jsonPathFilterColumn cast the json path expression to number if the filter value is a number or numbers,
+// the postgres and mysql expressions are text, and "10" < "9" if compared as text.
+// The sqlite json_extract return the number as is. The orders of json paths are always compared as text.
+func jsonPathFilterColumn(db *gorm.DB, column string, value any) string {
+	if !isNumberValue(value) {
+		return column
+	}
+	switch db.Dialector.Name() {
+	case "postgres":
+		return fmt.Sprintf("(%s)::numeric", column)
+	case "mysql":
+		return fmt.Sprintf("CAST(%s AS DECIMAL(65,30))", column)
+	default:
+		return column
+	}
+}
+
+// isNumberValue check v is a number, or a non-empty slice of numbers, such as the values of "in" and "between".
+func isNumberValue(v any) bool {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Slice, reflect.Array:
+		if rv.Len() == 0 {
+			return false
+		}
+		for i := 0; i < rv.Len(); i++ {
+			if !isNumberValue(rv.Index(i).Interface()) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// checkFieldPaths is like checkFieldNames, and allows the dotted json paths, such as "Profile.country".
+func checkFieldPaths(rt reflect.Type, kind string, names []string) error {
+	for _, name := range names {
+		field, _, err := splitJSONPath(strings.TrimSuffix(name, ".*"))
+		if err != nil {
+			return fmt.Errorf("%s: %v", rt.Name(), err)
+		}
+		if err := checkFieldNames(rt, kind, []string{field}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package carrot
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type jsonPathCustomer struct {
+	ID      uint    `json:"id" gorm:"primarykey"`
+	Name    string  `json:"name" gorm:"size:40"`
+	Profile Profile `json:"profile"`
+}
+
+func TestJSONPathColumn(t *testing.T) {
+	field, path, err := splitJSONPath("profile.extra.level")
+	assert.Nil(t, err)
+	assert.Equal(t, "profile", field)
+	assert.Equal(t, []string{"extra", "level"}, path)
+	_, path, err = splitJSONPath("name")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(path))
+	for _, name := range []string{"profile.", "profile.a'b", "profile.a b", "profile..a"} {
+		_, _, err = splitJSONPath(name)
+		assert.NotNil(t, err, name)
+	}
+
+	db, _ := InitDatabase(nil, "", "")
+	assert.Equal(t, "json_extract(`customers`.`profile`, '$.extra.level')", jsonPathColumn(db, "customers", "profile", []string{"extra", "level"}))
+	mysqlDB := &gorm.DB{Config: &gorm.Config{Dialector: mysql.Dialector{}}}
+	assert.Equal(t, "JSON_UNQUOTE(JSON_EXTRACT(`customers`.`profile`, '$.country'))", jsonPathColumn(mysqlDB, "customers", "profile", []string{"country"}))
+	pgDB := &gorm.DB{Config: &gorm.Config{Dialector: postgres.Dialector{}}}
+	assert.Equal(t, `("customers"."profile"::jsonb #>> '{extra,level}')`, jsonPathColumn(pgDB, "customers", "profile", []string{"extra", "level"}))
+
+	// the numbers are compared as numbers
+	assert.Equal(t, `(expr)::numeric`, jsonPathFilterColumn(pgDB, "expr", float64(10)))
+	assert.Equal(t, `(expr)::numeric`, jsonPathFilterColumn(pgDB, "expr", []any{1, 2.5}))
+	assert.Equal(t, `CAST(expr AS DECIMAL(65,30))`, jsonPathFilterColumn(mysqlDB, "expr", 10))
+	assert.Equal(t, `expr`, jsonPathFilterColumn(mysqlDB, "expr", "10"))
+	assert.Equal(t, `expr`, jsonPathFilterColumn(pgDB, "expr", []any{1, "a"}))
+	assert.Equal(t, `expr`, jsonPathFilterColumn(db, "expr", 10))
+
+	allows := map[string]struct{}{"Profile.country": {}}
+	assert.True(t, jsonPathAllowed(allows, "Profile", []string{"country"}))
+	assert.False(t, jsonPathAllowed(allows, "Profile", []string{"city"}))
+	allows["Profile.*"] = struct{}{}
+	assert.True(t, jsonPathAllowed(allows, "Profile", []string{"city"}))
+}
+
+func TestWebObjectJSONPath(t *testing.T) {
+	db, _ := InitDatabase(nil, "", "")
+	db.AutoMigrate(jsonPathCustomer{})
+	db.Create(&jsonPathCustomer{ID: 1, Name: "alice", Profile: Profile{Country: "CN", City: "Beijing", Extra: map[string]any{"level": 10}}})
+	db.Create(&jsonPathCustomer{ID: 2, Name: "bob", Profile: Profile{Country: "US", City: "Boston", Extra: map[string]any{"level": 1}}})
+	db.Create(&jsonPathCustomer{ID: 3, Name: "carol", Profile: Profile{Country: "CN", City: "Shanghai", Extra: map[string]any{"level": 2}}})
+
+	r := gin.New()
+	r.Use(WithGormDB(db))
+	webobject := WebObject{
+		Model:       jsonPathCustomer{},
+		Name:        "customer",
+		Filterables: []string{"Name", "Profile.country", "Profile.extra.level"},
+		Orderables:  []string{"Profile.city"},
+	}
+	err := webobject.RegisterObject(&r.RouterGroup)
+	assert.Nil(t, err)
+	client := NewTestClient(r)
+
+	var result QueryResultOf[jsonPathCustomer]
+	err = client.CallPost("/customer", gin.H{
+		"filters": []Filter{{Name: "profile.country", Op: FilterOpEqual, Value: "CN"}},
+		"orders":  []Order{{Name: "profile.city", Op: OrderOpDesc}},
+	}, &result)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, result.TotalCount)
+	assert.Equal(t, "carol", result.Items[0].Name)
+	assert.Equal(t, "alice", result.Items[1].Name)
+
+	err = client.CallPost("/customer", gin.H{
+		"filters": []Filter{{Name: "profile.extra.level", Op: FilterOpGreaterOrEqual, Value: 2}},
+	}, &result)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, result.TotalCount)
+
+	err = client.CallPost("/customer", gin.H{
+		"filters": []Filter{{Name: "profile.extra.level", Op: FilterOpGreater, Value: 9}},
+	}, &result)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, result.TotalCount)
+	assert.Equal(t, "alice", result.Items[0].Name)
+
+	// not whitelisted path is ignored
+	err = client.CallPost("/customer", gin.H{
+		"filters": []Filter{{Name: "profile.city", Op: FilterOpEqual, Value: "Boston"}},
+	}, &result)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, result.TotalCount)
+
+	w := client.Post(http.MethodPost, "/customer", []byte(`{"filters":[{"name":"profile.country') OR 1=1 --","op":"=","value":"CN"}]}`))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	invalid := WebObject{Model: jsonPathCustomer{}, Filterables: []string{"Address.country"}}
+	assert.NotNil(t, invalid.Build())
+}
+
+func TestAdminObjectJSONPath(t *testing.T) {
+	db, _ := InitDatabase(nil, "", "")
+	db.AutoMigrate(jsonPathCustomer{})
+	db.Create(&jsonPathCustomer{ID: 1, Name: "alice", Profile: Profile{Country: "CN", City: "Beijing"}})
+	db.Create(&jsonPathCustomer{ID: 2, Name: "bob", Profile: Profile{Country: "US", City: "Boston"}})
+
+	obj := AdminObject{
+		Model:       &jsonPathCustomer{},
+		Name:        "Customer",
+		Filterables: []string{"Profile.country"},
+		Orderables:  []string{"Profile.*"},
+		Orders:      []Order{{"Profile.city", OrderOpDesc}},
+	}
+	err := obj.Build(db)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"profile.country"}, obj.Filterables)
+	assert.Equal(t, "profile.city", obj.Orders[0].Name)
+
+	r, err := obj.QueryObjects(db, &QueryForm{Limit: 10}, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, r.TotalCount)
+	assert.Equal(t, "bob", r.Items[0]["name"])
+
+	r, err = obj.QueryObjects(db, &QueryForm{Limit: 10, Filters: []Filter{{Name: "profile.country", Op: FilterOpEqual, Value: "CN"}}}, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, r.TotalCount)
+	assert.Equal(t, "alice", r.Items[0]["name"])
+
+	_, err = obj.QueryObjects(db, &QueryForm{Limit: 10, Filters: []Filter{{Name: "profile.city", Op: FilterOpEqual, Value: "Boston"}}}, nil)
+	assert.NotNil(t, err)
+}
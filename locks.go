@@ -0,0 +1,122 @@
+package carrot
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// HeaderIfMatch carry the lock value of the object when it was loaded, such as the updated_at or version.
+const HeaderIfMatch = "If-Match"
+
+var ErrInvalidLockField = errors.New("invalid lock field")
+var ErrObjectChanged = errors.New("object has been changed, please reload it")
+
+// buildLockField check the LockField is a time or an integer version field, and use the column name as LockField.
+func (obj *AdminObject) buildLockField(db *gorm.DB) error {
+	if obj.LockField == "" {
+		return nil
+	}
+	f, ok := obj.modelElem.FieldByName(obj.LockField)
+	if !ok {
+		return fmt.Errorf("%w: %s not found in %s", ErrInvalidLockField, obj.LockField, obj.Name)
+	}
+	if f.Type != reflect.TypeOf(time.Time{}) && !isVersionType(f.Type) {
+		return fmt.Errorf("%w: %s must be time.Time or integer", ErrInvalidLockField, obj.LockField)
+	}
+	obj.lockFieldName = f.Name
+	obj.LockField = db.NamingStrategy.ColumnName(obj.tableName, f.Name)
+	return nil
+}
+
+func isVersionType(rt reflect.Type) bool {
+	switch rt.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+// checkLock return ErrObjectChanged if the If-Match header not matches the lock value of val,
+// the check is skipped if the header is empty.
+func (obj *AdminObject) checkLock(c *gin.Context, val any) error {
+	expect := strings.Trim(c.GetHeader(HeaderIfMatch), `"`)
+	if obj.lockFieldName == "" || expect == "" {
+		return nil
+	}
+	v := reflect.Indirect(reflect.ValueOf(val)).FieldByName(obj.lockFieldName).Interface()
+	if t, ok := v.(time.Time); ok {
+		et, err := time.Parse(time.RFC3339Nano, expect)
+		if err != nil || !t.Equal(et) {
+			return ErrObjectChanged
+		}
+		return nil
+	}
+	if fmt.Sprintf("%v", v) != expect {
+		return ErrObjectChanged
+	}
+	return nil
+}
+
+// updateFields update the changed fields of old to the values of val, the other columns are not touched.
+// The lock field is checked in the same statement, and the version is increased.
+func (obj *AdminObject) updateFields(tx *gorm.DB, keys map[string]any, old, val any, fields []string) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(fields)+1)
+	for _, field := range obj.Fields {
+		for _, name := range fields {
+			if field.Name != name {
+				continue
+			}
+			if field.Foreign != nil {
+				names = append(names, field.Foreign.foreignKey)
+			} else {
+				names = append(names, field.fieldName)
+			}
+		}
+	}
+
+	tx = tx.Model(old).Where(keys)
+	ov := reflect.Indirect(reflect.ValueOf(old)).FieldByName(obj.lockFieldName)
+	locked := ov.IsValid()
+	if locked {
+		tx = tx.Where(clause.Eq{Column: clause.Column{Name: obj.LockField}, Value: ov.Interface()})
+	}
+	if locked && isVersionType(ov.Type()) {
+		nv := reflect.Indirect(reflect.ValueOf(val)).FieldByName(obj.lockFieldName)
+		if ov.CanInt() {
+			nv.SetInt(ov.Int() + 1)
+		} else {
+			nv.SetUint(ov.Uint() + 1)
+		}
+		names = append(names, obj.lockFieldName)
+	}
+
+	result := tx.Select(names).Updates(val)
+	if result.Error != nil {
+		return result.Error
+	}
+	if locked && result.RowsAffected == 0 {
+		return ErrObjectChanged
+	}
+	return nil
+}
+
+// abortWithUpdateError abort with 409 if the object has been changed by others.
+func abortWithUpdateError(c *gin.Context, err error) {
+	if errors.Is(err, ErrObjectChanged) {
+		AbortWithJSONError(c, http.StatusConflict, err)
+		return
+	}
+	abortWithRelationError(c, err)
+}
@@ -0,0 +1,143 @@
+package carrot
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type testDoc struct {
+	ID      uint   `json:"id" gorm:"primaryKey"`
+	Title   string `json:"title" gorm:"size:100"`
+	Body    string `json:"body"`
+	Version int    `json:"version"`
+}
+
+func TestAdminLockField(t *testing.T) {
+	db, _ := InitDatabase(nil, "", "")
+	obj := AdminObject{Model: &testDoc{}, Name: "Doc", LockField: "Version"}
+	assert.Nil(t, obj.Build(db))
+	assert.Equal(t, "version", obj.LockField)
+
+	obj = AdminObject{Model: &testDoc{}, Name: "Doc", LockField: "Title"}
+	assert.ErrorIs(t, obj.Build(db), ErrInvalidLockField)
+	obj = AdminObject{Model: &testDoc{}, Name: "Doc", LockField: "Mock"}
+	assert.ErrorIs(t, obj.Build(db), ErrInvalidLockField)
+}
+
+func TestAdminPartialUpdate(t *testing.T) {
+	r := gin.Default()
+	db, _ := InitDatabase(nil, "", "")
+	InitCarrot(db, r)
+	MakeMigrates(db, []any{&testDoc{}})
+	db.Create(&testDoc{Title: "hello", Body: "world"})
+
+	objs := append(GetCarrotAdminObjects(), AdminObject{Model: &testDoc{}, Name: "Doc", LockField: "Version"})
+	RegisterAdmins(r.Group("/admin"), db, objs)
+	client := NewTestClient(r)
+	authClient(db, client, "bob@restsend.com", "--", true)
+
+	patch := func(path string, lock string, body string) (int, string) {
+		req, _ := http.NewRequest(http.MethodPatch, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		if lock != "" {
+			req.Header.Set(HeaderIfMatch, lock)
+		}
+		w := client.SendReq(path, req)
+		return w.Code, w.Body.String()
+	}
+
+	// the body changed by others is not overwritten
+	db.Model(&testDoc{}).Where("id", 1).Update("body", "other")
+	code, body := patch("/admin/doc/?id=1", "0", `{"title":"hello2"}`)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Contains(t, body, `"version":1`)
+
+	var doc testDoc
+	db.First(&doc, 1)
+	assert.Equal(t, "hello2", doc.Title)
+	assert.Equal(t, "other", doc.Body)
+	assert.Equal(t, 1, doc.Version)
+
+	code, body = patch("/admin/doc/?id=1", "0", `{"title":"hello3"}`)
+	assert.Equal(t, http.StatusConflict, code)
+	assert.Contains(t, body, ErrObjectChanged.Error())
+
+	// the version is increased without If-Match too
+	code, _ = patch("/admin/doc/?id=1", "", `{"title":"hello3"}`)
+	assert.Equal(t, http.StatusOK, code)
+	db.First(&doc, 1)
+	assert.Equal(t, 2, doc.Version)
+
+	// the row is changed after it was loaded
+	obj := AdminObject{Model: &testDoc{}, Name: "Doc", LockField: "Version"}
+	assert.Nil(t, obj.Build(db))
+	old := testDoc{ID: 1, Version: 1}
+	val := testDoc{ID: 1, Title: "stale"}
+	err := obj.updateFields(db, map[string]any{"id": 1}, &old, &val, []string{"title"})
+	assert.ErrorIs(t, err, ErrObjectChanged)
+
+	// lock by the updated_at of group
+	db.Create(&Group{Name: "staff"})
+	var group Group
+	db.Where("name", "staff").First(&group)
+	path := fmt.Sprintf("/admin/group/?id=%d", group.ID)
+	lock := group.UpdatedAt.Format(time.RFC3339Nano)
+	code, _ = patch(path, lock, `{"name":"staff2"}`)
+	assert.Equal(t, http.StatusOK, code)
+	code, _ = patch(path, lock, `{"name":"staff3"}`)
+	assert.Equal(t, http.StatusConflict, code)
+	db.First(&group, group.ID)
+	assert.Equal(t, "staff2", group.Name)
+
+	// the updated_at is checked in the same statement
+	groupObj := AdminObject{Model: &Group{}, Name: "Group", LockField: "UpdatedAt"}
+	assert.Nil(t, groupObj.Build(db))
+	staleGroup := group
+	staleGroup.UpdatedAt = group.UpdatedAt.Add(-time.Second)
+	newGroup := group
+	newGroup.Name = "stale"
+	err = groupObj.updateFields(db, map[string]any{"id": group.ID}, &staleGroup, &newGroup, []string{"name"})
+	assert.ErrorIs(t, err, ErrObjectChanged)
+	db.First(&group, group.ID)
+	assert.Equal(t, "staff2", group.Name)
+}
+
+func TestAdminUpdateByHook(t *testing.T) {
+	r := gin.Default()
+	db, _ := InitDatabase(nil, "", "")
+	InitCarrot(db, r)
+	MakeMigrates(db, []any{&testDoc{}})
+	db.Create(&testDoc{Title: "hello", Body: "world"})
+
+	objs := append(GetCarrotAdminObjects(), AdminObject{
+		Model: &testDoc{},
+		Name:  "Doc",
+		BeforeUpdate: func(db *gorm.DB, c *gin.Context, obj any, vals map[string]any) error {
+			obj.(*testDoc).Body = "by hook"
+			return nil
+		},
+	})
+	RegisterAdmins(r.Group("/admin"), db, objs)
+	client := NewTestClient(r)
+	authClient(db, client, "bob@restsend.com", "--", true)
+
+	var doc testDoc
+	err := client.CallPatch("/admin/doc/?id=1", gin.H{"title": "hello2"}, &doc)
+	assert.Nil(t, err)
+	assert.Equal(t, "by hook", doc.Body)
+
+	db.First(&doc, 1)
+	assert.Equal(t, "hello2", doc.Title)
+	assert.Equal(t, "by hook", doc.Body)
+
+	var log AdminLog
+	db.Where("action", AdminLogUpdate).Last(&log)
+	assert.Equal(t, "title,body", log.Fields)
+}
@@ -119,7 +119,7 @@ type GroupMember struct {
 	User        User         `json:"user"`
 	GroupID     uint         `json:"-"`
 	Group       Group        `json:"group"`
-	Role        string       `json:"role" gorm:"size:100"`
+	Role        string       `json:"role" gorm:"size:100" carrot:"enum=group_role"`
 	Description string       `json:"description,omitempty"`
 	Extra       []GroupExtra `gorm:"polymorphic:Object;polymorphicValue:member"`
 }
@@ -178,6 +178,9 @@ func InitMigrate(db *gorm.DB) error {
 		&Group{},
 		&GroupMember{},
 		&GroupExtra{},
+		&ObjectPermission{},
+		&AdminLog{},
+		&AdminJob{},
 	})
 }
 
@@ -38,6 +38,14 @@ const (
 	OrderOpAsc  = "asc"
 )
 
+const (
+	CountModeExact     = "exact"     // COUNT(*) with all filters, the default
+	CountModeEstimated = "estimated" // Table statistics, fallback to exact if the query has conditions
+	CountModeNone      = "none"      // Skip counting
+)
+
+var ErrInvalidCountMode = errors.New("invalid count mode")
+
 const (
 	GET    = 1 << 1
 	CREATE = 1 << 2
@@ -58,10 +66,11 @@ type (
 )
 
 type QueryView struct {
-	Path    string `json:"path"`
-	Method  string `json:"method"`
-	Desc    string `json:"desc"`
-	Prepare PrepareQuery
+	Path              string `json:"path"`
+	Method            string `json:"method"`
+	Desc              string `json:"desc"`
+	Prepare           PrepareQuery
+	BeforeQueryRender BeforeQueryRenderFunc // Render the result of the view, default is BeforeQueryRender of the object
 }
 
 type WebObjectPrimaryField struct {
@@ -81,6 +90,9 @@ type WebObject struct {
 	Filterables       []string
 	Orderables        []string
 	Searchables       []string
+	OwnerField        string        // Owner user id field, enables row-level access control, such as "OwnerID"
+	StateMachine      *StateMachine // State field can only be changed by transitions
+	Associations      []string      // Many2many fields can be added and removed by the primary keys, such as "Tags"
 	GetDB             GetDB
 	PrepareQuery      PrepareQuery
 	BeforeCreate      BeforeCreateFunc
@@ -104,17 +116,19 @@ type WebObject struct {
 	// Map json tag to field kind. such as:
 	// UUID string `json:"id"` => {"id": string}
 	jsonToKinds map[string]reflect.Kind
+	// Map json tag to the registered enum of the field.
+	jsonToEnums map[string]*Enum
 }
 
 type Filter struct {
 	isTimeType bool   `json:"-"`
-	Name       string `json:"name"`
+	Name       string `json:"name"` // Field name or json path, such as "profile.country"
 	Op         string `json:"op"`
 	Value      any    `json:"value"`
 }
 
 type Order struct {
-	Name string `json:"name"`
+	Name string `json:"name"` // Field name or json path, such as "profile.country"
 	Op   string `json:"op"`
 }
 
@@ -124,9 +138,10 @@ type QueryForm struct {
 	Keyword      string   `json:"keyword,omitempty"`
 	Filters      []Filter `json:"filters,omitempty"`
 	Orders       []Order  `json:"orders,omitempty"`
-	ForeignMode  bool     `json:"foreign"` // for foreign key
-	ViewFields   []string `json:"-"`       // for view
-	searchFields []string `json:"-"`       // for keyword
+	ForeignMode  bool     `json:"foreign"`             // for foreign key
+	CountMode    string   `json:"countMode,omitempty"` // exact, estimated or none
+	ViewFields   []string `json:"-"`                   // for view
+	searchFields []string `json:"-"`                   // for keyword
 }
 
 type QueryResult struct {
@@ -134,12 +149,18 @@ type QueryResult struct {
 	Pos        int    `json:"pos,omitempty"`
 	Limit      int    `json:"limit,omitempty"`
 	Keyword    string `json:"keyword,omitempty"`
+	TotalKind  string `json:"totalKind,omitempty"` // exact, estimated or none
 	Items      []any  `json:"items"`
 }
 
 // GetQuery return the combined filter SQL statement.
 // such as "age >= ?", "name IN ?".
 func (f *Filter) GetQuery() string {
+	return f.queryWith(fmt.Sprintf("`%s`", f.Name))
+}
+
+// queryWith return the filter SQL statement of the column expression.
+func (f *Filter) queryWith(column string) string {
 	var op string
 	switch f.Op {
 	case FilterOpIsNot:
@@ -164,23 +185,28 @@ func (f *Filter) GetQuery() string {
 		op = "LIKE"
 	case FilterOpBetween:
 		op = "BETWEEN"
-		return fmt.Sprintf("`%s` BETWEEN ? AND ?", f.Name)
+		return fmt.Sprintf("%s BETWEEN ? AND ?", column)
 	}
 
 	if op == "" {
 		return ""
 	}
 
-	return fmt.Sprintf("`%s` %s ?", f.Name, op)
+	return fmt.Sprintf("%s %s ?", column, op)
 }
 
 // GetQuery return the combined order SQL statement.
 // such as "id DESC".
 func (f *Order) GetQuery() string {
+	return f.queryWith(f.Name)
+}
+
+// queryWith return the order SQL statement of the column expression.
+func (f *Order) queryWith(column string) string {
 	if f.Op == OrderOpDesc {
-		return f.Name + " DESC"
+		return column + " DESC"
 	}
-	return f.Name + " ASC"
+	return column + " ASC"
 }
 
 func (obj *WebObject) RegisterObject(r *gin.RouterGroup) error {
@@ -219,10 +245,18 @@ func (obj *WebObject) RegisterObject(r *gin.RouterGroup) error {
 
 	if allowMethods&QUERY != 0 {
 		r.POST(p, func(c *gin.Context) {
-			handleQueryObject(c, obj, obj.PrepareQuery)
+			handleQueryObject(c, obj, obj.PrepareQuery, obj.BeforeQueryRender)
 		})
 	}
 
+	if obj.StateMachine != nil {
+		obj.registerTransitions(r, primaryKeyPath)
+	}
+
+	if len(obj.Associations) > 0 {
+		obj.registerAssociations(r, primaryKeyPath)
+	}
+
 	for i := 0; i < len(obj.Views); i++ {
 		v := &obj.Views[i]
 		if v.Path == "" {
@@ -231,8 +265,12 @@ func (obj *WebObject) RegisterObject(r *gin.RouterGroup) error {
 		if v.Method == "" {
 			v.Method = http.MethodPost
 		}
+		beforeQueryRender := v.BeforeQueryRender
+		if beforeQueryRender == nil {
+			beforeQueryRender = obj.BeforeQueryRender
+		}
 		r.Handle(v.Method, filepath.Join(p, v.Path), func(ctx *gin.Context) {
-			handleQueryObject(ctx, obj, v.Prepare)
+			handleQueryObject(ctx, obj, v.Prepare, beforeQueryRender)
 		})
 	}
 
@@ -309,6 +347,43 @@ func (obj *WebObject) checkType(db *gorm.DB, key string, value any) (string, boo
 	return fieldName, true, nil
 }
 
+// isDecimalField return true if the field of json key is Decimal or *Decimal.
+func (obj *WebObject) isDecimalField(key string) bool {
+	f, ok := obj.modelElem.FieldByName(obj.jsonToFields[key])
+	if !ok {
+		return false
+	}
+	rt := f.Type
+	if rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+	return rt == decimalType
+}
+
+// checkEnums check the enum fields of val, zero values are skipped.
+func (obj *WebObject) checkEnums(val any) error {
+	rv := reflect.Indirect(reflect.ValueOf(val))
+	for k, enum := range obj.jsonToEnums {
+		fv := rv.FieldByName(obj.jsonToFields[k])
+		if !fv.IsValid() || fv.IsZero() {
+			continue
+		}
+		v := reflect.Indirect(fv)
+		if v.Kind() == reflect.Slice {
+			for i := 0; i < v.Len(); i++ {
+				if err := enum.Check(v.Index(i).Interface()); err != nil {
+					return fmt.Errorf("%s %w", k, err)
+				}
+			}
+			continue
+		}
+		if err := enum.Check(v.Interface()); err != nil {
+			return fmt.Errorf("%s %w", k, err)
+		}
+	}
+	return nil
+}
+
 func RegisterObject(r *gin.RouterGroup, obj *WebObject) error {
 	return obj.RegisterObject(r)
 }
@@ -339,7 +414,39 @@ func (obj *WebObject) Build() error {
 
 	obj.jsonToFields = make(map[string]string)
 	obj.jsonToKinds = make(map[string]reflect.Kind)
-	obj.parseFields(obj.modelElem)
+	obj.jsonToEnums = make(map[string]*Enum)
+	// Build is called again by the docs of the registered objects
+	obj.primaryKeys = nil
+	obj.uniqueKeys = nil
+	if err := obj.parseFields(obj.modelElem); err != nil {
+		return err
+	}
+
+	obj.Editables = mergeTaggedFields(rt, obj.Editables, TagEdit)
+	obj.Filterables = mergeTaggedFields(rt, obj.Filterables, TagFilter)
+	obj.Orderables = mergeTaggedFields(rt, obj.Orderables, TagOrder)
+	obj.Searchables = mergeTaggedFields(rt, obj.Searchables, TagSearch)
+
+	for kind, names := range map[string][]string{
+		TagEdit:   obj.Editables,
+		TagSearch: obj.Searchables,
+	} {
+		if err := checkFieldNames(rt, kind, names); err != nil {
+			return err
+		}
+	}
+	for kind, names := range map[string][]string{
+		TagFilter: obj.Filterables,
+		TagOrder:  obj.Orderables,
+	} {
+		if err := checkFieldPaths(rt, kind, names); err != nil {
+			return err
+		}
+	}
+
+	if err := obj.checkAssociations(); err != nil {
+		return err
+	}
 
 	if obj.primaryKeys != nil {
 		obj.uniqueKeys = obj.primaryKeys
@@ -352,13 +459,15 @@ func (obj *WebObject) Build() error {
 }
 
 // parseFields parse the following properties according to struct tag:
-// - jsonToFields, jsonToKinds, primaryKeyName, primaryKeyJsonName
-func (obj *WebObject) parseFields(rt reflect.Type) {
+// - jsonToFields, jsonToKinds, jsonToEnums, primaryKeyName, primaryKeyJsonName
+func (obj *WebObject) parseFields(rt reflect.Type) error {
 	for i := 0; i < rt.NumField(); i++ {
 		f := rt.Field(i)
 
 		if f.Anonymous && f.Type.Kind() == reflect.Struct {
-			obj.parseFields(f.Type)
+			if err := obj.parseFields(f.Type); err != nil {
+				return err
+			}
 			continue
 		}
 
@@ -381,6 +490,18 @@ func (obj *WebObject) parseFields(rt reflect.Type) {
 			obj.jsonToKinds[jsonTag] = kind
 		}
 
+		enum, err := lookupFieldEnum(f)
+		if err != nil {
+			return err
+		}
+		if enum != nil && jsonTag != "-" {
+			if jsonTag == "" {
+				obj.jsonToEnums[f.Name] = enum
+			} else {
+				obj.jsonToEnums[jsonTag] = enum
+			}
+		}
+
 		gormTag := strings.ToLower(f.Tag.Get("gorm"))
 		if gormTag == "-" {
 			continue
@@ -402,6 +523,7 @@ func (obj *WebObject) parseFields(rt reflect.Type) {
 			obj.uniqueKeys = append(obj.uniqueKeys, pkField)
 		}
 	}
+	return nil
 }
 
 func getDbConnection(c *gin.Context, objFn GetDB, isCreate bool) (tx *gorm.DB) {
@@ -413,6 +535,19 @@ func getDbConnection(c *gin.Context, objFn GetDB, isCreate bool) (tx *gorm.DB) {
 	return tx.Session(&gorm.Session{})
 }
 
+// checkAccess check current user can access val with permission when OwnerField is set,
+// abort the request and return false if not.
+func (obj *WebObject) checkAccess(c *gin.Context, db *gorm.DB, val any, permission string) bool {
+	if obj.OwnerField == "" {
+		return true
+	}
+	if err := checkObjectAccess(db, val, obj.OwnerField, CurrentUser(c), permission); err != nil {
+		abortWithAccessError(c, err)
+		return false
+	}
+	return true
+}
+
 func handleGetObject(c *gin.Context, obj *WebObject) {
 	keys, err := obj.getPrimaryValues(c)
 	if err != nil {
@@ -432,6 +567,10 @@ func handleGetObject(c *gin.Context, obj *WebObject) {
 		return
 	}
 
+	if !obj.checkAccess(c, db, val, PermissionRead) {
+		return
+	}
+
 	if obj.BeforeRender != nil {
 		rr, err := obj.BeforeRender(db, c, val)
 		if err != nil {
@@ -464,7 +603,21 @@ func handleCreateObject(c *gin.Context, obj *WebObject) {
 		}
 	}
 
+	if err := obj.checkEnums(val); err != nil {
+		AbortWithJSONError(c, http.StatusBadRequest, err)
+		return
+	}
+
 	db := getDbConnection(c, obj.GetDB, true)
+	if obj.OwnerField != "" {
+		user := CurrentUser(c)
+		if user == nil {
+			AbortWithJSONError(c, http.StatusUnauthorized, ErrUnauthorized)
+			return
+		}
+		setObjectOwner(val, obj.OwnerField, user)
+	}
+
 	if obj.BeforeCreate != nil {
 		if err := obj.BeforeCreate(db, c, val); err != nil {
 			AbortWithJSONError(c, http.StatusBadRequest, err)
@@ -503,6 +656,23 @@ func handleEditObject(c *gin.Context, obj *WebObject) {
 		delete(inputVals, k.JSONName)
 	}
 
+	if obj.StateMachine != nil {
+		for k := range inputVals {
+			if obj.jsonToFields[k] == obj.StateMachine.Field {
+				AbortWithJSONError(c, http.StatusBadRequest, ErrStateNotEditable)
+				return
+			}
+		}
+	}
+	if obj.OwnerField != "" {
+		for k := range inputVals {
+			if obj.jsonToFields[k] == obj.OwnerField {
+				AbortWithJSONError(c, http.StatusBadRequest, ErrOwnerNotEditable)
+				return
+			}
+		}
+	}
+
 	for k, v := range inputVals {
 		if v == nil {
 			continue
@@ -513,6 +683,18 @@ func handleEditObject(c *gin.Context, obj *WebObject) {
 			AbortWithJSONError(c, http.StatusBadRequest, fmt.Errorf("%s type not match", k))
 			return
 		}
+		if enum, ok := obj.jsonToEnums[k]; ok {
+			if err := enum.Check(v); err != nil {
+				AbortWithJSONError(c, http.StatusBadRequest, fmt.Errorf("%s %w", k, err))
+				return
+			}
+		}
+		if obj.isDecimalField(k) {
+			if v, err = AsDecimal(v); err != nil {
+				AbortWithJSONError(c, http.StatusBadRequest, fmt.Errorf("%s %w", k, err))
+				return
+			}
+		}
 		if !ok { // ignore invalid field
 			continue
 		}
@@ -538,17 +720,22 @@ func handleEditObject(c *gin.Context, obj *WebObject) {
 	}
 	db = obj.buildPrimaryCondition(db.Model(obj.Model), keys)
 
-	if obj.BeforeUpdate != nil {
+	if obj.BeforeUpdate != nil || obj.OwnerField != "" {
 		val := reflect.New(obj.modelElem).Interface()
 		tx := db.Session(&gorm.Session{})
 		if err := tx.First(val).Error; err != nil {
 			AbortWithJSONError(c, http.StatusNotFound, ErrNotFound)
 			return
 		}
-		if err := obj.BeforeUpdate(db, c, val, inputVals); err != nil {
-			AbortWithJSONError(c, http.StatusBadRequest, err)
+		if !obj.checkAccess(c, db, val, PermissionUpdate) {
 			return
 		}
+		if obj.BeforeUpdate != nil {
+			if err := obj.BeforeUpdate(db, c, val, inputVals); err != nil {
+				AbortWithJSONError(c, http.StatusBadRequest, err)
+				return
+			}
+		}
 	}
 
 	result := db.Updates(vals)
@@ -582,6 +769,10 @@ func handleDeleteObject(c *gin.Context, obj *WebObject) {
 		return
 	}
 
+	if !obj.checkAccess(c, db, val, PermissionDelete) {
+		return
+	}
+
 	if obj.BeforeDelete != nil {
 		if err := obj.BeforeDelete(db, c, val); err != nil {
 			AbortWithJSONError(c, http.StatusBadRequest, err)
@@ -589,16 +780,23 @@ func handleDeleteObject(c *gin.Context, obj *WebObject) {
 		}
 	}
 
-	r = db.Delete(val)
-	if r.Error != nil {
-		AbortWithJSONError(c, http.StatusInternalServerError, r.Error)
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if obj.OwnerField != "" {
+			if err := deleteObjectPermissions(tx, val); err != nil {
+				return err
+			}
+		}
+		return tx.Delete(val).Error
+	})
+	if err != nil {
+		AbortWithJSONError(c, http.StatusInternalServerError, err)
 		return
 	}
 
 	RenderJSON(c, http.StatusOK, true)
 }
 
-func handleQueryObject(c *gin.Context, obj *WebObject, prepareQuery PrepareQuery) {
+func handleQueryObject(c *gin.Context, obj *WebObject, prepareQuery PrepareQuery, beforeQueryRender BeforeQueryRenderFunc) {
 	if prepareQuery == nil {
 		prepareQuery = DefaultPrepareQuery
 	}
@@ -608,6 +806,14 @@ func handleQueryObject(c *gin.Context, obj *WebObject, prepareQuery PrepareQuery
 		return
 	}
 
+	if obj.OwnerField != "" {
+		db, err = scopeObjectAccess(db, obj.Model, obj.OwnerField, CurrentUser(c))
+		if err != nil {
+			abortWithAccessError(c, err)
+			return
+		}
+	}
+
 	namer := db.NamingStrategy
 
 	// Use struct{} makes map like set.
@@ -620,14 +826,36 @@ func handleQueryObject(c *gin.Context, obj *WebObject, prepareQuery PrepareQuery
 		var stripFilters []Filter
 		for i := 0; i < len(form.Filters); i++ {
 			filter := form.Filters[i]
+			name, path, err := splitJSONPath(filter.Name)
+			if err != nil {
+				AbortWithJSONError(c, http.StatusBadRequest, err)
+				return
+			}
 			// Struct must has this field.
-			field, ok := obj.jsonToFields[filter.Name]
+			field, ok := obj.jsonToFields[name]
 			if !ok {
 				continue
 			}
+			if len(path) > 0 {
+				if !jsonPathAllowed(filterFields, field, path) {
+					continue
+				}
+				filter.Name = namer.ColumnName(obj.tableName, field) + "." + strings.Join(path, ".")
+				stripFilters = append(stripFilters, filter)
+				continue
+			}
 			if _, ok := filterFields[field]; !ok {
 				continue
 			}
+			if enum, ok := obj.jsonToEnums[filter.Name]; ok && filter.Value != nil {
+				switch filter.Op {
+				case FilterOpEqual, FilterOpNotEqual, FilterOpIn, FilterOpNotIn:
+					if err := enum.Check(filter.Value); err != nil {
+						AbortWithJSONError(c, http.StatusBadRequest, fmt.Errorf("%s %w", filter.Name, err))
+						return
+					}
+				}
+			}
 
 			if f, ok := obj.modelElem.FieldByName(field); ok {
 				var typeName string = f.Type.Name()
@@ -636,6 +864,12 @@ func handleQueryObject(c *gin.Context, obj *WebObject, prepareQuery PrepareQuery
 				}
 				filter.isTimeType = typeName == "Time" || typeName == "NullTime" || typeName == "DeletedAt"
 			}
+			if obj.isDecimalField(filter.Name) && filter.Value != nil {
+				if filter.Value, err = asDecimalValues(filter.Value); err != nil {
+					AbortWithJSONError(c, http.StatusBadRequest, fmt.Errorf("%s %w", filter.Name, err))
+					return
+				}
+			}
 			filter.Name = namer.ColumnName(obj.tableName, field)
 			stripFilters = append(stripFilters, filter)
 		}
@@ -652,10 +886,23 @@ func handleQueryObject(c *gin.Context, obj *WebObject, prepareQuery PrepareQuery
 		var stripOrders []Order
 		for i := 0; i < len(form.Orders); i++ {
 			order := form.Orders[i]
-			field, ok := obj.jsonToFields[order.Name]
+			name, path, err := splitJSONPath(order.Name)
+			if err != nil {
+				AbortWithJSONError(c, http.StatusBadRequest, err)
+				return
+			}
+			field, ok := obj.jsonToFields[name]
 			if !ok {
 				continue
 			}
+			if len(path) > 0 {
+				if !jsonPathAllowed(orderFields, field, path) {
+					continue
+				}
+				order.Name = namer.ColumnName(obj.tableName, field) + "." + strings.Join(path, ".")
+				stripOrders = append(stripOrders, order)
+				continue
+			}
 			if _, ok := orderFields[field]; !ok {
 				continue
 			}
@@ -688,8 +935,8 @@ func handleQueryObject(c *gin.Context, obj *WebObject, prepareQuery PrepareQuery
 		return
 	}
 
-	if obj.BeforeQueryRender != nil {
-		obj, err := obj.BeforeQueryRender(db, c, &r)
+	if beforeQueryRender != nil {
+		obj, err := beforeQueryRender(db, c, &r)
 		if err != nil {
 			AbortWithJSONError(c, http.StatusBadRequest, err)
 			return
@@ -724,18 +971,22 @@ func (obj *WebObject) queryObjects(db *gorm.DB, ctx *gin.Context, form *QueryFor
 	tblName := db.NamingStrategy.TableName(obj.tableName)
 
 	for _, v := range form.Filters {
-		if q := v.GetQuery(); q != "" {
+		column := fmt.Sprintf("`%s`.`%s`", tblName, v.Name)
+		if name, path, _ := splitJSONPath(v.Name); len(path) > 0 {
+			column = jsonPathFilterColumn(db, jsonPathColumn(db, tblName, name, path), v.Value)
+		}
+		if q := v.queryWith(column); q != "" {
 			if v.Op == FilterOpLike {
 				if kws, ok := v.Value.([]any); ok {
 					qs := []string{}
 					for _, kw := range kws {
 						k := fmt.Sprintf("\"%%%s%%\"", strings.ReplaceAll(kw.(string), "\"", "\\\""))
-						q := fmt.Sprintf("`%s`.`%s` LIKE %s", tblName, v.Name, k)
+						q := fmt.Sprintf("%s LIKE %s", column, k)
 						qs = append(qs, q)
 					}
 					db = db.Where(strings.Join(qs, " OR "))
 				} else {
-					db = db.Where(fmt.Sprintf("`%s`.%s", tblName, q), fmt.Sprintf("%%%s%%", v.Value))
+					db = db.Where(q, fmt.Sprintf("%%%s%%", v.Value))
 				}
 			} else if v.Op == FilterOpBetween {
 				vt := reflect.ValueOf(v.Value)
@@ -749,18 +1000,20 @@ func (obj *WebObject) queryObjects(db *gorm.DB, ctx *gin.Context, form *QueryFor
 					leftValue = castTime(leftValue)
 					rightValue = castTime(rightValue)
 				}
-				db = db.Where(fmt.Sprintf("`%s`.%s", tblName, q), leftValue, rightValue)
+				db = db.Where(q, leftValue, rightValue)
 			} else {
 				if v.isTimeType {
 					v.Value = castTime(v.Value)
 				}
-				db = db.Where(fmt.Sprintf("`%s`.%s", tblName, q), v.Value)
+				db = db.Where(q, v.Value)
 			}
 		}
 	}
 
 	for _, v := range form.Orders {
-		if q := v.GetQuery(); q != "" {
+		if name, path, _ := splitJSONPath(v.Name); len(path) > 0 {
+			db = db.Order(v.queryWith(jsonPathColumn(db, tblName, name, path)))
+		} else if q := v.GetQuery(); q != "" {
 			db = db.Order(fmt.Sprintf("%s.%s", tblName, q))
 		}
 	}
@@ -782,11 +1035,12 @@ func (obj *WebObject) queryObjects(db *gorm.DB, ctx *gin.Context, form *QueryFor
 	r.Limit = form.Limit
 	r.Keyword = form.Keyword
 
-	var c int64
-	if err := db.Model(obj.Model).Count(&c).Error; err != nil {
+	c, kind, err := countObjects(db.Model(obj.Model), obj.Model, form.CountMode)
+	if err != nil {
 		return r, err
 	}
-	if c <= 0 {
+	r.TotalKind = kind
+	if c <= 0 && kind == CountModeExact {
 		return r, nil
 	}
 	r.TotalCount = int(c)
@@ -816,6 +1070,34 @@ func (obj *WebObject) queryObjects(db *gorm.DB, ctx *gin.Context, form *QueryFor
 	return r, nil
 }
 
+// countObjects count the rows of db according to mode, return the count and the kind of count.
+// Estimated count is only used when db has no conditions, since the statistics cover the whole table.
+func countObjects(db *gorm.DB, model any, mode string) (int64, string, error) {
+	switch mode {
+	case CountModeNone:
+		return 0, CountModeNone, nil
+	case CountModeEstimated:
+		if _, ok := db.Statement.Clauses["WHERE"]; !ok {
+			stmt := &gorm.Statement{DB: db}
+			if err := stmt.Parse(model); err != nil {
+				return 0, "", err
+			}
+			if c, err := EstimateCount(db, stmt.Schema.Table); err == nil {
+				return c, CountModeEstimated, nil
+			}
+		}
+	case "", CountModeExact:
+	default:
+		return 0, "", fmt.Errorf("%w: %s", ErrInvalidCountMode, mode)
+	}
+
+	var c int64
+	if err := db.Count(&c).Error; err != nil {
+		return 0, "", err
+	}
+	return c, CountModeExact, nil
+}
+
 // DefaultPrepareQuery return default QueryForm.
 func DefaultPrepareQuery(db *gorm.DB, c *gin.Context) (*gorm.DB, *QueryForm, error) {
 	var form QueryForm
@@ -831,6 +1113,11 @@ func DefaultPrepareQuery(db *gorm.DB, c *gin.Context) (*gorm.DB, *QueryForm, err
 	if form.Limit <= 0 || form.Limit > DefaultQueryLimit {
 		form.Limit = DefaultQueryLimit
 	}
+	switch form.CountMode {
+	case "", CountModeExact, CountModeEstimated, CountModeNone:
+	default:
+		return nil, nil, fmt.Errorf("%w: %s", ErrInvalidCountMode, form.CountMode)
+	}
 
 	return db, &form, nil
 }
@@ -715,7 +715,7 @@ func initHookTest(t *testing.T) (TestClient, *gorm.DB) {
 		Name:        "user",
 		Model:       UnittestUser{},
 		Editables:   []string{"Name"},
-		Filterables: []string{"Name, Age"},
+		Filterables: []string{"Name", "Age"},
 		Searchables: []string{"Name"},
 		GetDB: func(c *gin.Context, isCreate bool) *gorm.DB {
 			return db
@@ -817,7 +817,7 @@ func TestQueryViews(t *testing.T) {
 		Name:        "user",
 		Model:       UnittestUser{},
 		Editables:   []string{"Name"},
-		Filterables: []string{"Name, Age"},
+		Filterables: []string{"Name", "Age"},
 		Searchables: []string{"Name"},
 		Views: []QueryView{
 			{
@@ -860,7 +860,7 @@ func TestBeforeRender(t *testing.T) {
 		Name:        "user",
 		Model:       UnittestUser{},
 		Editables:   []string{"Name"},
-		Filterables: []string{"Name, Age"},
+		Filterables: []string{"Name", "Age"},
 		Searchables: []string{"Name"},
 		BeforeRender: func(db *gorm.DB, ctx *gin.Context, vptr any) (any, error) {
 			ctx.Redirect(http.StatusMovedPermanently, "/not_found")
@@ -881,3 +881,55 @@ func TestBeforeRender(t *testing.T) {
 	err = client.CallGet("/user/1", nil, nil)
 	assert.Contains(t, err.Error(), "Moved Permanently")
 }
+
+func TestQueryCountMode(t *testing.T) {
+	db, _ := gorm.Open(sqlite.Open("file::memory:"), nil)
+	db.AutoMigrate(UnittestUser{})
+	for i := 0; i < 5; i++ {
+		db.Create(&UnittestUser{Name: fmt.Sprintf("user-%d", i), Age: i})
+	}
+
+	r := gin.New()
+	r.Use(WithGormDB(db))
+	webobject := WebObject{
+		Name:        "user",
+		Model:       UnittestUser{},
+		Filterables: []string{"Age"},
+	}
+	err := webobject.RegisterObject(&r.RouterGroup)
+	assert.Nil(t, err)
+	client := NewTestClient(r)
+
+	var result QueryResult
+	err = client.CallPost("/user", QueryForm{Limit: 2}, &result)
+	assert.Nil(t, err)
+	assert.Equal(t, 5, result.TotalCount)
+	assert.Equal(t, CountModeExact, result.TotalKind)
+
+	result = QueryResult{}
+	err = client.CallPost("/user", QueryForm{Limit: 2, CountMode: CountModeNone}, &result)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, result.TotalCount)
+	assert.Equal(t, CountModeNone, result.TotalKind)
+	assert.Equal(t, 2, len(result.Items))
+
+	// sqlite has no statistics, fallback to exact
+	result = QueryResult{}
+	err = client.CallPost("/user", QueryForm{Limit: 2, CountMode: CountModeEstimated, Filters: []Filter{{Name: "age", Op: FilterOpGreater, Value: 2}}}, &result)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, result.TotalCount)
+	assert.Equal(t, CountModeExact, result.TotalKind)
+
+	w := client.Post(http.MethodPost, "/user", []byte(`{"countMode":"mock"}`))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), ErrInvalidCountMode.Error())
+	_, _, err = countObjects(db.Model(&UnittestUser{}), &UnittestUser{}, "mock")
+	assert.ErrorIs(t, err, ErrInvalidCountMode)
+
+	typed, err := AsQueryResultOf[UnittestUser](&QueryResult{TotalKind: CountModeEstimated})
+	assert.Nil(t, err)
+	assert.Equal(t, CountModeEstimated, typed.TotalKind)
+
+	_, err = EstimateCount(db, "unittest_users")
+	assert.Equal(t, ErrEstimateNotSupported, err)
+}
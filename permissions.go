@@ -0,0 +1,120 @@
+package carrot
+
+import (
+	"errors"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const PermissionAction = "action"
+
+const KeyAdminPermissions = "_carrot_admin_permissions"
+
+var ErrPermissionDenied = errors.New("permission denied")
+
+// GetAdminPermissions return the permissions of user's groups with GroupTypeAdmin, such as:
+//
+//	["users.read", "pages.*"]
+func GetAdminPermissions(db *gorm.DB, user *User) ([]string, error) {
+	groups, err := GetGroupsByUser(db, user)
+	if err != nil {
+		return nil, err
+	}
+	var perms []string
+	for _, g := range groups {
+		if g.Type != GroupTypeAdmin {
+			continue
+		}
+		perms = append(perms, g.Permission.Permissions...)
+	}
+	return perms, nil
+}
+
+// MatchPermission check permission such as "users.update" is granted by perms,
+// "users.*", "*.update" and "*" are wildcards, "users.all" is the same as "users.*".
+// The actions are named "users.action.<path>", "users.action" grants all actions.
+func MatchPermission(perms []string, permission string) bool {
+	object, action, _ := strings.Cut(permission, ".")
+	for _, p := range perms {
+		p = strings.TrimSpace(p)
+		if p == "*" || p == permission {
+			return true
+		}
+		o, a, ok := strings.Cut(p, ".")
+		if !ok {
+			continue
+		}
+		if (o == "*" || o == object) && (a == "*" || a == PermissionAll || a == action) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasPermission check the admin permission of user, such as obj.HasPermission(perms, PermissionUpdate).
+// The object is matched by both Path and PluralName, "user.update" and "users.update" are the same.
+// PermissionUpdate and PermissionDelete imply PermissionRead, the rows must be found to be changed.
+func (obj *AdminObject) HasPermission(perms []string, permission string) bool {
+	permissions := []string{permission}
+	if permission == PermissionRead {
+		permissions = append(permissions, PermissionUpdate, PermissionDelete)
+	}
+	// the registered path is full path, such as "/admin/user/"
+	objPath := path.Base(strings.TrimSuffix(obj.Path, "/"))
+	for _, name := range []string{objPath, strings.ToLower(obj.PluralName)} {
+		if name == "" || name == "." || name == "/" {
+			continue
+		}
+		for _, p := range permissions {
+			if MatchPermission(perms, name+"."+p) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// actionPermission return the permission of the action, such as "action.publish",
+// the actions don't share the names with PermissionUpdate and PermissionDelete.
+func actionPermission(path string) string {
+	return PermissionAction + "." + path
+}
+
+// currentAdminPermissions return the admin permissions of current user, cached in the context.
+func currentAdminPermissions(c *gin.Context, db *gorm.DB, user *User) []string {
+	if c != nil {
+		if v, ok := c.Get(KeyAdminPermissions); ok {
+			return v.([]string)
+		}
+	}
+	perms, err := GetAdminPermissions(db, user)
+	if err != nil {
+		perms = nil
+	}
+	if c != nil {
+		c.Set(KeyAdminPermissions, perms)
+	}
+	return perms
+}
+
+// checkPermission abort with 403 if current user has not the permission, superusers have all permissions.
+func (obj *AdminObject) checkPermission(c *gin.Context, permissions ...string) bool {
+	user := CurrentUser(c)
+	if user != nil && user.IsSuperUser {
+		return true
+	}
+	if user != nil {
+		perms := currentAdminPermissions(c, getDbConnection(c, nil, false), user)
+		for _, p := range permissions {
+			if obj.HasPermission(perms, p) {
+				return true
+			}
+		}
+	}
+	AbortWithJSONError(c, http.StatusForbidden, ErrPermissionDenied)
+	return false
+}
@@ -0,0 +1,161 @@
+package carrot
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestMatchPermission(t *testing.T) {
+	assert.True(t, MatchPermission([]string{"*"}, "user.update"))
+	assert.True(t, MatchPermission([]string{"user.update"}, "user.update"))
+	assert.True(t, MatchPermission([]string{"user.*"}, "user.delete"))
+	assert.True(t, MatchPermission([]string{"user.all"}, "user.delete"))
+	assert.True(t, MatchPermission([]string{"*.create"}, "config.create"))
+	assert.True(t, MatchPermission([]string{"page.read", " user.create "}, "user.create"))
+	assert.False(t, MatchPermission([]string{"user.create"}, "user.update"))
+	assert.False(t, MatchPermission([]string{"page.*"}, "user.update"))
+	assert.False(t, MatchPermission([]string{"user"}, "user.update"))
+	assert.False(t, MatchPermission(nil, "user.update"))
+
+	obj := AdminObject{Path: "user", PluralName: "Users"}
+	assert.True(t, obj.HasPermission([]string{"users.update"}, PermissionUpdate))
+	assert.True(t, obj.HasPermission([]string{"user.update"}, PermissionUpdate))
+	assert.False(t, obj.HasPermission([]string{"configs.update"}, PermissionUpdate))
+
+	obj.Path = "/admin/user/"
+	assert.True(t, obj.HasPermission([]string{"user.update"}, PermissionUpdate))
+}
+
+func createAdminGroup(db *gorm.DB, user *User, groupType string, perms ...string) {
+	group := Group{Name: "staff-" + groupType, Type: groupType, Permission: GroupPermission{Permissions: perms}}
+	db.Create(&group)
+	db.Create(&GroupMember{UserID: user.ID, GroupID: group.ID})
+}
+
+func TestGetAdminPermissions(t *testing.T) {
+	db, _ := InitDatabase(nil, "", "")
+	InitMigrate(db)
+	u, _ := CreateUser(db, "bob@restsend.com", "--")
+
+	createAdminGroup(db, u, GroupTypeAdmin, "user.read", "config.*")
+	createAdminGroup(db, u, GroupTypeApp, "user.delete")
+
+	perms, err := GetAdminPermissions(db, u)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"user.read", "config.*"}, perms)
+
+	obj := AdminObject{Path: "config", PluralName: "Configs", Actions: []AdminAction{{Path: "reload"}}}
+	obj.BuildPermissions(db, u)
+	assert.True(t, obj.Permissions["can_create"])
+	assert.True(t, obj.Permissions["can_delete"])
+	assert.True(t, obj.Permissions["can_reload"])
+
+	obj = AdminObject{Path: "user", PluralName: "Users", Actions: []AdminAction{{Path: "toggle_staff"}}}
+	obj.BuildPermissions(db, u)
+	assert.False(t, obj.Permissions["can_create"])
+	assert.False(t, obj.Permissions["can_update"])
+	assert.False(t, obj.Permissions["can_delete"])
+	assert.False(t, obj.Permissions["can_toggle_staff"])
+
+	createAdminGroup(db, u, GroupTypeAdmin, "user.action.toggle_staff")
+	obj.BuildPermissions(db, u)
+	assert.False(t, obj.Permissions["can_action"])
+	assert.True(t, obj.Permissions["can_toggle_staff"])
+
+	// the actions don't share the names with the permissions of the object
+	obj = AdminObject{Path: "page", Actions: []AdminAction{{Path: "delete"}, {Path: "publish"}}}
+	createAdminGroup(db, u, GroupTypeAdmin, "page.delete")
+	obj.BuildPermissions(db, u)
+	assert.True(t, obj.Permissions["can_delete"])
+	assert.False(t, obj.Permissions["can_action"])
+	assert.False(t, obj.Permissions["can_publish"])
+
+	// update and delete imply read
+	assert.True(t, obj.HasPermission([]string{"page.delete"}, PermissionRead))
+	assert.True(t, obj.HasPermission([]string{"page.update"}, PermissionRead))
+	assert.False(t, obj.HasPermission([]string{"page.create"}, PermissionRead))
+	assert.True(t, obj.HasPermission([]string{"*.action.publish"}, actionPermission("publish")))
+	assert.True(t, obj.HasPermission([]string{"page.action"}, PermissionAction))
+}
+
+func TestAdminPermissionsCheck(t *testing.T) {
+	r := gin.Default()
+	db, _ := InitDatabase(nil, "", "")
+	InitCarrot(db, r)
+
+	// builtin objects are only for super users, staffs are checked by permissions
+	objs := GetCarrotAdminObjects()
+	for i := range objs {
+		objs[i].AccessCheck = nil
+	}
+	RegisterAdmins(r.Group("/admin"), db, objs)
+	bob, _ := CreateUser(db, "bob@restsend.com", "--")
+	bob.IsStaff = true
+	bob.Activated = true
+	db.Save(bob)
+
+	client := NewTestClient(r)
+	authClient(db, client, "bob@restsend.com", "--", false)
+	CreateUser(db, "alice@restsend.com", "1")
+
+	{
+		err := client.CallPut("/admin/config/", gin.H{"key": "test", "value": "mock"}, nil)
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "permission denied")
+
+		err = client.CallPost("/admin/user/toggle_staff?email=alice@restsend.com", nil, nil)
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "permission denied")
+
+		// read permission is required to query, export and view the history
+		w := client.Post(http.MethodPost, "/admin/config/", []byte(`{}`))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		w = client.Post(http.MethodPost, "/admin/config/_export", []byte(`{}`))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		w = client.Post(http.MethodPost, "/admin/config/_history?id=1", nil)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		w = client.Post(http.MethodPost, "/admin/admin.json", nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), `"pluralName":"Configs"`)
+	}
+	{
+		createAdminGroup(db, bob, GroupTypeAdmin, "configs.*", "user.action.toggle_staff")
+		w := client.Post(http.MethodPost, "/admin/config/", []byte(`{}`))
+		assert.Equal(t, http.StatusOK, w.Code)
+		w = client.Post(http.MethodPost, "/admin/admin.json", nil)
+		assert.Contains(t, w.Body.String(), `"pluralName":"Configs"`)
+		assert.NotContains(t, w.Body.String(), `"pluralName":"Users"`)
+
+		var r Config
+		err := client.CallPut("/admin/config/", gin.H{"key": "test", "value": "mock"}, &r)
+		assert.Nil(t, err)
+		assert.Equal(t, "test", r.Key)
+
+		var ok bool
+		err = client.CallPost("/admin/user/toggle_staff?email=alice@restsend.com", nil, &ok)
+		assert.Nil(t, err)
+		assert.True(t, ok)
+
+		err = client.CallPost("/admin/user/toggle_enabled?email=alice@restsend.com", nil, nil)
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "permission denied")
+
+		err = client.CallDelete("/admin/user/?email=alice@restsend.com", nil, nil)
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "permission denied")
+	}
+	{
+		// the rows can be found with the update permission only
+		w := client.Post(http.MethodPost, "/admin/group/", []byte(`{}`))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		createAdminGroup(db, bob, GroupTypeAdmin, "group.update")
+		w = client.Post(http.MethodPost, "/admin/group/", []byte(`{}`))
+		assert.Equal(t, http.StatusOK, w.Code)
+		w = client.Post(http.MethodPost, "/admin/admin.json", nil)
+		assert.Contains(t, w.Body.String(), `"pluralName":"Groups"`)
+	}
+}
@@ -0,0 +1,242 @@
+package carrot
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	//SigObjectTransition: obj any, transition *StateTransition, from, to string, c *gin.Context
+	SigObjectTransition = "object.transition"
+)
+
+var ErrInvalidTransition = errors.New("invalid state transition")
+var ErrStateNotEditable = errors.New("state can only be changed by transitions")
+
+type StateHookFunc func(db *gorm.DB, c *gin.Context, obj any, from, to string) error
+
+// StateTransition moves an object from one of From states to To state.
+type StateTransition struct {
+	Name       string        `json:"name"`                 // Transition name, also used as path, such as "publish"
+	Label      string        `json:"label,omitempty"`      // Label of the admin action
+	From       []string      `json:"from,omitempty"`       // Allowed source states, empty means any state
+	To         string        `json:"to"`                   // Target state
+	Permission string        `json:"permission,omitempty"` // Object permission required when OwnerField is set, default is PermissionUpdate
+	Guard      StateHookFunc `json:"-"`                    // Reject the transition by returning an error
+	Before     StateHookFunc `json:"-"`                    // Called before the state is saved
+	After      StateHookFunc `json:"-"`                    // Called after the state is saved
+}
+
+// StateMachine declares the allowed transitions of a state field, such as:
+//
+//	&StateMachine{
+//		Field: "Status",
+//		Transitions: []StateTransition{
+//			{Name: "submit", From: []string{"draft"}, To: "review"},
+//			{Name: "publish", From: []string{"review"}, To: "published"},
+//		},
+//	}
+type StateMachine struct {
+	Field       string            // Struct field name of the state
+	Transitions []StateTransition // Allowed transitions
+}
+
+// GetTransition return the transition by name, nil if not found.
+func (sm *StateMachine) GetTransition(name string) *StateTransition {
+	for i := range sm.Transitions {
+		if sm.Transitions[i].Name == name {
+			return &sm.Transitions[i]
+		}
+	}
+	return nil
+}
+
+// GetState return the current state of obj.
+func (sm *StateMachine) GetState(obj any) string {
+	fv := reflect.Indirect(reflect.ValueOf(obj)).FieldByName(sm.Field)
+	if !fv.IsValid() {
+		return ""
+	}
+	return fmt.Sprintf("%v", reflect.Indirect(fv).Interface())
+}
+
+// ColumnName return the column name of the state field.
+func (sm *StateMachine) ColumnName(db *gorm.DB, tableName string) string {
+	return db.NamingStrategy.ColumnName(tableName, sm.Field)
+}
+
+// Apply run transition on obj, check the source state and guard, then save the new state.
+// The Before hook, the update and the After hook run in one transaction, and
+// ErrInvalidTransition is returned if the state has been changed by others.
+// SigObjectTransition is emitted after the state saved.
+func (sm *StateMachine) Apply(db *gorm.DB, c *gin.Context, obj any, name string) error {
+	t := sm.GetTransition(name)
+	if t == nil {
+		return ErrInvalidTransition
+	}
+
+	from := sm.GetState(obj)
+	if len(t.From) > 0 {
+		allowed := false
+		for _, v := range t.From {
+			if v == from {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("%w: %s can't %s", ErrInvalidTransition, from, t.Name)
+		}
+	}
+
+	if t.Guard != nil {
+		if err := t.Guard(db, c, obj, from, t.To); err != nil {
+			return err
+		}
+	}
+
+	fv := reflect.Indirect(reflect.ValueOf(obj)).FieldByName(sm.Field)
+	if !fv.IsValid() {
+		return fmt.Errorf("invalid state field: %s", sm.Field)
+	}
+	elemType := fv.Type()
+	if elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+	to, err := convertValue(elemType, t.To, false)
+	if err != nil {
+		return err
+	}
+
+	// the source states are checked again in the update, the state may be changed by others
+	var fromValues []any
+	for _, v := range t.From {
+		fromValue, err := convertValue(elemType, v, false)
+		if err != nil {
+			return err
+		}
+		fromValues = append(fromValues, fromValue)
+	}
+
+	oldValue := reflect.New(fv.Type()).Elem()
+	oldValue.Set(fv)
+	toValue := reflect.ValueOf(to)
+	if fv.Kind() == reflect.Ptr {
+		ptr := reflect.New(elemType)
+		ptr.Elem().Set(toValue)
+		toValue = ptr
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if t.Before != nil {
+			if err := t.Before(tx, c, obj, from, t.To); err != nil {
+				return err
+			}
+		}
+
+		column := sm.ColumnName(tx, "")
+		stmt := tx.Model(obj)
+		if len(fromValues) > 0 {
+			stmt = stmt.Where(clause.IN{Column: clause.Column{Name: column}, Values: fromValues})
+		}
+		result := stmt.Update(column, to)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("%w: %s has been changed", ErrInvalidTransition, from)
+		}
+		fv.Set(toValue)
+
+		if t.After != nil {
+			return t.After(tx, c, obj, from, t.To)
+		}
+		return nil
+	})
+	if err != nil {
+		fv.Set(oldValue)
+		return err
+	}
+
+	Sig().Emit(SigObjectTransition, obj, t, from, t.To, c)
+	return nil
+}
+
+func (t *StateTransition) permission() string {
+	if t.Permission == "" {
+		return PermissionUpdate
+	}
+	return t.Permission
+}
+
+// registerTransitions registers POST /{name}/{primary keys}/{transition} for each transition.
+func (obj *WebObject) registerTransitions(r *gin.RouterGroup, primaryKeyPath string) {
+	for i := range obj.StateMachine.Transitions {
+		t := &obj.StateMachine.Transitions[i]
+		r.POST(filepath.Join(primaryKeyPath, t.Name), func(c *gin.Context) {
+			handleTransitionObject(c, obj, t)
+		})
+	}
+}
+
+func handleTransitionObject(c *gin.Context, obj *WebObject, t *StateTransition) {
+	keys, err := obj.getPrimaryValues(c)
+	if err != nil {
+		AbortWithJSONError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	db := getDbConnection(c, obj.GetDB, false)
+	val := reflect.New(obj.modelElem).Interface()
+	result := obj.buildPrimaryCondition(db, keys).Session(&gorm.Session{}).Take(val)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			AbortWithJSONError(c, http.StatusNotFound, ErrNotFound)
+		} else {
+			AbortWithJSONError(c, http.StatusInternalServerError, result.Error)
+		}
+		return
+	}
+
+	if !obj.checkAccess(c, db, val, t.permission()) {
+		return
+	}
+
+	if err := obj.StateMachine.Apply(db, c, val, t.Name); err != nil {
+		AbortWithJSONError(c, http.StatusBadRequest, err)
+		return
+	}
+	RenderJSON(c, http.StatusOK, val)
+}
+
+// buildTransitionActions append an admin action for each transition.
+func (obj *AdminObject) buildTransitionActions() {
+	for i := range obj.StateMachine.Transitions {
+		t := &obj.StateMachine.Transitions[i]
+		label := t.Label
+		if label == "" {
+			label = fmt.Sprintf("Change state to %s", t.To)
+		}
+		obj.Actions = append(obj.Actions, AdminAction{
+			Path:  t.Name,
+			Name:  t.Name,
+			Label: label,
+			Handler: func(db *gorm.DB, c *gin.Context, val any) (bool, any, error) {
+				if err := obj.checkAccess(c, db, val, t.permission()); err != nil {
+					return false, nil, err
+				}
+				if err := obj.StateMachine.Apply(db, c, val, t.Name); err != nil {
+					return false, nil, err
+				}
+				return false, obj.StateMachine.GetState(val), nil
+			},
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package carrot
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type stateArticle struct {
+	ID     uint   `json:"id" gorm:"primarykey"`
+	Title  string `json:"title" gorm:"size:100"`
+	Status string `json:"status" gorm:"size:20;default:draft"`
+}
+
+func newArticleStateMachine() *StateMachine {
+	return &StateMachine{
+		Field: "Status",
+		Transitions: []StateTransition{
+			{Name: "submit", From: []string{"draft"}, To: "review"},
+			{
+				Name: "publish", From: []string{"review"}, To: "published",
+				Guard: func(db *gorm.DB, c *gin.Context, obj any, from, to string) error {
+					if obj.(*stateArticle).Title == "" {
+						return errors.New("title required")
+					}
+					return nil
+				},
+			},
+		},
+	}
+}
+
+func TestWebObjectTransitions(t *testing.T) {
+	db, _ := gorm.Open(sqlite.Open("file::memory:"), nil)
+	db.AutoMigrate(stateArticle{})
+	db.Create(&stateArticle{ID: 1, Title: "hello"})
+	db.Create(&stateArticle{ID: 2})
+
+	r := gin.New()
+	r.Use(WithGormDB(db))
+	webobject := WebObject{
+		Model:        stateArticle{},
+		Name:         "article",
+		Editables:    []string{"Title", "Status"},
+		StateMachine: newArticleStateMachine(),
+	}
+	err := webobject.RegisterObject(&r.RouterGroup)
+	assert.Nil(t, err)
+	client := NewTestClient(r)
+
+	var transitions []string
+	sigID := Sig().Connect(SigObjectTransition, func(sender any, params ...any) {
+		transitions = append(transitions, params[1].(string)+"->"+params[2].(string))
+	})
+	defer Sig().Disconnect(SigObjectTransition, sigID)
+
+	w := client.Post(http.MethodPatch, "/article/1", []byte(`{"status":"published"}`))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), ErrStateNotEditable.Error())
+
+	w = client.Post(http.MethodPost, "/article/1/publish", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var article stateArticle
+	err = client.CallPost("/article/1/submit", nil, &article)
+	assert.Nil(t, err)
+	assert.Equal(t, "review", article.Status)
+
+	err = client.CallPost("/article/1/publish", nil, &article)
+	assert.Nil(t, err)
+	assert.Equal(t, "published", article.Status)
+	assert.Equal(t, []string{"draft->review", "review->published"}, transitions)
+
+	// guard
+	db.Model(&stateArticle{}).Where("id", 2).Update("status", "review")
+	w = client.Post(http.MethodPost, "/article/2/publish", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "title required")
+}
+
+func TestAdminObjectTransitions(t *testing.T) {
+	db, _ := InitDatabase(nil, "", "")
+	db.AutoMigrate(stateArticle{})
+	db.Create(&stateArticle{ID: 1, Title: "hello"})
+
+	obj := AdminObject{
+		Model:        &stateArticle{},
+		Name:         "Article",
+		Editables:    []string{"Title", "Status"},
+		StateMachine: newArticleStateMachine(),
+	}
+	err := obj.Build(db)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"title"}, obj.Editables)
+	assert.Equal(t, 2, len(obj.Actions))
+	assert.Equal(t, "submit", obj.Actions[0].Path)
+
+	var article stateArticle
+	db.Take(&article, 1)
+	_, r, err := obj.Actions[0].Handler(db, nil, &article)
+	assert.Nil(t, err)
+	assert.Equal(t, "review", r)
+	db.Take(&article, 1)
+	assert.Equal(t, "review", article.Status)
+}
+
+func TestStateMachineApply(t *testing.T) {
+	db, _ := gorm.Open(sqlite.Open("file::memory:"), nil)
+	db.AutoMigrate(stateArticle{})
+	db.Create(&stateArticle{ID: 1, Title: "hello"})
+
+	sm := newArticleStateMachine()
+	var stale stateArticle
+	db.Take(&stale, 1)
+	db.Model(&stateArticle{}).Where("id", 1).Update("status", "review")
+
+	// the state has been changed by others
+	err := sm.Apply(db, nil, &stale, "submit")
+	assert.ErrorIs(t, err, ErrInvalidTransition)
+	assert.Equal(t, "draft", stale.Status)
+
+	// the update is rolled back if the After hook fails
+	sm.Transitions[1].After = func(db *gorm.DB, c *gin.Context, obj any, from, to string) error {
+		return errors.New("mock after")
+	}
+	var article stateArticle
+	db.Take(&article, 1)
+	err = sm.Apply(db, nil, &article, "publish")
+	assert.NotNil(t, err)
+	assert.Equal(t, "review", article.Status)
+	db.Take(&article, 1)
+	assert.Equal(t, "review", article.Status)
+}
+
+func TestAdminTransitionAction(t *testing.T) {
+	r := gin.Default()
+	db, _ := InitDatabase(nil, "", "")
+	InitCarrot(db, r)
+	db.AutoMigrate(stateArticle{})
+	db.Create(&stateArticle{ID: 1, Title: "hello"})
+
+	objs := append(GetCarrotAdminObjects(), AdminObject{
+		Model:        &stateArticle{},
+		Name:         "Article",
+		StateMachine: newArticleStateMachine(),
+	})
+	RegisterAdmins(r.Group("/admin"), db, objs)
+	client := NewTestClient(r)
+	authClient(db, client, "bob@restsend.com", "--", true)
+
+	w := client.Post(http.MethodPost, "/admin/article/publish?id=1", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), ErrInvalidTransition.Error())
+
+	var state string
+	err := client.CallPost("/admin/article/submit?id=1", nil, &state)
+	assert.Nil(t, err)
+	assert.Equal(t, "review", state)
+}
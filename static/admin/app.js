@@ -9,6 +9,22 @@ async function parseResponseError(resp) {
     }
 }
 
+// download the file if response is download file
+async function downloadResponse(resp) {
+    let contentDisposition = resp.headers.get('content-disposition')
+    if (!contentDisposition) {
+        return
+    }
+    let filename = contentDisposition.split('filename=')[1]
+    let blob = await resp.blob()
+    let url = window.URL.createObjectURL(blob)
+    let a = document.createElement('a')
+    a.href = url
+    a.download = filename
+    a.click()
+    window.URL.revokeObjectURL(url)
+}
+
 class ConfirmAction {
     constructor() {
         this.reset()
@@ -26,13 +42,31 @@ class ConfirmAction {
             onFail: null,
         }
         this.keys = []
+        this.fields = []
     }
     confirm({ action, keys }) {
         this.reset()
         this.action = Object.assign(this.action, action)
         this.keys = keys
+        // the input fields of the action form, the values are posted with the action
+        this.fields = (action.form || []).map(f => {
+            return { ...f, value: f.defaultValue() }
+        })
         this.show = true
     }
+
+    // formValues return the values of the action form, throw error if the required field is empty
+    get formValues() {
+        let values = {}
+        this.fields.forEach(f => {
+            let v = f.unmarshal(f.value)
+            if (f.required && (v === undefined || v === null || v === '')) {
+                throw new Error(`${f.label} is required`)
+            }
+            values[f.name] = v
+        })
+        return values
+    }
     cancel(event) {
         if (event) {
             event.preventDefault()
@@ -41,6 +75,78 @@ class ConfirmAction {
         this.reset()
     }
 }
+// Jobs poll the status of the background actions until they are finished
+class Jobs {
+    constructor() {
+        this.items = []
+        this.timer = undefined
+    }
+
+    add(path, action, job) {
+        this.items.unshift({ path, name: action.name, job })
+        this.poll()
+    }
+
+    get running() {
+        return this.items.some(item => ['pending', 'running'].includes(item.job.status))
+    }
+
+    progress(item) {
+        if (!item.job.total) {
+            return item.job.status == 'done' ? 100 : 0
+        }
+        return Math.round(item.job.processed * 100 / item.job.total)
+    }
+
+    poll() {
+        if (this.timer) {
+            return
+        }
+        this.timer = setTimeout(async () => {
+            for (let item of this.items) {
+                if (!['pending', 'running'].includes(item.job.status)) {
+                    continue
+                }
+                try {
+                    let resp = await fetch(`${item.path}_job?id=${item.job.id}`, { method: 'POST' })
+                    if (resp.status == 200) {
+                        item.job = await resp.json()
+                    }
+                } catch (err) {
+                    console.error(err)
+                }
+                if (item.job.status == 'done') {
+                    Alpine.store('queryresult').refresh()
+                }
+            }
+            this.timer = undefined
+            if (this.running) {
+                this.poll()
+            }
+        }, 1000)
+    }
+
+    async cancel(item) {
+        let resp = await fetch(`${item.path}_job_cancel?id=${item.job.id}`, { method: 'POST' })
+        if (resp.status != 200) {
+            Alpine.store('toasts').error(`Cancel fail: ${await parseResponseError(resp)}`)
+        }
+    }
+
+    async download(item) {
+        let resp = await fetch(`${item.path}_job_download?id=${item.job.id}`, { method: 'POST' })
+        if (resp.status != 200) {
+            Alpine.store('toasts').error(`Download fail: ${await parseResponseError(resp)}`)
+            return
+        }
+        await downloadResponse(resp)
+    }
+
+    remove(item) {
+        this.items = this.items.filter(v => v !== item)
+    }
+}
+
 class Toasts {
     constructor() {
         this.reset()
@@ -282,11 +388,20 @@ class QueryResult {
     doAction(event) {
         event.preventDefault()
         let { action, keys } = Alpine.store('confirmAction')
+        let values = undefined
+        if (action.form && action.form.length > 0) {
+            try {
+                values = Alpine.store('confirmAction').formValues
+            } catch (err) {
+                Alpine.store('toasts').error(err.toString())
+                return
+            }
+        }
 
         Alpine.store('editobj').closeEdit()
         Alpine.store('confirmAction').cancel()
 
-        Alpine.store('current').doAction(action, keys).then(() => {
+        Alpine.store('current').doAction(action, keys, values).then(() => {
             this.rows.forEach(row => {
                 row.selected = false
             })
@@ -295,7 +410,11 @@ class QueryResult {
             if (btn_selectAll) {
                 btn_selectAll.checked = false
             }
-            Alpine.store('toasts').info(`${action.name} all records done`)
+            if (action.async) {
+                Alpine.store('toasts').info(`${action.name} is running in the background`)
+            } else {
+                Alpine.store('toasts').info(`${action.name} all records done`)
+            }
             this.refresh()
         }).catch(err => {
             Alpine.store('toasts').error(`${action.name} fail : ${err.toString()}`)
@@ -303,13 +422,58 @@ class QueryResult {
     }
 }
 class EditObject {
-    constructor({ mode, title, fields, names, primaryValue, row }) {
+    constructor({ mode, title, fields, names, primaryValue, row, inlines }) {
         this.mode = mode
         this.title = title
         this.fields = fields
         this.names = names
         this.primaryValue = primaryValue
         this.row = row
+        this.inlines = inlines || []
+        this.tab = 'fields'
+        this.history = []
+    }
+
+    addInlineRow(inline) {
+        inline.rows.push({
+            key: `new-${inline.rows.length}-${Date.now()}`,
+            fields: inline.editables.map(f => {
+                return { ...f, value: f.defaultValue() }
+            }),
+        })
+        inline.dirty = true
+    }
+
+    removeInlineRow(inline, idx) {
+        inline.rows.splice(idx, 1)
+        inline.dirty = true
+    }
+
+    // the children of the changed inlines, the exist children are sent with their primary keys
+    get inlineValues() {
+        let values = {}
+        this.inlines.filter(inline => {
+            return this.mode == 'create' || inline.dirty || inline.rows.some(row => row.fields.some(f => f.dirty))
+        }).forEach(inline => {
+            values[inline.field] = inline.rows.map(row => {
+                let v = { ...row.primaryValue }
+                row.fields.forEach(f => {
+                    v[f.name] = f.unmarshal(f.value)
+                })
+                return v
+            })
+        })
+        return values
+    }
+
+    async showHistory() {
+        this.tab = 'history'
+        try {
+            this.history = await Alpine.store('current').doHistory(this.primaryValue)
+        } catch (err) {
+            console.error(err)
+            Alpine.store('toasts').error(`Load history fail: ${err.toString()}`)
+        }
     }
 
     get apiUrl() {
@@ -319,10 +483,17 @@ class EditObject {
     async doSave(ev, closeWhenDone = true) {
         try {
             if (this.mode == 'create') {
-                const obj = await Alpine.store('current').doCreate(this.fields)
+                const obj = await Alpine.store('current').doCreate(this.fields, this.inlineValues)
                 this.primaryValue = Alpine.store('current').getPrimaryValue(obj)
             } else {
-                await Alpine.store('current').doSave(this.primaryValue, this.fields.filter(f => f.dirty))
+                let current = Alpine.store('current')
+                let lock = current.lockField && this.row ? this.row.rawData[current.lockField] : undefined
+                const obj = await current.doSave(this.primaryValue, this.fields.filter(f => f.dirty), this.inlineValues, lock)
+                if (this.row) {
+                    // the refreshed object carries the new lock value for the next save
+                    this.row.rawData = obj
+                }
+                this.primaryValue = current.getPrimaryValue(obj)
             }
 
             if (closeWhenDone) {
@@ -342,6 +513,153 @@ class EditObject {
         this.mode = undefined
     }
 }
+// ImportObject is shown in the edit content, upload the csv file, map the headers to fields,
+// preview the rows and import them
+class ImportObject {
+    constructor({ title, fields }) {
+        this.mode = 'import'
+        this.title = title
+        this.fields = fields
+        this.file = undefined
+        this.headers = []
+        this.mapping = {}
+        this.result = undefined
+        this.busy = false
+    }
+
+    async selectFile(ev) {
+        this.file = ev.target.files[0]
+        this.headers = []
+        this.mapping = {}
+        this.result = undefined
+        if (this.file) {
+            // the mapping is guessed by the server
+            await this.doImport('preview', false)
+        }
+    }
+
+    async doImport(mode, withMapping = true) {
+        if (!this.file || this.busy) {
+            return
+        }
+        this.busy = true
+        try {
+            let mapping = {}
+            Object.keys(this.mapping).filter(h => this.mapping[h]).forEach(h => {
+                mapping[h] = this.mapping[h]
+            })
+            let result = await Alpine.store('current').doImport(mode, this.file, withMapping ? mapping : undefined)
+            this.headers = result.headers || []
+            this.mapping = result.mapping || {}
+            this.result = result
+            if (mode == 'commit' && (result.errors || []).length == 0) {
+                Alpine.store('queryresult').refresh()
+                Alpine.store('toasts').info(`Import ${result.created} records done`)
+            }
+        } catch (err) {
+            console.error(err)
+            Alpine.store('toasts').error(`Import fail: ${err.toString()}`)
+        } finally {
+            this.busy = false
+        }
+    }
+
+    formatValue(v) {
+        if (v === null || v === undefined) {
+            return ''
+        }
+        if (typeof v === 'object') {
+            return v.label !== undefined ? v.label : JSON.stringify(v)
+        }
+        return v
+    }
+
+    closeEdit(event, cancel = false) {
+        this.mode = undefined
+    }
+}
+
+// flattenForeignValue keep the primary keys as the value of the foreign field, the labels are kept for the widgets
+function flattenForeignValue(f) {
+    if (f.foreign.many2many) {
+        f.valueLabels = {}
+        f.value.forEach(v => { f.valueLabels[v.value] = v.label })
+        f.value = f.value.map(v => v.value)
+    } else {
+        f.valueLabel = f.value.label
+        f.value = f.value.value
+    }
+}
+
+// prepareField set the display name, required flag and value converters of the field
+function prepareField(f, requireds) {
+    const headerName = f.label || f.name
+    f.headerName = headerName.toUpperCase().replace(/_/g, ' ')
+    f.primary = f.primary
+    f.required = requireds.includes(f.name)
+
+    if (/int/i.test(f.type)) {
+        f.type = 'int'
+    }
+
+    if (/float/i.test(f.type)) {
+        f.type = 'float'
+    }
+
+    f.defaultValue = () => {
+        if (f.attribute && f.attribute.default !== undefined) {
+            return f.attribute.default
+        }
+        switch (f.type) {
+            case 'bool': return false
+            case 'int': return 0
+            case 'uint': return 0
+            case 'float': return 0.0
+            case 'decimal': return '0'
+            case 'datetime': return ''
+            case 'string': return ''
+            default: return null
+        }
+    }
+    // convert value from string to type
+    f.unmarshal = (value) => {
+        if (value === null || value === undefined) {
+            return value
+        }
+
+        if (f.foreign) {
+            return value
+        }
+
+        switch (f.type) {
+            case 'bool':
+                if (value === 'true') { return true }
+                return value
+            case 'uint':
+            case 'int': {
+                let v = parseInt(value)
+                if (isNaN(v)) { return undefined }
+                return v
+            }
+            case 'float': {
+                let v = parseFloat(value)
+                if (isNaN(v)) { return undefined }
+                return v
+            }
+            case 'decimal':
+            case 'datetime':
+            case 'string':
+                return value
+            default:
+                if (typeof value === 'string') {
+                    return JSON.parse(value)
+                }
+                return value
+        }
+    }
+    return f
+}
+
 class AdminObject {
     constructor(meta) {
         this.permissions = meta.permissions || {}
@@ -362,71 +680,7 @@ class AdminObject {
         let requireds = meta.requireds || []
 
 
-        this.fields = fields.map(f => {
-            const headerName = f.label || f.name
-            f.headerName = headerName.toUpperCase().replace(/_/g, ' ')
-            f.primary = f.primary
-            f.required = requireds.includes(f.name)
-
-            if (/int/i.test(f.type)) {
-                f.type = 'int'
-            }
-
-            if (/float/i.test(f.type)) {
-                f.type = 'float'
-            }
-
-            f.defaultValue = () => {
-                if (f.attribute && f.attribute.default !== undefined) {
-                    return f.attribute.default
-                }
-                switch (f.type) {
-                    case 'bool': return false
-                    case 'int': return 0
-                    case 'uint': return 0
-                    case 'float': return 0.0
-                    case 'datetime': return ''
-                    case 'string': return ''
-                    default: return null
-                }
-            }
-            // convert value from string to type
-            f.unmarshal = (value) => {
-                if (value === null || value === undefined) {
-                    return value
-                }
-
-                if (f.foreign) {
-                    return value
-                }
-
-                switch (f.type) {
-                    case 'bool':
-                        if (value === 'true') { return true }
-                        return value
-                    case 'uint':
-                    case 'int': {
-                        let v = parseInt(value)
-                        if (isNaN(v)) { return undefined }
-                        return v
-                    }
-                    case 'float': {
-                        let v = parseFloat(value)
-                        if (isNaN(v)) { return undefined }
-                        return v
-                    }
-                    case 'datetime':
-                    case 'string':
-                        return value
-                    default:
-                        if (typeof value === 'string') {
-                            return JSON.parse(value)
-                        }
-                        return value
-                }
-            }
-            return f
-        })
+        this.fields = fields.map(f => prepareField(f, requireds))
 
         let filterFields = (names, defaults) => {
             if (!names) {
@@ -439,6 +693,17 @@ class AdminObject {
 
         this.shows = filterFields(meta.shows, fields)
         this.editables = filterFields(meta.editables, fields)
+        // has many children edited in the object edit page
+        this.inlines = (meta.inlines || []).map(inline => {
+            let fields = (inline.fields || []).map(f => prepareField(f, inline.requireds || []))
+            inline.fields = fields
+            inline.editables = (inline.editables || []).map(name => fields.find(f => f.name === name)).filter(f => f)
+            inline.primaryKeys = inline.primaryKeys || []
+            return inline
+        })
+        this.editables = this.editables.filter(f => !this.inlines.find(inline => inline.field === f.name))
+        // the value of lockField is sent as If-Match, the save fails if the object has been changed
+        this.lockField = meta.lockField
         this.searchables = filterFields(meta.searchables)
         this.filterables = filterFields(meta.filterables)
         this.orderables = filterFields(meta.orderables)
@@ -461,7 +726,10 @@ class AdminObject {
             f.onSelect = this.onFilterSelect.bind(this)
         })
 
-        let actions = meta.actions || []
+        // check user can run the actions
+        let actions = (meta.actions || []).filter(action => {
+            return this.permissions.can_action || this.permissions[`can_${action.path}`]
+        })
         // check user can delete
         if (this.permissions.can_delete) {
             actions.push({
@@ -478,6 +746,7 @@ class AdminObject {
                 path = `${path}${action.path}`
             }
             action.path = path
+            action.form = (action.form || []).map(f => prepareField(f, f.required ? [f.name] : []))
             action.onclick = () => {
                 let keys = []
                 let queryresult = Alpine.store('queryresult')
@@ -496,6 +765,21 @@ class AdminObject {
             }
             return action
         })
+
+        // builtin export actions
+        let exportFormats = ['csv', 'xlsx']
+        exportFormats.forEach(format => {
+            this.actions.push({
+                name: `Export ${format.toUpperCase()}`,
+                label: `Export ${format.toUpperCase()}`,
+                class: 'bg-white text-gray-900 ring-1 ring-inset ring-gray-300 hover:bg-gray-50',
+                onclick: () => {
+                    this.doExport(format).catch(err => {
+                        Alpine.store('toasts').error(`Export fail : ${err.toString()}`)
+                    })
+                }
+            })
+        })
     }
 
     onFilterSelect(filter, value) {
@@ -563,14 +847,19 @@ class AdminObject {
         return this.filterables.length > 0
     }
 
-    async doSave(keys, vals) {
-        let values = {}
+    async doSave(keys, vals, inlines = {}, lock = undefined) {
+        let values = { ...inlines }
         vals.forEach(v => {
             values[v.name] = v.unmarshal(v.value)
         })
+        let headers = {}
+        if (lock !== undefined && lock !== null) {
+            headers['If-Match'] = `${lock}`
+        }
         let params = new URLSearchParams(keys).toString()
         let resp = await fetch(`${this.path}?${params}`, {
             method: 'PATCH',
+            headers,
             body: JSON.stringify(values),
         })
         if (resp.status != 200) {
@@ -579,8 +868,57 @@ class AdminObject {
         return await resp.json()
     }
 
-    async doCreate(vals) {
-        let values = {}
+    // export the selected rows, or the whole result with current filters, keyword and orders
+    async doExport(format) {
+        let queryresult = Alpine.store('queryresult')
+        let params = new URLSearchParams({ format })
+        if (queryresult.selected < queryresult.total) {
+            let keys = queryresult.rows.filter(row => row.selected).map(row => row.primaryValue)
+            params.set('keys', JSON.stringify(keys))
+        }
+        let resp = await fetch(`${this.path}_export?${params.toString()}`, {
+            method: 'POST',
+            body: JSON.stringify({
+                keyword: queryresult.keyword,
+                filters: queryresult.filters,
+                orders: queryresult.orders,
+            }),
+        })
+        if (resp.status != 200) {
+            throw new Error(await parseResponseError(resp))
+        }
+        await downloadResponse(resp)
+    }
+
+    async doImport(mode, file, mapping) {
+        let data = new FormData()
+        data.append('file', file)
+        if (mapping) {
+            data.append('mapping', JSON.stringify(mapping))
+        }
+        let resp = await fetch(`${this.path}_import?mode=${mode}`, {
+            method: 'POST',
+            body: data,
+        })
+        if (resp.status != 200) {
+            throw new Error(await parseResponseError(resp))
+        }
+        return await resp.json()
+    }
+
+    async doHistory(keys) {
+        let params = new URLSearchParams(keys).toString()
+        let resp = await fetch(`${this.path}_history?${params}`, {
+            method: 'POST',
+        })
+        if (resp.status != 200) {
+            throw new Error(await parseResponseError(resp))
+        }
+        return await resp.json()
+    }
+
+    async doCreate(vals, inlines = {}) {
+        let values = { ...inlines }
         vals.forEach(v => {
             values[v.name] = v.unmarshal(v.value)
         })
@@ -595,7 +933,7 @@ class AdminObject {
         return await resp.json()
     }
 
-    async doAction(action, keys) {
+    async doAction(action, keys, values = undefined) {
         if (action.batch) {
             let items = {
                 "keys": JSON.stringify(keys)
@@ -608,6 +946,7 @@ class AdminObject {
             let params = new URLSearchParams(keys[i]).toString()
             let resp = await fetch(`${action.path}?${params}`, {
                 method: action.method || 'POST',
+                body: values ? JSON.stringify(values) : undefined,
             })
             if (resp.status != 200) {
                 let reason = await parseResponseError(resp)
@@ -619,22 +958,13 @@ class AdminObject {
                 }
                 break
             }
-            if (action.onDone) {
+            if (action.async) {
+                Alpine.store('jobs').add(this.path, action, await resp.json())
+            } else if (action.onDone) {
                 let result = await resp.json()
                 action.onDone(keys[i], result)
             } else {
-                // if response is download file
-                let contentDisposition = resp.headers.get('content-disposition')
-                if (contentDisposition) {
-                    let filename = contentDisposition.split('filename=')[1]
-                    let blob = await resp.blob()
-                    let url = window.URL.createObjectURL(blob)
-                    let a = document.createElement('a')
-                    a.href = url
-                    a.download = filename
-                    a.click()
-                    window.URL.revokeObjectURL(url)
-                }
+                await downloadResponse(resp)
             }
             Alpine.store('toasts').reset()
         }
@@ -648,6 +978,7 @@ const adminapp = () => ({
     loadStyles: {},
     async init() {
         Alpine.store('toasts', new Toasts())
+        Alpine.store('jobs', new Jobs())
         Alpine.store('queryresult', new QueryResult())
         Alpine.store('current', {})
         Alpine.store('switching', false)
@@ -835,12 +1166,31 @@ const adminapp = () => ({
                 f.value = row.rawData[editField.name]
             }
             if (f.value && f.foreign) {
-                f.value = f.value.value
+                flattenForeignValue(f)
             }
             names[editField.name] = f
             return f
         })
 
+        let inlines = this.$store.current.inlines.map(inline => {
+            let children = (!isCreate && row.rawData[inline.field]) || []
+            let rows = children.map((child, idx) => {
+                let primaryValue = {}
+                inline.primaryKeys.forEach(k => {
+                    primaryValue[k] = child[k]
+                })
+                let fields = inline.editables.map(editField => {
+                    let f = { ...editField, value: child[editField.name] }
+                    if (f.value && f.foreign) {
+                        flattenForeignValue(f)
+                    }
+                    return f
+                })
+                return { key: `${idx}`, primaryValue, fields }
+            })
+            return { field: inline.field, label: inline.label, editables: inline.editables, rows, dirty: false }
+        })
+
         let editobj = new EditObject(
             {
                 mode: isCreate ? 'create' : 'edit',
@@ -848,7 +1198,8 @@ const adminapp = () => ({
                 fields: fields,
                 names,
                 primaryValue: row ? row.primaryValue : undefined,
-                row
+                row,
+                inlines,
             })
 
         let current = this.$store.current
@@ -876,6 +1227,29 @@ const adminapp = () => ({
     editObject(event, row) {
         this.prepareEditobj(event, false, row)
     },
+    importObjects(event) {
+        if (event) {
+            event.preventDefault()
+        }
+        let current = this.$store.current
+        let importobj = new ImportObject({
+            title: `Import ${current.pluralName}`,
+            fields: current.editables.filter(f => !f.notColumn && !(f.foreign && f.isArray)),
+        })
+        fetch('import.html', {
+            cache: "no-store",
+        }).then(resp => {
+            resp.text().then(text => {
+                let elm = document.getElementById('edit_form')
+                if (elm) {
+                    this.$store.editobj = importobj
+                    elm.innerHTML = text
+                }
+            })
+        }).catch(err => {
+            Alpine.store('toasts').error(`Load import page fail: ${err.toString()}`)
+        })
+    },
     closeEdit(event, cancel = false) {
         if (event) {
             event.preventDefault()
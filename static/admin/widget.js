@@ -181,20 +181,143 @@ class StructWidget extends BaseWidget {
     }
 }
 
-async function loadForeignValues(path) {
+// queryForeignValues query a page of the foreign objects, the keyword matches the searchables of the target
+async function queryForeignValues(path, { keyword = '', pos = 0, limit = 0 } = {}) {
     let req = await fetch(path, {
         method: 'POST',
         body: JSON.stringify({
-            foreign: true
+            foreign: true,
+            keyword,
+            pos,
+            limit,
         }),
     })
     let data = await req.json()
-    if (!data.items) {
+    return {
+        items: data.items || [],
+        pos: data.pos || 0,
+        total: data.total || 0,
+    }
+}
+
+async function loadForeignValues(path) {
+    let data = await queryForeignValues(path)
+    if (data.items.length == 0) {
         return
     }
     return data.items
 }
 
+// ForeignSearch is an autocomplete input, the candidates are loaded page by page when typing
+class ForeignSearch {
+    constructor(path, onSelect) {
+        this.path = path
+        this.onSelect = onSelect
+        this.keyword = ''
+        this.items = []
+        this.total = 0
+        this.timer = undefined
+    }
+
+    render(elm, text, placeholder) {
+        let wrapper = document.createElement('div')
+        wrapper.className = 'relative w-full'
+
+        let input = document.createElement('input')
+        input.type = 'text'
+        input.value = text || ''
+        input.placeholder = placeholder || 'Search...'
+        input.className = 'block rounded-md border-0 py-1.5 text-gray-900 shadow-sm ring-1 ring-inset ring-gray-300 placeholder:text-gray-400 focus:ring-2 focus:ring-inset focus:ring-indigo-600 sm:text-sm sm:leading-6 w-full'
+        wrapper.appendChild(input)
+
+        let list = document.createElement('ul')
+        list.className = 'absolute z-10 mt-1 max-h-60 w-full overflow-auto rounded-md bg-white py-1 text-sm shadow-lg ring-1 ring-black ring-opacity-5 hidden'
+        wrapper.appendChild(list)
+
+        this.input = input
+        this.list = list
+
+        input.addEventListener('focus', () => {
+            input.select()
+            this.search(input.value == text ? '' : input.value)
+        })
+        input.addEventListener('input', () => {
+            clearTimeout(this.timer)
+            this.timer = setTimeout(() => this.search(input.value), 300)
+        })
+        input.addEventListener('keydown', (e) => {
+            if (e.key == 'Escape') {
+                list.classList.add('hidden')
+            }
+        })
+        input.addEventListener('blur', () => {
+            // delay to handle the click of the candidate
+            setTimeout(() => {
+                list.classList.add('hidden')
+                input.value = this.text || ''
+            }, 200)
+        })
+        this.text = text
+        elm.appendChild(wrapper)
+    }
+
+    setText(text) {
+        this.text = text
+        if (this.input) {
+            this.input.value = text || ''
+        }
+    }
+
+    async search(keyword) {
+        this.keyword = keyword
+        this.items = []
+        await this.loadMore()
+    }
+
+    async loadMore() {
+        let keyword = this.keyword
+        let data = await queryForeignValues(this.path, { keyword, pos: this.items.length })
+        if (keyword != this.keyword) {
+            return // the keyword has changed
+        }
+        this.items.push(...data.items)
+        this.total = data.total
+        this.renderList()
+    }
+
+    renderList() {
+        this.list.innerHTML = ''
+        if (this.items.length == 0) {
+            let empty = document.createElement('li')
+            empty.className = 'px-3 py-1.5 text-gray-400'
+            empty.innerText = 'No results'
+            this.list.appendChild(empty)
+        }
+        this.items.forEach(item => {
+            let li = document.createElement('li')
+            li.className = 'cursor-pointer px-3 py-1.5 text-gray-900 hover:bg-indigo-600 hover:text-white'
+            li.innerText = item.label || item.value
+            li.addEventListener('mousedown', (e) => {
+                e.preventDefault()
+                this.list.classList.add('hidden')
+                this.onSelect(item)
+            })
+            this.list.appendChild(li)
+        })
+        if (this.items.length < this.total) {
+            let more = document.createElement('li')
+            more.className = 'cursor-pointer px-3 py-1.5 text-indigo-600 hover:underline'
+            more.innerText = `More (${this.items.length}/${this.total})`
+            more.addEventListener('mousedown', (e) => {
+                e.preventDefault()
+                this.loadMore()
+            })
+            this.list.appendChild(more)
+        }
+        this.list.classList.remove('hidden')
+    }
+}
+
 class ForeignKeyWidget extends BaseWidget {
     render(elm) {
         if (this.field.value) {
@@ -203,38 +326,69 @@ class ForeignKeyWidget extends BaseWidget {
     }
 
     renderEdit(elm) {
-        let node = document.createElement('select')
-        node.className = 'block rounded-md border-0 py-1.5 text-gray-900 shadow-sm ring-1 ring-inset ring-gray-300 placeholder:text-gray-400 focus:ring-2 focus:ring-inset  focus:ring-indigo-600 sm:text-sm sm:leading-6'
-        let firstOption = document.createElement('option')
-        firstOption.value = ''
-        firstOption.disabled = true
-        firstOption.innerText = this.field.placeholder || 'Select A Value'
-        node.appendChild(firstOption)
+        let search = new ForeignSearch(this.field.foreign.path, (item) => {
+            this.field.value = item.value
+            this.field.valueLabel = item.label
+            this.field.dirty = true
+            search.setText(item.label || item.value)
+        })
+        let text = this.field.valueLabel || (this.field.value !== undefined && this.field.value !== null ? `${this.field.value}` : '')
+        search.render(elm, text, this.field.placeholder || 'Search A Value')
+    }
+}
 
-        loadForeignValues(this.field.foreign.path).then(items => {
-            if (!items) return
+// ForeignMultiWidget edit the many2many field, the value is the list of the primary keys
+class ForeignMultiWidget extends BaseWidget {
+    render(elm) {
+        if (this.field.value && this.field.value.length > 0) {
+            this.renderWith(elm, this.field.value.map(v => v.label || v.value).join(', '))
+        }
+    }
 
-            if (!this.field.value) {
-                this.field.value = items[0].value
+    renderEdit(elm) {
+        let container = document.createElement('div')
+        container.className = 'w-full space-y-2'
+        let chips = document.createElement('div')
+        chips.className = 'flex flex-wrap gap-1'
+        container.appendChild(chips)
+
+        let labels = this.field.valueLabels || {}
+        let renderChips = () => {
+            chips.innerHTML = ''
+            let values = this.field.value || []
+            values.forEach(v => {
+                let chip = document.createElement('span')
+                chip.className = 'inline-flex items-center gap-x-1 rounded-md bg-indigo-50 px-2 py-1 text-xs font-medium text-indigo-700'
+                chip.innerText = labels[v] || v
+                let remove = document.createElement('button')
+                remove.type = 'button'
+                remove.className = 'text-indigo-400 hover:text-indigo-900'
+                remove.innerText = '\u00d7'
+                remove.addEventListener('click', (e) => {
+                    e.preventDefault()
+                    this.field.value = values.filter(x => `${x}` != `${v}`)
+                    this.field.dirty = true
+                    renderChips()
+                })
+                chip.appendChild(remove)
+                chips.appendChild(chip)
+            })
+        }
+        renderChips()
+
+        let search = new ForeignSearch(this.field.foreign.path, (item) => {
+            let values = this.field.value || []
+            if (!values.some(v => `${v}` == `${item.value}`)) {
+                labels[item.value] = item.label
+                this.field.value = [...values, item.value]
+                this.field.valueLabels = labels
                 this.field.dirty = true
+                renderChips()
             }
-
-            items.forEach(item => {
-                let option = document.createElement('option')
-                option.value = item.value
-                option.innerText = item.label || item.value
-                if (item.value == this.field.value) {
-                    option.selected = true
-                }
-                node.appendChild(option)
-            })
-        })
-        node.addEventListener('change', (e) => {
-            e.preventDefault()
-            this.field.value = e.target.value
-            this.field.dirty = true
+            search.setText('')
         })
-        elm.appendChild(node)
+        search.render(container, '', this.field.placeholder || 'Search to add')
+        elm.appendChild(container)
     }
 }
 
@@ -461,26 +615,116 @@ class DateTimeFilterWidget extends SelectFilterWidget {
         super.renderWithOptions(elm, options, false)
     }
 }
-class ForeignKeyFilterWidget extends SelectFilterWidget {
+// ForeignKeyFilterWidget search the foreign values page by page, the selected values are listed below
+class ForeignKeyFilterWidget {
     render(elm) {
         this.name = this.field.foreign.field // use the foreign field name as the filter name
-        let options = []
-        if (this.field.canNull) {
-            options.push({ label: 'Empty value', value: null })
+        this.selected = []
+        this.empty = false
+
+        let singleChoice = false
+        if (this.field.attribute && this.field.attribute.singleChoice !== undefined) {
+            singleChoice = this.field.attribute.singleChoice
         }
 
-        loadForeignValues(this.field.foreign.path).then(items => {
-            if (items) {
-                options.push(...items)
+        let search = new ForeignSearch(this.field.foreign.path, (item) => {
+            if (singleChoice) {
+                this.selected = [item]
+                this.empty = false
+            } else if (!this.selected.find(v => v.value == item.value)) {
+                this.selected.push(item)
             }
+            this.renderSelected()
+            this.onChange()
+        })
+        search.render(elm, '', 'Search...')
 
-            let singleChoice = false
-            if (this.field.attribute && this.field.attribute.singleChoice !== undefined) {
-                singleChoice = this.field.attribute.singleChoice
-            }
+        let node = document.createElement('div')
+        node.className = 'mt-2 grid grid-cols-1 gap-2'
+        elm.appendChild(node)
+        this.node = node
+        this.singleChoice = singleChoice
+        this.renderSelected()
+    }
 
-            this.renderWithOptions(elm, options, !singleChoice)
+    renderSelected() {
+        this.node.innerHTML = ''
+        if (this.field.canNull) {
+            let option = document.createElement('label')
+            option.className = 'flex items-center hover:bg-gray-50 rounded py-2 px-2'
+            let input = document.createElement('input')
+            input.type = 'checkbox'
+            input.checked = this.empty
+            input.className = 'h-4 w-4 rounded border-gray-300 text-indigo-600 focus:ring-indigo-500'
+            input.addEventListener('change', (e) => {
+                this.empty = e.target.checked
+                if (this.empty && this.singleChoice) {
+                    this.selected = []
+                }
+                this.renderSelected()
+                this.onChange()
+            })
+            option.appendChild(input)
+            let span = document.createElement('span')
+            span.className = 'ml-3 text-sm text-gray-500'
+            span.innerText = 'Empty value'
+            option.appendChild(span)
+            this.node.appendChild(option)
+        }
+
+        this.selected.forEach(item => {
+            let option = document.createElement('div')
+            option.className = 'flex items-center justify-between hover:bg-gray-50 rounded py-2 px-2'
+            let span = document.createElement('span')
+            span.className = 'text-sm text-gray-500'
+            span.innerText = item.label || item.value
+            option.appendChild(span)
+            let remove = document.createElement('button')
+            remove.className = 'text-xs text-gray-400 hover:text-gray-600'
+            remove.innerText = '✕'
+            remove.addEventListener('click', (e) => {
+                e.preventDefault()
+                this.selected = this.selected.filter(v => v.value != item.value)
+                this.renderSelected()
+                this.onChange()
+            })
+            option.appendChild(remove)
+            this.node.appendChild(option)
         })
+
+        if (this.empty || this.selected.length > 0) {
+            let clean = document.createElement('button')
+            clean.className = 'mt-4 inline-flex items-center  justify-center px-2.5 py-2 border border-transparent text-xs font-medium rounded text-indigo-700 bg-indigo-100 hover:bg-indigo-200 focus:outline-none focus:ring-2 focus:ring-offset-2 focus:ring-indigo-500'
+            clean.innerText = 'Clean'
+            clean.addEventListener('click', (e) => {
+                e.preventDefault()
+                this.selected = []
+                this.empty = false
+                this.renderSelected()
+                this.onChange()
+            })
+            this.node.appendChild(clean)
+        }
+    }
+
+    onChange() {
+        let vals = []
+        if (this.empty) {
+            vals.push({ label: 'Empty value', value: null })
+        }
+        vals.push(...this.selected)
+
+        let selected = null
+        if (vals.length > 0) {
+            selected = {
+                name: this.name,
+                op: vals.length > 1 ? 'in' : '=',
+                value: vals.length > 1 ? vals.map(v => v.value) : vals[0].value,
+                showOp: vals.length > 1 ? 'in' : 'is',
+                showValue: vals.map(v => v.label || v.value).join(', '),
+            }
+        }
+        this.field.onSelect(this.field, selected)
     }
 }
 
@@ -489,11 +733,13 @@ window.AdminWidgets = {
     'uint': BaseWidget,
     'int': BaseWidget,
     'float': BaseWidget,
+    'decimal': BaseWidget,
 
     'bool': BooleanWidget,
     'textarea': TextareaWidget,
     'datetime': DateTimeWidget,
     'foreign': ForeignKeyWidget,
+    'foreignMulti': ForeignMultiWidget,
     'struct': StructWidget,
     'password': PasswordWidget,
     'select': SelectWidget,
@@ -504,6 +750,7 @@ window.AdminFilterWidgets = {
     'uint': NumberFilterWidget,
     'int': NumberFilterWidget,
     'float': NumberFilterWidget,
+    'decimal': NumberFilterWidget,
 
     'bool': BooleanFilterWidget,
     'datetime': DateTimeFilterWidget,
@@ -515,7 +762,7 @@ window.AdminFilterWidgets = {
 function getWidget(field, col) {
     let widgetType = null
     if (field.foreign) {
-        widgetType = 'foreign'
+        widgetType = field.foreign.many2many ? 'foreignMulti' : 'foreign'
     } else {
         switch (field.type) {
             case 'string':
@@ -524,6 +771,7 @@ function getWidget(field, col) {
             case 'uint':
             case 'int':
             case 'float':
+            case 'decimal':
                 widgetType = field.type
                 break
             default:
@@ -573,6 +821,7 @@ function getFilterWidget(field) {
             case 'uint':
             case 'int':
             case 'float':
+            case 'decimal':
                 widgetType = field.type
                 break
             default:
@@ -0,0 +1,94 @@
+package carrot
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+const (
+	TagFilter   = "filter"
+	TagOrder    = "order"
+	TagSearch   = "search"
+	TagEdit     = "edit"
+	TagShow     = "show"
+	TagRequired = "required"
+	TagLabel    = "label"
+	TagEnum     = "enum"
+)
+
+// carrotTag is the parsed `carrot:"..."` struct tag, such as:
+//
+//	Name string `carrot:"filter,order,search,edit,show,label=Full name"`
+//	Role string `carrot:"filter,enum=group_role"`
+type carrotTag map[string]string
+
+func parseCarrotTag(tag string) carrotTag {
+	r := carrotTag{}
+	for _, v := range strings.Split(tag, ",") {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(v, "=")
+		r[strings.ToLower(strings.TrimSpace(key))] = strings.TrimSpace(value)
+	}
+	return r
+}
+
+func (t carrotTag) Has(key string) bool {
+	_, ok := t[key]
+	return ok
+}
+
+func (t carrotTag) Get(key string) string {
+	return t[key]
+}
+
+// carrotTaggedFields return the field names of rt which has the carrot tag key, embedded structs are included.
+func carrotTaggedFields(rt reflect.Type, key string) []string {
+	var fields []string
+	for i := 0; i < rt.NumField(); i++ {
+		f := rt.Field(i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			fields = append(fields, carrotTaggedFields(f.Type, key)...)
+			continue
+		}
+		if parseCarrotTag(f.Tag.Get("carrot")).Has(key) {
+			fields = append(fields, f.Name)
+		}
+	}
+	return fields
+}
+
+// mergeTaggedFields append the fields tagged with key to fields, duplicated names are skipped.
+func mergeTaggedFields(rt reflect.Type, fields []string, key string) []string {
+	tagged := carrotTaggedFields(rt, key)
+	if len(tagged) == 0 {
+		return fields
+	}
+	fields = append([]string(nil), fields...) // fields is owned by the caller
+	for _, name := range tagged {
+		exists := false
+		for _, v := range fields {
+			if v == name {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			fields = append(fields, name)
+		}
+	}
+	return fields
+}
+
+// checkFieldNames return an error if any name is not a field of rt.
+func checkFieldNames(rt reflect.Type, kind string, names []string) error {
+	for _, name := range names {
+		if _, ok := rt.FieldByName(name); !ok {
+			return fmt.Errorf("%s: invalid %s field %q", rt.Name(), kind, name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package carrot
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type taggedItem struct {
+	ID     uint   `json:"id" gorm:"primarykey"`
+	Name   string `json:"name" carrot:"filter,order,search,edit,show,required,label=Full name"`
+	Age    int    `json:"age" carrot:"filter,order"`
+	Remark string `json:"remark"`
+}
+
+func TestParseCarrotTag(t *testing.T) {
+	tag := parseCarrotTag("filter, order,label=Full name")
+	assert.True(t, tag.Has(TagFilter))
+	assert.True(t, tag.Has(TagOrder))
+	assert.False(t, tag.Has(TagEdit))
+	assert.Equal(t, "Full name", tag.Get(TagLabel))
+	assert.Equal(t, 0, len(parseCarrotTag("")))
+}
+
+func TestWebObjectCarrotTag(t *testing.T) {
+	obj := WebObject{
+		Model:       taggedItem{},
+		Filterables: []string{"Name", "Remark"},
+	}
+	err := obj.Build()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Name", "Remark", "Age"}, obj.Filterables)
+	assert.Equal(t, []string{"Name", "Age"}, obj.Orderables)
+	assert.Equal(t, []string{"Name"}, obj.Searchables)
+	assert.Equal(t, []string{"Name"}, obj.Editables)
+
+	obj = WebObject{
+		Model:       taggedItem{},
+		Filterables: []string{"Name, Age"},
+	}
+	err = obj.Build()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), `"Name, Age"`)
+}
+
+func TestAdminObjectCarrotTag(t *testing.T) {
+	db, _ := InitDatabase(nil, "", "")
+	obj := AdminObject{
+		Model: &taggedItem{},
+		Name:  "TaggedItem",
+		Shows: []string{"ID"},
+	}
+	err := obj.Build(db)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"id", "name"}, obj.Shows)
+	assert.Equal(t, []string{"name"}, obj.Requireds)
+	assert.Equal(t, []string{"name", "age"}, obj.Filterables)
+	for _, f := range obj.Fields {
+		if f.Name == "name" {
+			assert.Equal(t, "Full Name", f.Label)
+		}
+	}
+
+	obj = AdminObject{
+		Model:  &taggedItem{},
+		Name:   "TaggedItem",
+		Orders: []Order{{"Updated", OrderOpDesc}},
+	}
+	err = obj.Build(db)
+	assert.NotNil(t, err)
+
+	for _, obj := range GetCarrotAdminObjects() {
+		assert.Nil(t, obj.Build(db), obj.Name)
+	}
+}
+
+func TestMergeTaggedFieldsCopy(t *testing.T) {
+	rt := reflect.TypeOf(taggedItem{})
+	shared := make([]string, 1, 4)
+	shared[0] = "Remark"
+	filters := mergeTaggedFields(rt, shared, TagFilter)
+	orders := mergeTaggedFields(rt, shared, TagOrder)
+	assert.Equal(t, []string{"Remark", "Name", "Age"}, filters)
+	assert.Equal(t, []string{"Remark", "Name", "Age"}, orders)
+
+	// the spare capacity of the caller's slice is not written
+	filters[1] = "ID"
+	assert.Equal(t, "Name", orders[1])
+	assert.Equal(t, []string{"Remark"}, shared)
+	assert.Equal(t, "", shared[:2][1])
+}
@@ -13,6 +13,7 @@ type AvgUsage struct {
 	Threshold time.Duration
 	StartAt   time.Time
 	vals      []time.Duration
+	mu        sync.Mutex
 }
 
 type Worker[T any] struct {
@@ -26,6 +27,8 @@ type Worker[T any] struct {
 }
 
 func (at *AvgUsage) GetCount() int {
+	at.mu.Lock()
+	defer at.mu.Unlock()
 	if time.Since(at.StartAt) >= at.Threshold {
 		at.StartAt = time.Now()
 		at.vals = nil
@@ -34,6 +37,8 @@ func (at *AvgUsage) GetCount() int {
 }
 
 func (at *AvgUsage) Add(v time.Duration) {
+	at.mu.Lock()
+	defer at.mu.Unlock()
 	if time.Since(at.StartAt) >= at.Threshold {
 		at.StartAt = time.Now()
 		at.vals = nil
@@ -42,6 +47,8 @@ func (at *AvgUsage) Add(v time.Duration) {
 }
 
 func (at *AvgUsage) CountPerMinute() float64 {
+	at.mu.Lock()
+	defer at.mu.Unlock()
 	us := time.Since(at.StartAt)
 	if us <= 1*time.Minute {
 		return float64(len(at.vals))
@@ -51,6 +58,8 @@ func (at *AvgUsage) CountPerMinute() float64 {
 }
 
 func (at *AvgUsage) Get() time.Duration {
+	at.mu.Lock()
+	defer at.mu.Unlock()
 	var x time.Duration = 0
 	if len(at.vals) <= 0 {
 		return 0